@@ -0,0 +1,101 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/atuleu/go-humanize"
+	"github.com/atuleu/go-tablifier"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+type DevicesCommand struct {
+	Args struct {
+		Node Nodename
+	} `positional-args:"yes"`
+}
+
+type deviceTableLine struct {
+	Zone      string
+	Device    string
+	Firmware  string
+	FirstSeen string
+	LastSeen  string
+	Resets    uint32
+}
+
+func seenSince(now time.Time, t *timestamppb.Timestamp) string {
+	if t == nil {
+		return "never"
+	}
+	ellapsed := now.Sub(t.AsTime()).Truncate(time.Second)
+	return humanize.Duration(ellapsed).String() + " ago"
+}
+
+func (c *DevicesCommand) Execute(args []string) (err error) {
+	ctx, span := otel.Tracer(intrumentationName).Start(context.Background(),
+		"leto-cli/Devices")
+	defer func() {
+		if err != nil {
+			span.SetStatus(codes.Error, "leto-cli error")
+			span.RecordError(err)
+		}
+		span.End()
+	}()
+
+	var nodes []Node
+	if len(c.Args.Node) > 0 {
+		node, err := GetNode(c.Args.Node)
+		if err != nil {
+			return err
+		}
+		nodes = []Node{node}
+	} else {
+		nodes, err = Nodes()
+		if err != nil {
+			return err
+		}
+	}
+
+	now := time.Now()
+	lines := []deviceTableLine{}
+	for _, node := range nodes {
+		list, err := node.ListDevices(ctx)
+		if err != nil {
+			return fmt.Errorf("could not list devices of '%s': %w", node.Name, err)
+		}
+		for _, d := range list.Devices {
+			line := deviceTableLine{
+				Zone:      node.Name + "." + d.Zone,
+				Device:    fmt.Sprintf("%s.%d", d.Class, d.Id),
+				Firmware:  d.FirmwareVersion,
+				FirstSeen: seenSince(now, d.FirstSeen),
+				LastSeen:  seenSince(now, d.LastSeen),
+				Resets:    d.Resets,
+			}
+			if len(line.Firmware) == 0 {
+				line.Firmware = "n.a."
+			} else if d.Outdated == true {
+				line.Firmware += " (outdated)"
+			}
+			lines = append(lines, line)
+		}
+	}
+
+	tablifier.Tablify(lines)
+
+	return nil
+}
+
+func init() {
+	_, err := parser.AddCommand("devices",
+		"lists devices of nodes",
+		"lists the devices seen by a node, or by all nodes on the local network, with their firmware version",
+		&DevicesCommand{})
+	if err != nil {
+		panic(err.Error())
+	}
+}
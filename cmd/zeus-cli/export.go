@@ -0,0 +1,426 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/adrg/xdg"
+	"github.com/formicidae-tracker/zeus/internal/zeus"
+	"github.com/jessevdk/go-flags"
+)
+
+type ExportCommand struct {
+	Directory string        `short:"d" long:"directory" description:"directory containing the zone logs, defaults to zeus data directory"`
+	Node      Nodename      `short:"n" long:"node" description:"exports the logs of the running zone from this node instead of a directory"`
+	From      string        `long:"from" description:"only exports data after this RFC3339 date"`
+	To        string        `long:"to" description:"only exports data before this RFC3339 date"`
+	Period    time.Duration `short:"p" long:"period" description:"resamples the climate to this period"`
+	Alarms    string        `long:"alarms" description:"also exports alarm events to this CSV file"`
+
+	Args struct {
+		Zone   string
+		Output flags.Filename
+	} `positional-args:"yes" required:"yes"`
+}
+
+// logFragments returns the log files of a zone, with the given type,
+// 'climate' or 'alarms'. Rotated segments are not listed, as they are
+// read with their file.
+func logFragments(dir, zone, ftype string) ([]string, error) {
+	matches, err := filepath.Glob(filepath.Join(dir, zone+".*."+ftype+".*"))
+	if err != nil {
+		return nil, err
+	}
+	res := make([]string, 0, len(matches))
+	for _, m := range matches {
+		ext := filepath.Ext(m)
+		switch ext {
+		case ".txt", ".csv", ".jsonl":
+		default:
+			continue
+		}
+		base := strings.TrimSuffix(m, ext)
+		// files are suffixed with a number instead of being overwritten.
+		if _, err := strconv.Atoi(strings.TrimPrefix(filepath.Ext(base), ".")); err == nil {
+			base = strings.TrimSuffix(base, filepath.Ext(base))
+		}
+		if strings.HasSuffix(base, "."+ftype) == true {
+			res = append(res, m)
+		}
+	}
+	sort.Strings(res)
+	return res, nil
+}
+
+func parseDate(value string) (time.Time, error) {
+	if len(value) == 0 {
+		return time.Time{}, nil
+	}
+	return time.Parse(time.RFC3339, value)
+}
+
+func inRange(t, from, to time.Time) bool {
+	if from.IsZero() == false && t.Before(from) {
+		return false
+	}
+	return to.IsZero() || t.Before(to)
+}
+
+// mergeClimateLogs sorts entries by time and removes the ones with the
+// same time. All entries are padded to numAux auxiliary temperatures.
+func mergeClimateLogs(entries []zeus.ClimateLogEntry, numAux int) []zeus.ClimateLogEntry {
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].Time.Before(entries[j].Time)
+	})
+	res := make([]zeus.ClimateLogEntry, 0, len(entries))
+	for _, e := range entries {
+		if len(res) > 0 && res[len(res)-1].Time.Equal(e.Time) {
+			continue
+		}
+		for len(e.Temperatures) < numAux+1 {
+			e.Temperatures = append(e.Temperatures, zeus.UndefinedTemperature)
+		}
+		res = append(res, e)
+	}
+	return res
+}
+
+type meanValue struct {
+	sum   float64
+	count int
+}
+
+func (m *meanValue) add(u zeus.BoundedUnit) {
+	if zeus.IsUndefined(u) {
+		return
+	}
+	m.sum += u.Value()
+	m.count += 1
+}
+
+func (m meanValue) value() float64 {
+	if m.count == 0 {
+		return math.Inf(-1)
+	}
+	return m.sum / float64(m.count)
+}
+
+// resampleClimateLog averages the defined values of entries over
+// consecutive periods. Each resulting entry is timed at the start of
+// its period and keeps the last target of the period.
+func resampleClimateLog(entries []zeus.ClimateLogEntry, period time.Duration) []zeus.ClimateLogEntry {
+	if period <= 0 || len(entries) == 0 {
+		return entries
+	}
+	var res []zeus.ClimateLogEntry
+	for i := 0; i < len(entries); {
+		start := entries[i].Time.Truncate(period)
+		end := start.Add(period)
+		humidity := meanValue{}
+		temperatures := make([]meanValue, len(entries[i].Temperatures))
+		var target *zeus.State
+		for ; i < len(entries) && entries[i].Time.Before(end); i++ {
+			humidity.add(entries[i].Humidity)
+			for j, t := range entries[i].Temperatures {
+				temperatures[j].add(t)
+			}
+			if entries[i].Target != nil {
+				target = entries[i].Target
+			}
+		}
+		e := zeus.ClimateLogEntry{
+			ClimateReport: zeus.ClimateReport{
+				Time:         start,
+				Humidity:     zeus.Humidity(humidity.value()),
+				Temperatures: make([]zeus.Temperature, len(temperatures)),
+			},
+			Target: target,
+		}
+		for j, t := range temperatures {
+			e.Temperatures[j] = zeus.Temperature(t.value())
+		}
+		res = append(res, e)
+	}
+	return res
+}
+
+func (c *ExportCommand) readClimate(dir string, from, to time.Time) ([]zeus.ClimateLogEntry, int, error) {
+	files, err := logFragments(dir, c.Args.Zone, "climate")
+	if err != nil {
+		return nil, 0, err
+	}
+	if len(files) == 0 {
+		return nil, 0, fmt.Errorf("no climate log for zone '%s' in '%s'", c.Args.Zone, dir)
+	}
+	var res []zeus.ClimateLogEntry
+	numAux := 0
+	for _, f := range files {
+		entries, err := zeus.ReadClimateLog(f)
+		if err != nil {
+			return nil, 0, fmt.Errorf("could not read '%s': %w", f, err)
+		}
+		for _, e := range entries {
+			if inRange(e.Time, from, to) == false {
+				continue
+			}
+			if len(e.Temperatures)-1 > numAux {
+				numAux = len(e.Temperatures) - 1
+			}
+			res = append(res, e)
+		}
+	}
+	return mergeClimateLogs(res, numAux), numAux, nil
+}
+
+// readNodeClimate receives the climate log of the running zone from
+// a node. It has no targets.
+func (c *ExportCommand) readNodeClimate(ctx context.Context, node Node, from, to time.Time) ([]zeus.ClimateLogEntry, int, error) {
+	log, err := node.ClimateLog(ctx, c.Args.Zone)
+	if err != nil {
+		return nil, 0, err
+	}
+	res := make([]zeus.ClimateLogEntry, 0, len(log))
+	numAux := 0
+	for _, l := range log {
+		e := zeus.ClimateLogEntry{
+			ClimateReport: zeus.ClimateReport{
+				Time:         l.Time.AsTime(),
+				Humidity:     zeus.Humidity(l.Humidity),
+				Temperatures: make([]zeus.Temperature, len(l.Temperatures)),
+			},
+		}
+		if inRange(e.Time, from, to) == false {
+			continue
+		}
+		for i, t := range l.Temperatures {
+			e.Temperatures[i] = zeus.Temperature(t)
+		}
+		if len(e.Temperatures)-1 > numAux {
+			numAux = len(e.Temperatures) - 1
+		}
+		res = append(res, e)
+	}
+	return mergeClimateLogs(res, numAux), numAux, nil
+}
+
+func (c *ExportCommand) writeClimate(entries []zeus.ClimateLogEntry, numAux int) error {
+	header := zeus.ClimateLogHeader{
+		Zone:        c.Args.Zone,
+		ZeusVersion: zeus.ZEUS_VERSION,
+		AuxCount:    numAux,
+		AuxNames:    make([]string, numAux),
+	}
+	for i := range header.AuxNames {
+		header.AuxNames[i] = fmt.Sprintf("Aux %d", i+1)
+	}
+	if len(entries) > 0 {
+		header.Start = entries[0].Time
+	}
+	var encoder zeus.ClimateLogEncoder
+	if filepath.Ext(string(c.Args.Output)) == ".jsonl" {
+		encoder = zeus.NewJSONLClimateLogEncoder(header)
+	} else {
+		encoder = zeus.NewCSVClimateLogEncoder(header)
+	}
+
+	f, err := os.Create(string(c.Args.Output))
+	if err != nil {
+		return err
+	}
+	w := bufio.NewWriter(f)
+	if _, err := w.WriteString(encoder.Header()); err != nil {
+		f.Close()
+		return err
+	}
+	for _, e := range entries {
+		target := zeus.UndefinedState()
+		if e.Target != nil {
+			target = *e.Target
+		}
+		if err := encoder.Encode(w, e.ClimateReport, target); err != nil {
+			f.Close()
+			return err
+		}
+	}
+	if err := w.Flush(); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+func (c *ExportCommand) readAlarms(dir string, from, to time.Time) ([]zeus.AlarmEvent, error) {
+	files, err := logFragments(dir, c.Args.Zone, "alarms")
+	if err != nil {
+		return nil, err
+	}
+	var events []zeus.AlarmEvent
+	for _, f := range files {
+		fileEvents, err := zeus.ReadAlarmLogFile(f)
+		if err != nil {
+			return nil, fmt.Errorf("could not read '%s': %w", f, err)
+		}
+		for _, e := range fileEvents {
+			if inRange(e.Time, from, to) == true {
+				events = append(events, e)
+			}
+		}
+	}
+	return events, nil
+}
+
+// readNodeAlarms receives the alarm log of the running zone from a
+// node.
+func (c *ExportCommand) readNodeAlarms(ctx context.Context, node Node, from, to time.Time) ([]zeus.AlarmEvent, error) {
+	log, err := node.AlarmLog(ctx, c.Args.Zone)
+	if err != nil {
+		return nil, err
+	}
+	var events []zeus.AlarmEvent
+	for _, l := range log {
+		e := zeus.AlarmEvent{
+			ZoneIdentifier: c.Args.Zone,
+			Identifier:     l.Identifier,
+			Description:    l.Description,
+			Flags:          zeus.AlarmFlags(l.Flags),
+			Status:         zeus.AlarmOff,
+			Time:           l.Time.AsTime(),
+		}
+		if l.On == true {
+			e.Status = zeus.AlarmOn
+		}
+		if inRange(e.Time, from, to) == true {
+			events = append(events, e)
+		}
+	}
+	return events, nil
+}
+
+func (c *ExportCommand) writeAlarms(events []zeus.AlarmEvent) error {
+	sort.SliceStable(events, func(i, j int) bool {
+		return events[i].Time.Before(events[j].Time)
+	})
+
+	f, err := os.Create(c.Alarms)
+	if err != nil {
+		return err
+	}
+	w := csv.NewWriter(f)
+	w.Write([]string{"time", "identifier", "level", "status", "description"})
+	for i, e := range events {
+		if i > 0 && events[i-1] == e {
+			continue
+		}
+		status := "off"
+		if e.Status == zeus.AlarmOn {
+			status = "on"
+		}
+		w.Write([]string{e.Time.Format(time.RFC3339Nano), e.Identifier, e.Flags.Level(), status, e.Description})
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+func (c *ExportCommand) Execute(args []string) error {
+	from, err := parseDate(c.From)
+	if err != nil {
+		return fmt.Errorf("invalid --from: %w", err)
+	}
+	to, err := parseDate(c.To)
+	if err != nil {
+		return fmt.Errorf("invalid --to: %w", err)
+	}
+
+	if len(c.Node) > 0 {
+		if len(c.Directory) > 0 {
+			return fmt.Errorf("--node and --directory are exclusive")
+		}
+		return c.exportNode(from, to)
+	}
+
+	dir := c.Directory
+	if len(dir) == 0 {
+		dir = filepath.Join(xdg.DataHome, "fort-experiments/climate")
+	}
+
+	entries, numAux, err := c.readClimate(dir, from, to)
+	if err != nil {
+		return err
+	}
+	if err := c.write(entries, numAux); err != nil {
+		return err
+	}
+
+	if len(c.Alarms) == 0 {
+		return nil
+	}
+	events, err := c.readAlarms(dir, from, to)
+	if err == nil {
+		err = c.writeAlarms(events)
+	}
+	if err != nil {
+		return fmt.Errorf("could not export alarms: %w", err)
+	}
+	return nil
+}
+
+// exportNode exports the logs the node keeps for its running zone,
+// since the start of its experiment.
+func (c *ExportCommand) exportNode(from, to time.Time) error {
+	node, err := GetNode(c.Node)
+	if err != nil {
+		return err
+	}
+	ctx := context.Background()
+
+	entries, numAux, err := c.readNodeClimate(ctx, node, from, to)
+	if err != nil {
+		return fmt.Errorf("could not get climate log: %w", err)
+	}
+	if err := c.write(entries, numAux); err != nil {
+		return err
+	}
+
+	if len(c.Alarms) == 0 {
+		return nil
+	}
+	events, err := c.readNodeAlarms(ctx, node, from, to)
+	if err == nil {
+		err = c.writeAlarms(events)
+	}
+	if err != nil {
+		return fmt.Errorf("could not export alarms: %w", err)
+	}
+	return nil
+}
+
+func (c *ExportCommand) write(entries []zeus.ClimateLogEntry, numAux int) error {
+	entries = resampleClimateLog(entries, c.Period)
+	if err := c.writeClimate(entries, numAux); err != nil {
+		return fmt.Errorf("could not write '%s': %w", c.Args.Output, err)
+	}
+	return nil
+}
+
+func init() {
+	_, err := parser.AddCommand("export",
+		"exports the climate of a zone",
+		"merges all climate logs of a zone in a single CSV file, or JSON Lines file if OUTPUT ends with '.jsonl'. With --node, the logs of the running zone are received from the node",
+		&ExportCommand{})
+	if err != nil {
+		panic(err.Error())
+	}
+}
@@ -93,3 +93,72 @@ func (n Node) StopClimate(ctx context.Context) error {
 	_, err = client.StopClimate(ctx, &zeuspb.Empty{})
 	return mapError(err)
 }
+
+func (n Node) ListDevices(ctx context.Context) (*zeuspb.DeviceList, error) {
+	conn, client, err := n.Connect()
+	if err != nil {
+		return nil, err
+	}
+	defer closeAndLogError(conn)
+	list, err := client.ListDevices(ctx, &zeuspb.Empty{})
+	return list, mapError(err)
+}
+
+func (n Node) ReloadConfig(ctx context.Context) (*zeuspb.ConfigReload, error) {
+	conn, client, err := n.Connect()
+	if err != nil {
+		return nil, err
+	}
+	defer closeAndLogError(conn)
+	res, err := client.ReloadConfig(ctx, &zeuspb.Empty{})
+	return res, mapError(err)
+}
+
+// ClimateLog receives the climate log of a running zone, page by
+// page.
+func (n Node) ClimateLog(ctx context.Context, zone string) ([]*zeuspb.ClimateLogEntry, error) {
+	conn, client, err := n.Connect()
+	if err != nil {
+		return nil, err
+	}
+	defer closeAndLogError(conn)
+	stream, err := client.GetClimateLog(ctx, &zeuspb.LogRequest{Zone: zone})
+	if err != nil {
+		return nil, mapError(err)
+	}
+	var res []*zeuspb.ClimateLogEntry
+	for {
+		page, err := stream.Recv()
+		if err == io.EOF {
+			return res, nil
+		}
+		if err != nil {
+			return nil, mapError(err)
+		}
+		res = append(res, page.Entries...)
+	}
+}
+
+// AlarmLog receives the alarm log of a running zone, page by page.
+func (n Node) AlarmLog(ctx context.Context, zone string) ([]*zeuspb.AlarmLogEvent, error) {
+	conn, client, err := n.Connect()
+	if err != nil {
+		return nil, err
+	}
+	defer closeAndLogError(conn)
+	stream, err := client.GetAlarmLog(ctx, &zeuspb.LogRequest{Zone: zone})
+	if err != nil {
+		return nil, mapError(err)
+	}
+	var res []*zeuspb.AlarmLogEvent
+	for {
+		page, err := stream.Recv()
+		if err == io.EOF {
+			return res, nil
+		}
+		if err != nil {
+			return nil, mapError(err)
+		}
+		res = append(res, page.Events...)
+	}
+}
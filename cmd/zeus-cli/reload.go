@@ -0,0 +1,56 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+)
+
+type ReloadConfigCommand struct {
+	Args struct {
+		Node Nodename
+	} `positional-args:"yes" required:"yes"`
+}
+
+func (c *ReloadConfigCommand) Execute(args []string) (err error) {
+	ctx, span := otel.Tracer(intrumentationName).Start(context.Background(),
+		"leto-cli/ReloadConfig")
+	defer func() {
+		if err != nil {
+			span.SetStatus(codes.Error, "leto-cli error")
+			span.RecordError(err)
+		}
+		span.End()
+	}()
+
+	node, err := GetNode(c.Args.Node)
+	if err != nil {
+		return err
+	}
+	res, err := node.ReloadConfig(ctx)
+	if err != nil {
+		return err
+	}
+	if len(res.Applied) == 0 && len(res.Refused) == 0 {
+		fmt.Println("configuration unchanged")
+	}
+	for _, change := range res.Applied {
+		fmt.Printf("applied: %s\n", change)
+	}
+	for _, change := range res.Refused {
+		fmt.Printf("refused: %s\n", change)
+	}
+	return nil
+}
+
+func init() {
+	_, err := parser.AddCommand("reload-config",
+		"reloads the configuration of a node",
+		"reloads the configuration file of a node. Changes that would disrupt a running climate are refused",
+		&ReloadConfigCommand{})
+	if err != nil {
+		panic(err.Error())
+	}
+}
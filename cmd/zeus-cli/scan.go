@@ -88,12 +88,14 @@ func (c *ScanCommand) Execute(args []string) (err error) {
 
 		for _, s := range status.Zones {
 			line.Zone = node.Name + "." + s.Name
-			line.Status = fmt.Sprintf("'%s' %.2f / %.2f °C %.2f / %.2f %% R.H.",
+			line.Status = fmt.Sprintf("'%s' %.2f / %.2f °C %.2f / %.2f %% R.H. (dew point %.2f °C, VPD %.2f kPa)",
 				s.Target.Name,
 				safeCast(s.Temperature),
 				safeCast(s.Target.Temperature),
 				safeCast(s.Humidity),
 				safeCast(s.Target.Humidity),
+				safeCast(s.DewPoint),
+				safeCast(s.VaporPressureDeficit),
 			)
 
 			lines = append(lines, line)
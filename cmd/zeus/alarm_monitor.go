@@ -24,6 +24,7 @@ type alarmMonitor struct {
 	logger     *logrus.Entry
 	concatened chan string
 	name       string
+	zone       string
 
 	stagged, fired            map[string]zeus.Alarm
 	toDismiss, toFire, toKill alarmQueue
@@ -94,6 +95,7 @@ func (m *alarmMonitor) Monitor() {
 	defer func() {
 		close(m.concatened)
 		close(m.outbound)
+		zoneAlarmMetric.DeleteMatching("zone", m.zone)
 	}()
 
 	var timer <-chan time.Time
@@ -189,7 +191,7 @@ func (m *alarmMonitor) fireAny(now time.Time) {
 			continue
 		}
 		delete(m.stagged, alarm.Identifier())
-		m.outbound <- zeus.AlarmEvent{
+		event := zeus.AlarmEvent{
 			ZoneIdentifier: m.name,
 			Identifier:     alarm.Identifier(),
 			Description:    alarm.Description(),
@@ -197,10 +199,13 @@ func (m *alarmMonitor) fireAny(now time.Time) {
 			Status:         zeus.AlarmOn,
 			Time:           item.deadline.Add(-1 * alarm.MinUpTime()),
 		}
+		instruments.alarmTransition(m.zone, event)
+		m.outbound <- event
 		heap.Push(&m.toKill, &alarmItem{
 			name:     alarm.Identifier(),
 			deadline: now.Add(alarm.MinDownTime())})
 		m.fired[alarm.Identifier()] = alarm
+		zoneAlarmMetric.Set(1, m.zone, alarm.Identifier(), alarm.Flags().Level())
 	}
 }
 
@@ -213,7 +218,8 @@ func (m *alarmMonitor) killAny(now time.Time) {
 			continue
 		}
 		delete(m.fired, item.name)
-		m.outbound <- zeus.AlarmEvent{
+		zoneAlarmMetric.Set(0, m.zone, alarm.Identifier(), alarm.Flags().Level())
+		event := zeus.AlarmEvent{
 			ZoneIdentifier: m.name,
 			Identifier:     alarm.Identifier(),
 			Description:    alarm.Description(),
@@ -221,6 +227,8 @@ func (m *alarmMonitor) killAny(now time.Time) {
 			Status:         zeus.AlarmOff,
 			Time:           item.deadline.Add(-1 * alarm.MinUpTime()),
 		}
+		instruments.alarmTransition(m.zone, event)
+		m.outbound <- event
 	}
 }
 
@@ -242,6 +250,7 @@ func NewAlarmMonitor(zoneName string) (AlarmMonitor, error) {
 		inbound:    make(chan zeus.Alarm, 30),
 		outbound:   make(chan zeus.AlarmEvent, 60),
 		name:       path.Join(hostname, "zone", zoneName),
+		zone:       zoneName,
 		logger:     tm.NewLogger(path.Join("zone", zoneName, "alarm")),
 		concatened: make(chan string),
 		fired:      make(map[string]zeus.Alarm),
@@ -172,7 +172,7 @@ func (s *AlarmMonitorSuite) TestReadAlarmLogFile(c *C) {
 	filename := filepath.Join(tmpdir, "log.txt")
 	contents := make([]string, 0, len(testdata))
 	for _, alarms := range testdata {
-		am, err := NewFileAlarmReporter(filename)
+		am, err := NewFileAlarmReporter(filename, LogRotationDefinition{})
 		if c.Check(err, IsNil) == false {
 			continue
 		}
@@ -195,7 +195,7 @@ func (s *AlarmMonitorSuite) TestReadAlarmLogFile(c *C) {
 			contents = append(contents, string(cnt))
 		}
 
-		result, err := ReadAlarmLogFile(filename)
+		result, err := zeus.ReadAlarmLogFile(filename)
 		c.Check(err, IsNil)
 		c.Check(result, DeepEquals, alarms)
 	}
@@ -1,10 +1,8 @@
 package main
 
 import (
-	"bufio"
 	"encoding/json"
 	"fmt"
-	"io"
 	"os"
 
 	"github.com/formicidae-tracker/zeus/internal/zeus"
@@ -16,7 +14,7 @@ type AlarmReporter interface {
 }
 
 type fileAlarmReporter struct {
-	file   *os.File
+	file   *rotatingFile
 	events chan zeus.AlarmEvent
 }
 
@@ -36,38 +34,19 @@ func (r *fileAlarmReporter) AlarmChannel() chan<- zeus.AlarmEvent {
 	return r.events
 }
 
-func NewFileAlarmReporter(filename string) (AlarmReporter, error) {
-	file, err := os.Create(filename)
+func NewFileAlarmReporter(filename string, rotation LogRotationDefinition) (AlarmReporter, error) {
+	// appends to the log of a resumed experiment.
+	file, err := os.OpenFile(filename, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0644)
 	if err != nil {
 		return nil, err
 	}
-	return &fileAlarmReporter{
-		file:   file,
-		events: make(chan zeus.AlarmEvent),
-	}, nil
-}
-
-func ReadAlarmLogFile(filename string) ([]zeus.AlarmEvent, error) {
-	f, err := os.Open(filename)
+	rotating, err := AppendRotatingFile(file, "", rotation)
 	if err != nil {
+		file.Close()
 		return nil, err
 	}
-	defer f.Close()
-	var res []zeus.AlarmEvent
-	reader := bufio.NewReader(f)
-	for {
-		l, err := reader.ReadString('\n')
-		if err == io.EOF {
-			return res, nil
-		}
-		if err != nil {
-			return res, err
-		}
-		event := zeus.AlarmEvent{}
-		err = json.Unmarshal([]byte(l), &event)
-		if err != nil {
-			return res, err
-		}
-		res = append(res, event)
-	}
+	return &fileAlarmReporter{
+		file:   rotating,
+		events: make(chan zeus.AlarmEvent),
+	}, nil
 }
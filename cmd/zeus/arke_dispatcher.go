@@ -1,8 +1,10 @@
 package main
 
 import (
+	"fmt"
 	"os/exec"
 	"path"
+	"strconv"
 	"sync"
 	"syscall"
 	"time"
@@ -22,6 +24,9 @@ type StampedMessage struct {
 type ArkeDispatcher interface {
 	Dispatch(chan<- struct{})
 	Register(devicesID arke.NodeID) <-chan *StampedMessage
+	// RegisterLink returns a channel receiving the link state when it
+	// changes. Only the last state is kept until it is received.
+	RegisterLink() <-chan bool
 	Name() string
 	Interface() socketcan.RawInterface
 	Close() error
@@ -30,11 +35,123 @@ type ArkeDispatcher interface {
 type arkeDispatcher struct {
 	mx       sync.RWMutex
 	channels map[int][]chan *StampedMessage
+	links    []chan bool
 
 	name   string
 	intf   socketcan.RawInterface
 	logger *logrus.Entry
 	done   chan struct{}
+
+	// supervised is set when the link is brought back up after
+	// failures, with delays from backoff.
+	supervised *supervisedInterface
+	backoff    *backoff
+}
+
+// linkMonitored is implemented by interfaces knowing if their link is
+// up.
+type linkMonitored interface {
+	LinkUp() bool
+}
+
+// supervisedInterface is the socketcan.RawInterface of a supervised
+// dispatcher. It stays valid for the devices of the zones when the
+// underlying interface is reopened.
+type supervisedInterface struct {
+	mx     sync.RWMutex
+	intf   socketcan.RawInterface
+	open   func() (socketcan.RawInterface, error)
+	linkUp bool
+	quit   chan struct{}
+}
+
+func (i *supervisedInterface) current() (socketcan.RawInterface, error) {
+	i.mx.RLock()
+	defer i.mx.RUnlock()
+	select {
+	case <-i.quit:
+		return nil, syscall.EBADF
+	default:
+	}
+	if i.intf == nil {
+		return nil, syscall.ENODEV
+	}
+	return i.intf, nil
+}
+
+func (i *supervisedInterface) Send(f socketcan.CanFrame) error {
+	intf, err := i.current()
+	if err != nil {
+		return err
+	}
+	return intf.Send(f)
+}
+
+func (i *supervisedInterface) Receive() (socketcan.CanFrame, error) {
+	intf, err := i.current()
+	if err != nil {
+		return socketcan.CanFrame{}, err
+	}
+	return intf.Receive()
+}
+
+func (i *supervisedInterface) Close() error {
+	i.mx.Lock()
+	defer i.mx.Unlock()
+	select {
+	case <-i.quit:
+		return nil
+	default:
+	}
+	close(i.quit)
+	if i.intf == nil {
+		return nil
+	}
+	err := i.intf.Close()
+	i.intf = nil
+	return err
+}
+
+// reopen closes the underlying interface and opens a new one.
+func (i *supervisedInterface) reopen() error {
+	i.mx.Lock()
+	old := i.intf
+	i.intf = nil
+	i.mx.Unlock()
+	if old != nil {
+		old.Close()
+	}
+
+	intf, err := i.open()
+	if err != nil {
+		return err
+	}
+
+	i.mx.Lock()
+	defer i.mx.Unlock()
+	select {
+	case <-i.quit:
+		intf.Close()
+		return syscall.EBADF
+	default:
+	}
+	i.intf = intf
+	return nil
+}
+
+func (i *supervisedInterface) LinkUp() bool {
+	i.mx.RLock()
+	defer i.mx.RUnlock()
+	return i.linkUp
+}
+
+// setLinkUp sets the link state and returns true if it changed.
+func (i *supervisedInterface) setLinkUp(up bool) bool {
+	i.mx.Lock()
+	defer i.mx.Unlock()
+	changed := i.linkUp != up
+	i.linkUp = up
+	return changed
 }
 
 func (d *arkeDispatcher) closeChannels() {
@@ -47,6 +164,25 @@ func (d *arkeDispatcher) closeChannels() {
 		}
 	}
 	d.channels = nil
+	for _, link := range d.links {
+		close(link)
+	}
+	d.links = nil
+}
+
+// notifyLink sends the link state to the registered channels. It is
+// only called by Dispatch, so it never blocks.
+func (d *arkeDispatcher) notifyLink(up bool) {
+	d.mx.RLock()
+	defer d.mx.RUnlock()
+	for _, link := range d.links {
+		select {
+		case <-link:
+			// drops the previous state, not received yet.
+		default:
+		}
+		link <- up
+	}
 }
 
 func (d *arkeDispatcher) nonBlockingSend(m *StampedMessage, c chan<- *StampedMessage) {
@@ -54,6 +190,7 @@ func (d *arkeDispatcher) nonBlockingSend(m *StampedMessage, c chan<- *StampedMes
 	case c <- m:
 		return
 	default:
+		dispatcherDropMetric.Add(1, d.name, strconv.Itoa(int(m.ID)))
 		d.logger.WithFields(logrus.Fields{
 			"ID":      m.ID,
 			"message": m.M.String(),
@@ -78,24 +215,27 @@ func (d *arkeDispatcher) Dispatch(ready chan<- struct{}) {
 	d.done = make(chan struct{})
 
 	defer close(d.done)
+	// tells the receivers that no more messages will come.
+	defer d.closeChannels()
 	d.logger.Info("started")
 	close(ready)
 	for {
 		f, err := d.intf.Receive()
 		if err != nil {
-			if errno, ok := err.(syscall.Errno); ok == true {
-				if errno == syscall.EBADF || errno == syscall.ENETDOWN || errno == syscall.ENODEV {
-					return
-				}
+			if d.handleReceiveError(err) == false {
+				return
 			}
-			d.logger.WithError(err).Error("could not receive CAN frame")
 		} else {
+			d.setLinkUp(true)
 			t := time.Now()
+			instruments.frameReceived(d.name)
 			m, ID, err := arke.ParseMessage(&f)
 			if err != nil {
+				instruments.parseError(d.name)
 				d.logger.WithError(err).Error("could not parse CAN frame")
 				continue
 			}
+			instruments.frameParsed(d.name, m.MessageClassID())
 			d.dispatchMessage(&StampedMessage{
 				M:  m,
 				ID: ID,
@@ -105,6 +245,51 @@ func (d *arkeDispatcher) Dispatch(ready chan<- struct{}) {
 	}
 }
 
+func (d *arkeDispatcher) setLinkUp(up bool) {
+	if d.supervised == nil || d.supervised.setLinkUp(up) == false {
+		return
+	}
+	if up == true {
+		d.backoff.reset()
+		d.logger.Info("link is up")
+	} else {
+		d.logger.Warn("link is down")
+	}
+	d.notifyLink(up)
+}
+
+// handleReceiveError returns false if dispatching should stop. A
+// supervised dispatcher waits for a down link to come back, and
+// reopens the interface if it disappeared. Registered channels are
+// kept meanwhile.
+func (d *arkeDispatcher) handleReceiveError(err error) bool {
+	errno, ok := err.(syscall.Errno)
+	if ok == false || (errno != syscall.EBADF && errno != syscall.ENETDOWN && errno != syscall.ENODEV) {
+		d.logger.WithError(err).Error("could not receive CAN frame")
+		return true
+	}
+	if errno == syscall.EBADF || d.supervised == nil {
+		return false
+	}
+	d.setLinkUp(false)
+	select {
+	case <-d.supervised.quit:
+		return false
+	case <-time.After(d.backoff.next()):
+	}
+	if errno == syscall.ENETDOWN {
+		// the socket is still bound and receives again once the link
+		// is up.
+		return true
+	}
+	if err := d.supervised.reopen(); err != nil {
+		d.logger.WithError(err).Warn("could not reopen interface")
+	} else {
+		d.logger.Info("interface reopened")
+	}
+	return true
+}
+
 func (d *arkeDispatcher) Register(devicesID arke.NodeID) <-chan *StampedMessage {
 	if d.channels == nil {
 		d.logger.Panic("register on closed dispatcher")
@@ -120,6 +305,19 @@ func (d *arkeDispatcher) Register(devicesID arke.NodeID) <-chan *StampedMessage
 	return newChannel
 }
 
+func (d *arkeDispatcher) RegisterLink() <-chan bool {
+	if d.channels == nil {
+		d.logger.Panic("register on closed dispatcher")
+	}
+
+	d.mx.Lock()
+	defer d.mx.Unlock()
+
+	res := make(chan bool, 1)
+	d.links = append(d.links, res)
+	return res
+}
+
 func (d *arkeDispatcher) Send(id arke.NodeID, m arke.SendableMessage) error {
 	return arke.SendMessage(d.intf, m, false, id)
 }
@@ -149,8 +347,8 @@ func (d *arkeDispatcher) Close() error {
 		d.done = nil
 		return err
 	case <-time.After(3 * time.Second):
-		d.logger.Panic("closing hangup") // will panic
-		return nil
+		// the dispatch loop is left behind, its channels are closed.
+		return fmt.Errorf("dispatcher on %s did not stop", d.name)
 	}
 }
 
@@ -178,3 +376,28 @@ func NewArkeDispatcher(ifname string, intf socketcan.RawInterface) ArkeDispatche
 		logger:   tm.NewLogger(path.Join("dispatch", ifname)),
 	}
 }
+
+// NewSupervisedArkeDispatcher returns a dispatcher for the interface
+// opened by open. When its link goes down, it waits for it to come
+// back up and reopens the interface if needed, without closing the
+// channels of the registered zones.
+func NewSupervisedArkeDispatcher(ifname string, open func() (socketcan.RawInterface, error)) (ArkeDispatcher, error) {
+	intf, err := open()
+	if err != nil {
+		return nil, err
+	}
+	supervised := &supervisedInterface{
+		intf:   intf,
+		open:   open,
+		linkUp: true,
+		quit:   make(chan struct{}),
+	}
+	return &arkeDispatcher{
+		channels:   make(map[int][]chan *StampedMessage),
+		name:       ifname,
+		intf:       supervised,
+		logger:     tm.NewLogger(path.Join("dispatch", ifname)),
+		supervised: supervised,
+		backoff:    newBackoff(LinkMinBackoff, LinkMaxBackoff),
+	}, nil
+}
@@ -1,6 +1,10 @@
 package main
 
 import (
+	"syscall"
+	"time"
+
+	socketcan "github.com/atuleu/golang-socketcan"
 	"github.com/formicidae-tracker/libarke/src-go/arke"
 	"github.com/sirupsen/logrus"
 	"github.com/sirupsen/logrus/hooks/test"
@@ -114,3 +118,76 @@ func (s *ArkeDispatcherSuite) TestDoesNotHangUp(c *C) {
 	c.Check(s.hook.Entries[1].Data["message"], Equals, "Celaeno.SetPoint{Power: 0}")
 
 }
+
+func (s *ArkeDispatcherSuite) TestSupervisedReopensInterface(c *C) {
+	opened := make(chan *StubRawInterface, 10)
+	d, err := NewSupervisedArkeDispatcher("can-stub", func() (socketcan.RawInterface, error) {
+		intf := NewStubRawInterface()
+		opened <- intf
+		return intf, nil
+	})
+	c.Assert(err, IsNil)
+	d.(*arkeDispatcher).backoff = newBackoff(time.Millisecond, 10*time.Millisecond)
+	link := d.Interface().(linkMonitored)
+	first := <-opened
+
+	messages := d.Register(1)
+	links := d.RegisterLink()
+	ready := make(chan struct{})
+	go d.Dispatch(ready)
+	<-ready
+	go first.enqueue(&arke.CelaenoSetPoint{}, 1)
+	_, ok := <-messages
+	c.Check(ok, Equals, true)
+	c.Check(link.LinkUp(), Equals, true)
+
+	// a down link is waited for on the same interface.
+	first.fail(syscall.ENETDOWN)
+	go first.enqueue(&arke.CelaenoSetPoint{}, 1)
+	_, ok = <-messages
+	c.Check(ok, Equals, true)
+	c.Check(link.LinkUp(), Equals, true)
+	c.Check(len(opened), Equals, 0)
+	// only the last link state is kept.
+	c.Check(<-links, Equals, true)
+	c.Check(len(links), Equals, 0)
+
+	// a removed interface is reopened.
+	first.fail(syscall.ENODEV)
+	var second *StubRawInterface
+	select {
+	case second = <-opened:
+	case <-time.After(time.Second):
+		c.Fatalf("interface was not reopened")
+	}
+	c.Check(link.LinkUp(), Equals, false)
+	c.Check(<-links, Equals, false)
+	c.Check(first.isClosed(), Equals, true)
+	go second.enqueue(&arke.CelaenoSetPoint{}, 1)
+	_, ok = <-messages
+	c.Check(ok, Equals, true)
+	c.Check(link.LinkUp(), Equals, true)
+	c.Check(<-links, Equals, true)
+	c.Check(d.Interface().Send(socketcan.CanFrame{}), IsNil)
+
+	c.Check(d.Close(), IsNil)
+	c.Check(second.isClosed(), Equals, true)
+	_, ok = <-messages
+	c.Check(ok, Equals, false)
+	_, ok = <-links
+	c.Check(ok, Equals, false)
+}
+
+func (s *ArkeDispatcherSuite) TestClosesChannelsWhenDead(c *C) {
+	messages := s.d.Register(1)
+	ready := make(chan struct{})
+	go s.d.Dispatch(ready)
+	<-ready
+	s.intf.fail(syscall.ENODEV)
+	select {
+	case _, ok := <-messages:
+		c.Check(ok, Equals, false)
+	case <-time.After(time.Second):
+		c.Fatalf("channel was not closed")
+	}
+}
@@ -0,0 +1,669 @@
+package main
+
+import (
+	"fmt"
+	"path"
+	"sync"
+	"syscall"
+	"time"
+
+	socketcan "github.com/atuleu/golang-socketcan"
+	"github.com/formicidae-tracker/libarke/src-go/arke"
+	"github.com/formicidae-tracker/olympus/pkg/tm"
+	"github.com/formicidae-tracker/zeus/internal/zeus"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	emulatorTick            = 50 * time.Millisecond
+	emulatorReportPeriod    = 500 * time.Millisecond
+	emulatorWatchdogTimeout = 30 * time.Second
+	emulatorFanRPM          = 1200
+	emulatorAgingFanRPM     = 600
+)
+
+// emulatedDevice is the class specific behavior of an EmulatedNode.
+type emulatedDevice interface {
+	// handle processes a message sent to the node and returns the
+	// messages it sends back.
+	handle(m arke.ReceivableMessage, now time.Time) []arke.SendableMessage
+	// step returns the messages the node periodically sends. elapsed
+	// is the emulated time since the last step.
+	step(now time.Time, elapsed time.Duration) []arke.SendableMessage
+	reset()
+	// inject sets the scenario faults currently affecting the node.
+	inject(faults []Fault)
+}
+
+// EmulatedNode is an Arke node emulated by an ArkeEmulator.
+type EmulatedNode struct {
+	Class        arke.NodeClass
+	ID           arke.NodeID
+	MajorVersion uint8
+	MinorVersion uint8
+	// Zone is the name of the zone of the node, targeted by scenario
+	// faults.
+	Zone string
+
+	device          emulatedDevice
+	setPoint        arke.ReceivableMessage
+	resets          int
+	heartbeatPeriod time.Duration
+	nextHeartbeat   time.Time
+	nextStep        time.Time
+	lastStep        time.Time
+	missing         bool
+}
+
+// NewEmulatedNode returns a node of class c with the given ID. Zeus
+// and Celaeno nodes drive and report the climate of plant.
+func NewEmulatedNode(c arke.NodeClass, ID arke.NodeID, plant *PlantModel) *EmulatedNode {
+	res := &EmulatedNode{
+		Class:        c,
+		ID:           ID,
+		MajorVersion: 1,
+		MinorVersion: 0,
+	}
+	switch c {
+	case arke.ZeusClass:
+		res.device = newEmulatedZeus(plant)
+	case arke.CelaenoClass:
+		res.device = newEmulatedCelaeno(plant)
+	default:
+		res.device = passiveDevice{}
+	}
+	return res
+}
+
+// NewEmulatedZone returns the nodes of a zone: a Zeus, a Celaeno, an
+// Helios and optionally a Notus, all using devicesID and sharing
+// plant.
+func NewEmulatedZone(devicesID arke.NodeID, withNotus bool, plant *PlantModel) []*EmulatedNode {
+	res := []*EmulatedNode{
+		NewEmulatedNode(arke.ZeusClass, devicesID, plant),
+		NewEmulatedNode(arke.CelaenoClass, devicesID, plant),
+		NewEmulatedNode(arke.HeliosClass, devicesID, plant),
+	}
+	if withNotus == true {
+		res = append(res, NewEmulatedNode(arke.NotusClass, devicesID, plant))
+	}
+	return res
+}
+
+func (n *EmulatedNode) reset() {
+	n.resets += 1
+	n.reboot()
+}
+
+func (n *EmulatedNode) reboot() {
+	n.setPoint = nil
+	n.heartbeatPeriod = 0
+	n.device.reset()
+}
+
+func (n *EmulatedNode) heartbeat() socketcan.CanFrame {
+	return socketcan.CanFrame{
+		ID:   arke.MakeCANIDT(arke.HeartBeat, arke.MessageClass(n.Class), n.ID),
+		Dlc:  2,
+		Data: []byte{n.MajorVersion, n.MinorVersion},
+	}
+}
+
+type passiveDevice struct{}
+
+func (passiveDevice) handle(arke.ReceivableMessage, time.Time) []arke.SendableMessage { return nil }
+
+func (passiveDevice) step(time.Time, time.Duration) []arke.SendableMessage { return nil }
+
+func (passiveDevice) reset() {}
+
+func (passiveDevice) inject([]Fault) {}
+
+// emulatedFan returns the status and speed of a fan.
+func emulatedFan(s arke.FanStatus) arke.FanStatusAndRPM {
+	switch s {
+	case arke.FanStalled:
+		return arke.FanStatusAndRPM(uint16(s) << 14)
+	case arke.FanAging:
+		return arke.FanStatusAndRPM(emulatorAgingFanRPM | uint16(s)<<14)
+	default:
+		return emulatorFanRPM
+	}
+}
+
+// faultyFan returns the status of the fan targeted by fault, or
+// arke.FanOK if it is not a fan fault.
+func faultyFan(f Fault) arke.FanStatus {
+	switch f.Kind {
+	case FanStalled:
+		return arke.FanStalled
+	case FanAging:
+		return arke.FanAging
+	default:
+		return arke.FanOK
+	}
+}
+
+// unreadableZeusReport is the report sent by a Zeus failing to read
+// its sensor: its values decode as NaN.
+type unreadableZeusReport struct{}
+
+func (unreadableZeusReport) MessageClassID() arke.MessageClass {
+	return arke.ZeusReportMessage
+}
+
+func (unreadableZeusReport) Marshal(buf []byte) (int, error) {
+	if len(buf) < 8 {
+		return 0, fmt.Errorf("buffer too small")
+	}
+	for i := 0; i < 8; i++ {
+		buf[i] = 0xff
+	}
+	return 8, nil
+}
+
+func (unreadableZeusReport) String() string {
+	return "Zeus.Report{Unreadable}"
+}
+
+// emulatedZeus regulates and reports the climate of its plant. It
+// stops regulating when it does not receive any set point for some
+// time.
+type emulatedZeus struct {
+	plant        *PlantModel
+	status       arke.ZeusStatus
+	lastSetPoint time.Time
+
+	fans          [3]arke.FanStatus
+	sensorFailure bool
+}
+
+func newEmulatedZeus(plant *PlantModel) *emulatedZeus {
+	res := &emulatedZeus{plant: plant}
+	res.reset()
+	return res
+}
+
+func (z *emulatedZeus) reset() {
+	z.plant.SetTarget(zeus.UndefinedTemperature, zeus.UndefinedHumidity)
+	z.status = arke.ZeusStatus{Status: arke.ZeusIdle}
+	z.updateStatus()
+}
+
+func (z *emulatedZeus) inject(faults []Fault) {
+	z.fans = [3]arke.FanStatus{}
+	z.sensorFailure = false
+	for _, f := range faults {
+		if f.Kind == SensorNaN {
+			z.sensorFailure = true
+		}
+		if s := faultyFan(f); s != arke.FanOK {
+			z.fans[f.Fan] = s
+		}
+	}
+}
+
+func (z *emulatedZeus) updateStatus() {
+	for i, s := range z.fans {
+		z.status.Fans[i] = emulatedFan(s)
+	}
+	if z.status.Status&arke.ZeusActive == 0 {
+		return
+	}
+	if z.sensorFailure == true {
+		// the firmware stops regulating without its sensor.
+		z.plant.SetTarget(zeus.UndefinedTemperature, zeus.UndefinedHumidity)
+		z.status.Status = arke.ZeusActive | arke.ZeusClimateNotControlledWatchDog
+		return
+	}
+	z.status.Status = arke.ZeusActive | z.plant.ZeusStatus()
+}
+
+func (z *emulatedZeus) handle(m arke.ReceivableMessage, now time.Time) []arke.SendableMessage {
+	sp, ok := m.(*arke.ZeusSetPoint)
+	if ok == false {
+		return nil
+	}
+	z.lastSetPoint = now
+	z.plant.SetTarget(zeus.Temperature(sp.Temperature), zeus.Humidity(sp.Humidity))
+	z.status.Status = arke.ZeusActive
+	z.updateStatus()
+	status := z.status
+	return []arke.SendableMessage{&status}
+}
+
+func (z *emulatedZeus) step(now time.Time, elapsed time.Duration) []arke.SendableMessage {
+	z.plant.Step(elapsed)
+	if z.status.Status&arke.ZeusActive != 0 && now.Sub(z.lastSetPoint) > emulatorWatchdogTimeout {
+		z.plant.SetTarget(zeus.UndefinedTemperature, zeus.UndefinedHumidity)
+		z.status.Status = arke.ZeusClimateNotControlledWatchDog
+	}
+	z.updateStatus()
+	status := z.status
+	if z.sensorFailure == true {
+		return []arke.SendableMessage{unreadableZeusReport{}, &status}
+	}
+	temperature := float32(z.plant.Temperature)
+	report := arke.ZeusReport{
+		Humidity:    float32(z.plant.Humidity),
+		Temperature: [4]float32{temperature, temperature, temperature, temperature},
+	}
+	return []arke.SendableMessage{&report, &status}
+}
+
+// emulatedCelaeno reports the water level of its plant.
+type emulatedCelaeno struct {
+	plant *PlantModel
+
+	fan   arke.FanStatus
+	empty bool
+}
+
+func newEmulatedCelaeno(plant *PlantModel) *emulatedCelaeno {
+	return &emulatedCelaeno{plant: plant}
+}
+
+func (c *emulatedCelaeno) reset() {}
+
+func (c *emulatedCelaeno) inject(faults []Fault) {
+	c.fan = arke.FanOK
+	empty := false
+	for _, f := range faults {
+		if f.Kind == WaterEmpty {
+			empty = true
+		}
+		if s := faultyFan(f); s != arke.FanOK {
+			c.fan = s
+		}
+	}
+	if empty == true {
+		c.plant.WaterLevel = 0
+	} else if c.empty == true {
+		c.plant.Refill()
+	}
+	c.empty = empty
+}
+
+func (c *emulatedCelaeno) status() arke.SendableMessage {
+	return &arke.CelaenoStatus{
+		WaterLevel: c.plant.WaterLevelStatus(),
+		Fan:        emulatedFan(c.fan),
+	}
+}
+
+func (c *emulatedCelaeno) handle(m arke.ReceivableMessage, now time.Time) []arke.SendableMessage {
+	if _, ok := m.(*arke.CelaenoSetPoint); ok == false {
+		return nil
+	}
+	return []arke.SendableMessage{c.status()}
+}
+
+func (c *emulatedCelaeno) step(now time.Time, elapsed time.Duration) []arke.SendableMessage {
+	return []arke.SendableMessage{c.status()}
+}
+
+// ArkeEmulator is a socketcan.RawInterface emulating a bus of Arke
+// nodes, so climate control can run without any hardware. Nodes
+// answer heartbeat and reset requests, acknowledge set points and
+// periodically send their reports and status. Faults of a Scenario
+// are injected over the emulated time.
+type ArkeEmulator struct {
+	mx        sync.Mutex
+	nodes     []*EmulatedNode
+	period    time.Duration
+	timeRatio float64
+
+	scenario *scenarioPlayer
+	lastTick time.Time
+	busDown  bool
+	down     chan struct{}
+
+	frames    chan socketcan.CanFrame
+	quit      chan struct{}
+	done      chan struct{}
+	closeOnce sync.Once
+
+	logger *logrus.Entry
+}
+
+type ArkeEmulatorOptions struct {
+	Name string
+	// ReportPeriod is the period of the nodes reports and status,
+	// defaults to 500ms.
+	ReportPeriod time.Duration
+	// TimeRatio accelerates the plants of the nodes, defaults to 1.0.
+	TimeRatio float64
+	Nodes     []*EmulatedNode
+	// Scenario is the optional list of faults to inject.
+	Scenario *Scenario
+}
+
+func NewArkeEmulator(o ArkeEmulatorOptions) *ArkeEmulator {
+	if o.ReportPeriod <= 0 {
+		o.ReportPeriod = emulatorReportPeriod
+	}
+	if o.TimeRatio <= 0 {
+		o.TimeRatio = 1.0
+	}
+	res := &ArkeEmulator{
+		nodes:     o.Nodes,
+		period:    o.ReportPeriod,
+		timeRatio: o.TimeRatio,
+		scenario:  newScenarioPlayer(o.Scenario),
+		down:      make(chan struct{}),
+		frames:    make(chan socketcan.CanFrame, 64),
+		quit:      make(chan struct{}),
+		done:      make(chan struct{}),
+		logger:    tm.NewLogger(path.Join("emulator", o.Name)),
+	}
+	// faults starting right away apply before the first frame.
+	res.injectFaults(time.Now())
+	go res.run()
+	return res
+}
+
+func (e *ArkeEmulator) run() {
+	defer close(e.done)
+	ticker := time.NewTicker(emulatorTick)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-e.quit:
+			return
+		case now := <-ticker.C:
+			e.step(now)
+		}
+	}
+}
+
+func (e *ArkeEmulator) hasZone(zone string) bool {
+	for _, n := range e.nodes {
+		if n.Zone == zone {
+			return true
+		}
+	}
+	return false
+}
+
+func (e *ArkeEmulator) setBusDown(down bool) {
+	if down == e.busDown {
+		return
+	}
+	e.busDown = down
+	e.logger.WithField("down", down).Warn("bus state changed")
+	if down == false {
+		e.down = make(chan struct{})
+		return
+	}
+	close(e.down)
+	for {
+		select {
+		case <-e.frames:
+		default:
+			return
+		}
+	}
+}
+
+// injectFaults applies the scenario faults at now.
+func (e *ArkeEmulator) injectFaults(now time.Time) {
+	var elapsed time.Duration
+	if e.lastTick.IsZero() == false {
+		elapsed = time.Duration(float64(now.Sub(e.lastTick)) * e.timeRatio)
+	}
+	e.lastTick = now
+	started, _ := e.scenario.advance(elapsed)
+	active := e.scenario.activeFaults()
+
+	busDown := false
+	for _, f := range active {
+		if f.Kind == BusDown && (len(f.Zone) == 0 || e.hasZone(f.Zone) == true) {
+			busDown = true
+		}
+	}
+	e.setBusDown(busDown)
+
+	for _, n := range e.nodes {
+		var faults []Fault
+		n.missing = false
+		for _, f := range active {
+			if f.Targets(n.Zone, n.Class) == false {
+				continue
+			}
+			faults = append(faults, f)
+			if f.Kind == DeviceMissing {
+				n.missing = true
+			}
+		}
+		n.device.inject(faults)
+		for _, f := range started {
+			if f.Kind != WatchdogReset || f.Targets(n.Zone, n.Class) == false {
+				continue
+			}
+			e.logger.WithFields(logrus.Fields{
+				"class": arke.ClassName(n.Class),
+				"ID":    n.ID,
+			}).Info("watchdog reset")
+			n.reboot()
+			if z, ok := n.device.(*emulatedZeus); ok == true {
+				z.status.Status = arke.ZeusClimateNotControlledWatchDog
+			}
+			e.emitFrame(n, n.heartbeat())
+		}
+	}
+}
+
+func (e *ArkeEmulator) step(now time.Time) {
+	e.mx.Lock()
+	defer e.mx.Unlock()
+	e.injectFaults(now)
+	for _, n := range e.nodes {
+		if n.heartbeatPeriod > 0 && now.Before(n.nextHeartbeat) == false {
+			n.nextHeartbeat = now.Add(n.heartbeatPeriod)
+			e.emitFrame(n, n.heartbeat())
+		}
+		if now.Before(n.nextStep) == true {
+			continue
+		}
+		n.nextStep = now.Add(e.period)
+		var elapsed time.Duration
+		if n.lastStep.IsZero() == false {
+			elapsed = time.Duration(float64(now.Sub(n.lastStep)) * e.timeRatio)
+		}
+		n.lastStep = now
+		e.emit(n, n.device.step(now, elapsed))
+	}
+}
+
+// emitFrame sends a frame of n on the bus, unless n or the bus is
+// down.
+func (e *ArkeEmulator) emitFrame(n *EmulatedNode, f socketcan.CanFrame) {
+	if n.missing == true || e.busDown == true {
+		return
+	}
+	select {
+	case e.frames <- f:
+	case <-e.quit:
+	}
+}
+
+func (e *ArkeEmulator) emit(n *EmulatedNode, messages []arke.SendableMessage) {
+	for _, m := range messages {
+		f := socketcan.CanFrame{
+			ID:   arke.MakeCANIDT(arke.StandardMessage, m.MessageClassID(), n.ID),
+			Data: make([]byte, 8),
+		}
+		dlc, err := m.Marshal(f.Data)
+		if err != nil {
+			e.logger.WithError(err).WithField("message", m.String()).Error("could not marshal message")
+			continue
+		}
+		f.Dlc = uint8(dlc)
+		e.emitFrame(n, f)
+	}
+}
+
+func (e *ArkeEmulator) matchingNodes(c arke.NodeClass, ID arke.NodeID) []*EmulatedNode {
+	var res []*EmulatedNode
+	for _, n := range e.nodes {
+		if c != arke.BroadcastClass && c != n.Class {
+			continue
+		}
+		if ID != arke.BroadcastID && ID != n.ID {
+			continue
+		}
+		res = append(res, n)
+	}
+	return res
+}
+
+// reachableNodes returns the matching nodes that are not missing.
+func (e *ArkeEmulator) reachableNodes(c arke.NodeClass, ID arke.NodeID) []*EmulatedNode {
+	var res []*EmulatedNode
+	for _, n := range e.matchingNodes(c, ID) {
+		if n.missing == false {
+			res = append(res, n)
+		}
+	}
+	return res
+}
+
+func (e *ArkeEmulator) handleHeartBeatRequest(m *arke.HeartBeatRequestData, now time.Time) {
+	for _, n := range e.reachableNodes(m.Class, arke.BroadcastID) {
+		// a null period is a single ping.
+		if m.Period != 0 {
+			n.heartbeatPeriod = m.Period
+			n.nextHeartbeat = now.Add(m.Period)
+		}
+		e.emitFrame(n, n.heartbeat())
+	}
+}
+
+func (e *ArkeEmulator) handleResetRequest(m *arke.ResetRequestData) {
+	for _, n := range e.reachableNodes(m.Class, m.ID) {
+		e.logger.WithFields(logrus.Fields{
+			"class": arke.ClassName(n.Class),
+			"ID":    n.ID,
+		}).Info("reset")
+		n.reset()
+		// nodes announce themselves once rebooted.
+		e.emitFrame(n, n.heartbeat())
+	}
+}
+
+// Send processes a frame sent on the bus. Frames that are not
+// understood by any node are silently ignored, like on a real bus.
+func (e *ArkeEmulator) Send(f socketcan.CanFrame) error {
+	select {
+	case <-e.quit:
+		return syscall.EBADF
+	default:
+	}
+
+	now := time.Now()
+	e.mx.Lock()
+	defer e.mx.Unlock()
+	if e.busDown == true {
+		return syscall.ENETDOWN
+	}
+	if f.RTR == true {
+		return nil
+	}
+	m, ID, err := arke.ParseMessage(&f)
+	if err != nil {
+		return nil
+	}
+
+	switch mm := m.(type) {
+	case *arke.HeartBeatRequestData:
+		e.handleHeartBeatRequest(mm, now)
+	case *arke.ResetRequestData:
+		e.handleResetRequest(mm)
+	default:
+		// message classes are allocated by ranges of 4 per node class.
+		class := arke.NodeClass(m.MessageClassID() & 0x3c)
+		for _, n := range e.reachableNodes(class, ID) {
+			// set point messages share their ID with the node class.
+			if m.MessageClassID() == arke.MessageClass(class) {
+				n.setPoint = m
+			}
+			e.emit(n, n.device.handle(m, now))
+		}
+	}
+	return nil
+}
+
+// Receive returns the next frame sent by the nodes. It fails with
+// syscall.ENETDOWN while the bus is down.
+func (e *ArkeEmulator) Receive() (socketcan.CanFrame, error) {
+	e.mx.Lock()
+	down := e.down
+	e.mx.Unlock()
+	select {
+	case <-down:
+		return socketcan.CanFrame{}, syscall.ENETDOWN
+	default:
+	}
+	select {
+	case f := <-e.frames:
+		return f, nil
+	case <-down:
+		return socketcan.CanFrame{}, syscall.ENETDOWN
+	case <-e.quit:
+		return socketcan.CanFrame{}, syscall.EBADF
+	}
+}
+
+func (e *ArkeEmulator) Close() error {
+	e.closeOnce.Do(func() { close(e.quit) })
+	<-e.done
+	return nil
+}
+
+// LastSetPoint returns the last set point received by the node of
+// class c and the given ID, or nil if none was received since its
+// last reset.
+func (e *ArkeEmulator) LastSetPoint(c arke.NodeClass, ID arke.NodeID) arke.ReceivableMessage {
+	e.mx.Lock()
+	defer e.mx.Unlock()
+	for _, n := range e.matchingNodes(c, ID) {
+		return n.setPoint
+	}
+	return nil
+}
+
+// Resets returns the number of reset requests received by the node
+// of class c and the given ID.
+func (e *ArkeEmulator) Resets(c arke.NodeClass, ID arke.NodeID) int {
+	e.mx.Lock()
+	defer e.mx.Unlock()
+	for _, n := range e.matchingNodes(c, ID) {
+		return n.resets
+	}
+	return 0
+}
+
+// emulatorFactory returns an interface factory creating, for each
+// interface, an ArkeEmulator with the nodes of the zones using it and
+// the faults of scenario, which may be nil.
+func emulatorFactory(zones map[string]ZoneDefinition, plant PlantParameters, scenario *Scenario, timeRatio float64) func(string) (socketcan.RawInterface, error) {
+	return func(ifname string) (socketcan.RawInterface, error) {
+		var nodes []*EmulatedNode
+		for name, definition := range zones {
+			if definition.CANInterface != ifname {
+				continue
+			}
+			zone := NewEmulatedZone(arke.NodeID(definition.DevicesID), definition.HasNotusDevice, NewPlantModel(plant))
+			for _, n := range zone {
+				n.Zone = name
+			}
+			nodes = append(nodes, zone...)
+		}
+		return NewArkeEmulator(ArkeEmulatorOptions{
+			Name:      ifname,
+			TimeRatio: timeRatio,
+			Nodes:     nodes,
+			Scenario:  scenario,
+		}), nil
+	}
+}
@@ -0,0 +1,212 @@
+package main
+
+import (
+	"math"
+	"syscall"
+	"time"
+
+	"github.com/formicidae-tracker/libarke/src-go/arke"
+	. "gopkg.in/check.v1"
+)
+
+type ArkeEmulatorSuite struct {
+	emulator *ArkeEmulator
+}
+
+var _ = Suite(&ArkeEmulatorSuite{})
+
+func (s *ArkeEmulatorSuite) SetUpTest(c *C) {
+	s.emulator = NewArkeEmulator(ArkeEmulatorOptions{
+		Name:         "vcan-test",
+		ReportPeriod: 100 * time.Millisecond,
+		// a minute of the plant every report.
+		TimeRatio: 600,
+		Nodes:     NewEmulatedZone(1, false, NewPlantModel(DefaultPlantParameters())),
+	})
+}
+
+func (s *ArkeEmulatorSuite) TearDownTest(c *C) {
+	c.Check(s.emulator.Close(), IsNil)
+}
+
+// receiveUntil returns the first message received of the given class.
+func (s *ArkeEmulatorSuite) receiveUntil(c *C, class arke.MessageClass) arke.ReceivableMessage {
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		f, err := s.emulator.Receive()
+		c.Assert(err, IsNil)
+		m, _, err := arke.ParseMessage(&f)
+		c.Assert(err, IsNil)
+		if m.MessageClassID() == class {
+			return m
+		}
+	}
+	c.Fatalf("did not receive %s", class)
+	return nil
+}
+
+func (s *ArkeEmulatorSuite) TestHeartBeat(c *C) {
+	c.Assert(s.emulator.Send(arke.MakeHeartBeatRequest(arke.CelaenoClass, 200*time.Millisecond)), IsNil)
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		m := s.receiveUntil(c, arke.HeartBeatMessage).(*arke.HeartBeatData)
+		c.Check(m.Class, Equals, arke.CelaenoClass)
+		c.Check(m.ID, Equals, arke.NodeID(1))
+		c.Check(m.MajorVersion, Equals, uint8(1))
+	}
+	c.Check(time.Since(start) > 350*time.Millisecond, Equals, true)
+}
+
+func (s *ArkeEmulatorSuite) TestSetPointIsAcknowledged(c *C) {
+	status := s.receiveUntil(c, arke.ZeusStatusMessage).(*arke.ZeusStatus)
+	c.Check(status.Status, Equals, arke.ZeusIdle)
+
+	c.Assert(arke.SendMessage(s.emulator, &arke.ZeusSetPoint{
+		Temperature: 26.0,
+		Humidity:    60.0,
+		Wind:        127,
+	}, false, 1), IsNil)
+	// status sent before the set point may still be queued.
+	for status.Status == arke.ZeusIdle {
+		status = s.receiveUntil(c, arke.ZeusStatusMessage).(*arke.ZeusStatus)
+	}
+	c.Check(status.Status, Equals, arke.ZeusActive)
+
+	report := s.receiveUntil(c, arke.ZeusReportMessage).(*arke.ZeusReport)
+	c.Check(report.Temperature[0] < 26.0, Equals, true)
+	for i := 0; i < 20 && (report.Temperature[0] < 25.9 || report.Humidity < 59.9); i++ {
+		report = s.receiveUntil(c, arke.ZeusReportMessage).(*arke.ZeusReport)
+	}
+	// values are quantized by the sensors binary format.
+	c.Check(math.Abs(float64(report.Temperature[0])-26.0) < 0.1, Equals, true)
+	c.Check(math.Abs(float64(report.Humidity)-60.0) < 0.1, Equals, true)
+
+	sp, ok := s.emulator.LastSetPoint(arke.ZeusClass, 1).(*arke.ZeusSetPoint)
+	c.Assert(ok, Equals, true)
+	c.Check(sp.Wind, Equals, uint8(127))
+	c.Check(s.emulator.LastSetPoint(arke.ZeusClass, 2), IsNil)
+}
+
+func (s *ArkeEmulatorSuite) TestReset(c *C) {
+	c.Assert(arke.SendMessage(s.emulator, &arke.HeliosSetPoint{Visible: 255}, false, 1), IsNil)
+	c.Assert(arke.SendMessage(s.emulator, &arke.ZeusSetPoint{Temperature: 26.0}, false, 1), IsNil)
+	s.receiveUntil(c, arke.ZeusStatusMessage)
+
+	c.Assert(s.emulator.Send(arke.MakeResetRequest(arke.ZeusClass, 1)), IsNil)
+	hb := s.receiveUntil(c, arke.HeartBeatMessage).(*arke.HeartBeatData)
+	c.Check(hb.Class, Equals, arke.ZeusClass)
+	c.Check(s.emulator.Resets(arke.ZeusClass, 1), Equals, 1)
+	c.Check(s.emulator.Resets(arke.HeliosClass, 1), Equals, 0)
+	c.Check(s.emulator.LastSetPoint(arke.ZeusClass, 1), IsNil)
+	c.Check(s.emulator.LastSetPoint(arke.HeliosClass, 1), NotNil)
+
+	status := s.receiveUntil(c, arke.ZeusStatusMessage).(*arke.ZeusStatus)
+	c.Check(status.Status, Equals, arke.ZeusIdle)
+}
+
+// scenarioEmulator replaces the emulator by one injecting faults.
+func (s *ArkeEmulatorSuite) scenarioEmulator(c *C, faults ...Fault) {
+	c.Check(s.emulator.Close(), IsNil)
+	s.emulator = NewArkeEmulator(ArkeEmulatorOptions{
+		Name:         "vcan-test",
+		ReportPeriod: 100 * time.Millisecond,
+		TimeRatio:    600,
+		Nodes:        NewEmulatedZone(1, false, NewPlantModel(DefaultPlantParameters())),
+		Scenario:     &Scenario{Faults: faults},
+	})
+}
+
+func (s *ArkeEmulatorSuite) TestScenarioDeviceMissing(c *C) {
+	// ten emulated minutes is one second.
+	s.scenarioEmulator(c, Fault{Kind: DeviceMissing, Device: "celaeno", Duration: 10 * time.Minute})
+	c.Assert(s.emulator.Send(arke.MakeHeartBeatRequest(arke.BroadcastClass, 100*time.Millisecond)), IsNil)
+
+	deadline := time.Now().Add(500 * time.Millisecond)
+	for time.Now().Before(deadline) {
+		f, err := s.emulator.Receive()
+		c.Assert(err, IsNil)
+		m, _, err := arke.ParseMessage(&f)
+		c.Assert(err, IsNil)
+		if hb, ok := m.(*arke.HeartBeatData); ok == true {
+			c.Check(hb.Class, Not(Equals), arke.CelaenoClass)
+		}
+		c.Check(m.MessageClassID(), Not(Equals), arke.CelaenoStatusMessage)
+	}
+
+	status := s.receiveUntil(c, arke.CelaenoStatusMessage).(*arke.CelaenoStatus)
+	c.Check(status.WaterLevel, Equals, arke.CelaenoWaterNominal)
+}
+
+func (s *ArkeEmulatorSuite) TestScenarioFans(c *C) {
+	s.scenarioEmulator(c,
+		Fault{Kind: FanStalled, Device: "zeus", Fan: 1},
+		Fault{Kind: FanAging, Device: "celaeno"})
+
+	zeusStatus := s.receiveUntil(c, arke.ZeusStatusMessage).(*arke.ZeusStatus)
+	c.Check(zeusStatus.Fans[0].Status(), Equals, arke.FanOK)
+	c.Check(zeusStatus.Fans[1].Status(), Equals, arke.FanStalled)
+	c.Check(zeusStatus.Fans[1].RPM(), Equals, uint16(0))
+	celaenoStatus := s.receiveUntil(c, arke.CelaenoStatusMessage).(*arke.CelaenoStatus)
+	c.Check(celaenoStatus.Fan.Status(), Equals, arke.FanAging)
+}
+
+func (s *ArkeEmulatorSuite) TestScenarioWaterEmpty(c *C) {
+	s.scenarioEmulator(c, Fault{Kind: WaterEmpty, Duration: 10 * time.Minute})
+	status := s.receiveUntil(c, arke.CelaenoStatusMessage).(*arke.CelaenoStatus)
+	c.Check(status.WaterLevel, Equals, arke.CelaenoWaterCritical)
+	for i := 0; i < 30 && status.WaterLevel != arke.CelaenoWaterNominal; i++ {
+		status = s.receiveUntil(c, arke.CelaenoStatusMessage).(*arke.CelaenoStatus)
+	}
+	c.Check(status.WaterLevel, Equals, arke.CelaenoWaterNominal)
+}
+
+func (s *ArkeEmulatorSuite) TestScenarioSensorNaN(c *C) {
+	s.scenarioEmulator(c, Fault{Kind: SensorNaN})
+	c.Assert(arke.SendMessage(s.emulator, &arke.ZeusSetPoint{Temperature: 26.0}, false, 1), IsNil)
+
+	unreadable := 0
+	var status *arke.ZeusStatus
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) && (unreadable == 0 || status == nil) {
+		f, err := s.emulator.Receive()
+		c.Assert(err, IsNil)
+		m, _, err := arke.ParseMessage(&f)
+		if err != nil {
+			unreadable += 1
+			continue
+		}
+		if st, ok := m.(*arke.ZeusStatus); ok == true && st.Status&arke.ZeusActive != 0 {
+			status = st
+		}
+	}
+	c.Check(unreadable > 0, Equals, true)
+	c.Assert(status, NotNil)
+	c.Check(status.Status, Equals, arke.ZeusActive|arke.ZeusClimateNotControlledWatchDog)
+}
+
+func (s *ArkeEmulatorSuite) TestScenarioWatchdogReset(c *C) {
+	s.scenarioEmulator(c, Fault{Kind: WatchdogReset, Device: "zeus", At: 5 * time.Minute})
+	hb := s.receiveUntil(c, arke.HeartBeatMessage).(*arke.HeartBeatData)
+	c.Check(hb.Class, Equals, arke.ZeusClass)
+	status := s.receiveUntil(c, arke.ZeusStatusMessage).(*arke.ZeusStatus)
+	c.Check(status.Status, Equals, arke.ZeusClimateNotControlledWatchDog)
+	// a watchdog reset is not a reset request.
+	c.Check(s.emulator.Resets(arke.ZeusClass, 1), Equals, 0)
+}
+
+func (s *ArkeEmulatorSuite) TestScenarioBusDown(c *C) {
+	s.scenarioEmulator(c, Fault{Kind: BusDown, At: 5 * time.Minute, Duration: 10 * time.Minute})
+	var err error
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) && err == nil {
+		_, err = s.emulator.Receive()
+	}
+	c.Assert(err, Equals, syscall.ENETDOWN)
+	c.Check(arke.SendMessage(s.emulator, &arke.ZeusSetPoint{Temperature: 26.0}, false, 1), Equals, syscall.ENETDOWN)
+
+	for time.Now().Before(deadline.Add(time.Second)) && err != nil {
+		time.Sleep(50 * time.Millisecond)
+		_, err = s.emulator.Receive()
+	}
+	c.Check(err, IsNil)
+}
@@ -0,0 +1,159 @@
+package main
+
+import (
+	"sort"
+	"time"
+
+	socketcan "github.com/atuleu/golang-socketcan"
+	"github.com/formicidae-tracker/libarke/src-go/arke"
+)
+
+// scannedClasses are the node classes pinged by a bus scan.
+var scannedClasses = []arke.NodeClass{
+	arke.ZeusClass,
+	arke.CelaenoClass,
+	arke.HeliosClass,
+	arke.NotusClass,
+}
+
+// ScannedDevice is a device answering a bus scan.
+type ScannedDevice struct {
+	Class   arke.NodeClass
+	ID      arke.NodeID
+	Version FirmwareVersion
+	// Zone the device is assigned to, empty if unconfigured.
+	Zone string
+	// Conflict is set when several devices answer with the same class
+	// and ID.
+	Conflict bool
+}
+
+// BusScanner pings every node class on an interface and collects the
+// heartbeats.
+type BusScanner struct {
+	// Window is how long answers to a ping are collected.
+	Window time.Duration
+	// Rounds is the number of pings per class. A device is
+	// conflicting if its ID answers more than once to every ping,
+	// which a periodic heartbeat cannot do.
+	Rounds int
+
+	intf socketcan.RawInterface
+}
+
+func NewBusScanner(intf socketcan.RawInterface) *BusScanner {
+	return &BusScanner{
+		Window: 500 * time.Millisecond,
+		Rounds: 2,
+		intf:   intf,
+	}
+}
+
+func (s *BusScanner) receive(heartbeats chan<- *arke.HeartBeatData, quit <-chan struct{}) {
+	defer close(heartbeats)
+	for {
+		f, err := s.intf.Receive()
+		if err != nil {
+			return
+		}
+		m, _, err := arke.ParseMessage(&f)
+		if err != nil || m.MessageClassID() != arke.HeartBeatMessage {
+			continue
+		}
+		select {
+		case heartbeats <- m.(*arke.HeartBeatData):
+		case <-quit:
+			return
+		}
+	}
+}
+
+// Scan returns the devices answering on the bus, sorted by class and
+// ID. The receiving goroutine ends when the interface is closed.
+func (s *BusScanner) Scan() ([]ScannedDevice, error) {
+	heartbeats := make(chan *arke.HeartBeatData, 64)
+	quit := make(chan struct{})
+	defer close(quit)
+	go s.receive(heartbeats, quit)
+
+	found := make(map[DeviceDefinition]*ScannedDevice)
+	// duplicated counts the rounds where a device answered several
+	// times.
+	duplicated := make(map[DeviceDefinition]int)
+	for _, c := range scannedClasses {
+		for i := 0; i < s.Rounds; i++ {
+			answers, err := s.ping(c, heartbeats)
+			if err != nil {
+				return nil, err
+			}
+			for _, h := range answers {
+				d := DeviceDefinition{Class: h.Class, ID: h.ID}
+				if _, ok := found[d]; ok == false {
+					found[d] = &ScannedDevice{Class: h.Class, ID: h.ID}
+				}
+				if v := heartbeatVersion(h); v.Known() == true {
+					found[d].Version = v
+				}
+			}
+			for d, count := range countAnswers(answers) {
+				if count > 1 {
+					duplicated[d]++
+				}
+			}
+		}
+	}
+
+	res := make([]ScannedDevice, 0, len(found))
+	for d, device := range found {
+		device.Conflict = duplicated[d] == s.Rounds
+		res = append(res, *device)
+	}
+	sort.Slice(res, func(i, j int) bool {
+		if res[i].Class != res[j].Class {
+			return res[i].Class < res[j].Class
+		}
+		return res[i].ID < res[j].ID
+	})
+	return res, nil
+}
+
+func (s *BusScanner) ping(c arke.NodeClass, heartbeats <-chan *arke.HeartBeatData) ([]*arke.HeartBeatData, error) {
+	if err := s.intf.Send(arke.MakePing(c)); err != nil {
+		return nil, err
+	}
+	var res []*arke.HeartBeatData
+	timeout := time.After(s.Window)
+	for {
+		select {
+		case <-timeout:
+			return res, nil
+		case h, ok := <-heartbeats:
+			if ok == false {
+				return res, nil
+			}
+			if h.Class == c {
+				res = append(res, h)
+			}
+		}
+	}
+}
+
+func countAnswers(answers []*arke.HeartBeatData) map[DeviceDefinition]int {
+	res := make(map[DeviceDefinition]int)
+	for _, h := range answers {
+		res[DeviceDefinition{Class: h.Class, ID: h.ID}]++
+	}
+	return res
+}
+
+// AssignZones sets the zone of the devices found on ifname, from the
+// zone definitions.
+func AssignZones(devices []ScannedDevice, ifname string, zones map[string]ZoneDefinition) {
+	for i, d := range devices {
+		for name, definition := range zones {
+			if definition.CANInterface == ifname && arke.NodeID(definition.DevicesID) == d.ID {
+				devices[i].Zone = name
+			}
+		}
+	}
+}
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"time"
+
+	"github.com/formicidae-tracker/libarke/src-go/arke"
+	. "gopkg.in/check.v1"
+)
+
+type BusScanSuite struct{}
+
+var _ = Suite(&BusScanSuite{})
+
+func (s *BusScanSuite) TestScan(c *C) {
+	plant := NewPlantModel(DefaultPlantParameters())
+	nodes := NewEmulatedZone(1, false, plant)
+	conflicting := []*EmulatedNode{
+		NewEmulatedNode(arke.CelaenoClass, 3, plant),
+		NewEmulatedNode(arke.CelaenoClass, 3, plant),
+	}
+	conflicting[1].MinorVersion = 2
+	nodes = append(nodes, conflicting...)
+	nodes = append(nodes, NewEmulatedNode(arke.HeliosClass, 4, nil))
+	e := NewArkeEmulator(ArkeEmulatorOptions{Name: "scan", Nodes: nodes})
+	defer e.Close()
+	// a periodic heartbeat is not a conflict.
+	c.Assert(e.Send(arke.MakeHeartBeatRequest(arke.ZeusClass, 10*time.Millisecond)), IsNil)
+
+	scanner := NewBusScanner(e)
+	scanner.Window = 20 * time.Millisecond
+	devices, err := scanner.Scan()
+	c.Assert(err, IsNil)
+	AssignZones(devices, "scan", map[string]ZoneDefinition{
+		"box":   {CANInterface: "scan", DevicesID: 1},
+		"other": {CANInterface: "slcan1", DevicesID: 4},
+	})
+
+	c.Assert(devices, HasLen, 5)
+	version := FirmwareVersion{Major: 1}
+	c.Check(devices[0], DeepEquals, ScannedDevice{Class: arke.CelaenoClass, ID: 1, Version: version, Zone: "box"})
+	c.Check(devices[1].Class, Equals, arke.CelaenoClass)
+	c.Check(devices[1].ID, Equals, arke.NodeID(3))
+	c.Check(devices[1].Conflict, Equals, true)
+	c.Check(devices[1].Zone, Equals, "")
+	c.Check(devices[2], DeepEquals, ScannedDevice{Class: arke.HeliosClass, ID: 1, Version: version, Zone: "box"})
+	c.Check(devices[3], DeepEquals, ScannedDevice{Class: arke.HeliosClass, ID: 4, Version: version})
+	c.Check(devices[4], DeepEquals, ScannedDevice{Class: arke.ZeusClass, ID: 1, Version: version, Zone: "box"})
+}
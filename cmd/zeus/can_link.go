@@ -0,0 +1,96 @@
+package main
+
+import (
+	"fmt"
+	"net"
+	"os/exec"
+	"path"
+	"strings"
+
+	"github.com/formicidae-tracker/olympus/pkg/tm"
+	"github.com/sirupsen/logrus"
+)
+
+// ipLink runs 'ip link' with args.
+func ipLink(args ...string) error {
+	cmd := exec.Command("ip", append([]string{"link"}, args...)...)
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("Could not run 'ip link %s': %s", strings.Join(args, " "), strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+// setLink brings the link of ifname up or down.
+func setLink(logger *logrus.Entry, ifname string, up bool) error {
+	state := "down"
+	if up == true {
+		state = "up"
+	}
+	logger.WithField("interface", ifname).Infof("set interface link %s", state)
+	return ipLink("set", ifname, state)
+}
+
+// CANLink is a native or virtual CAN interface, brought up until it
+// is closed.
+type CANLink struct {
+	ifname string
+	logger *logrus.Entry
+	// created is true for the virtual interfaces we added.
+	created bool
+}
+
+// OpenNativeInterface sets the bitrate of a native CAN interface and
+// brings it up.
+func OpenNativeInterface(ifname string, bitrate int) (*CANLink, error) {
+	l := &CANLink{
+		ifname: ifname,
+		logger: tm.NewLogger(path.Join("can-link", ifname)),
+	}
+	if _, err := net.InterfaceByName(ifname); err != nil {
+		return nil, fmt.Errorf("Could not find interface %s: %w", ifname, err)
+	}
+	// the bitrate cannot be changed while the link is up.
+	if err := setLink(l.logger, ifname, false); err != nil {
+		return nil, err
+	}
+	l.logger.WithField("bitrate", bitrate).Info("setting bitrate")
+	if err := ipLink("set", ifname, "type", "can", "bitrate", fmt.Sprintf("%d", bitrate)); err != nil {
+		return nil, err
+	}
+	if err := setLink(l.logger, ifname, true); err != nil {
+		return nil, err
+	}
+	return l, nil
+}
+
+// OpenVirtualInterface adds the vcan interface ifname if needed, and
+// brings it up.
+func OpenVirtualInterface(ifname string) (*CANLink, error) {
+	l := &CANLink{
+		ifname: ifname,
+		logger: tm.NewLogger(path.Join("can-link", ifname)),
+	}
+	if _, err := net.InterfaceByName(ifname); err != nil {
+		l.logger.Info("adding virtual interface")
+		if err := ipLink("add", "dev", ifname, "type", "vcan"); err != nil {
+			return nil, err
+		}
+		l.created = true
+	}
+	if err := setLink(l.logger, ifname, true); err != nil {
+		l.Close()
+		return nil, err
+	}
+	return l, nil
+}
+
+// Close brings the interface down, and deletes it if it was added by
+// OpenVirtualInterface.
+func (l *CANLink) Close() error {
+	if l.created == true {
+		l.logger.Info("deleting virtual interface")
+		return ipLink("delete", "dev", l.ifname)
+	}
+	return setLink(l.logger, l.ifname, false)
+}
@@ -0,0 +1,308 @@
+package main
+
+import (
+	"bufio"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	socketcan "github.com/atuleu/golang-socketcan"
+	"github.com/formicidae-tracker/zeus/internal/zeus"
+)
+
+// CANLogEntry is a line of a candump log file, i.e. as written by
+// 'candump -l -x':
+//
+//	(1436509052.249713) slcan0 1C1#DEADBEEF R
+//
+// The trailing direction, 'R' for received or 'T' for sent frames,
+// is optional.
+type CANLogEntry struct {
+	Time      time.Time
+	Interface string
+	Frame     socketcan.CanFrame
+	Sent      bool
+}
+
+func formatCANFrame(f socketcan.CanFrame) string {
+	id := fmt.Sprintf("%03X", f.ID)
+	if f.Extended == true {
+		id = fmt.Sprintf("%08X", f.ID)
+	}
+	if f.RTR == true {
+		return id + "#R"
+	}
+	dlc := int(f.Dlc)
+	if dlc > len(f.Data) {
+		dlc = len(f.Data)
+	}
+	return id + "#" + strings.ToUpper(hex.EncodeToString(f.Data[:dlc]))
+}
+
+func (e CANLogEntry) String() string {
+	direction := "R"
+	if e.Sent == true {
+		direction = "T"
+	}
+	return fmt.Sprintf("(%010d.%06d) %s %s %s",
+		e.Time.Unix(), e.Time.Nanosecond()/1000,
+		e.Interface, formatCANFrame(e.Frame), direction)
+}
+
+func parseCANFrame(s string) (socketcan.CanFrame, error) {
+	idStr, dataStr, ok := strings.Cut(s, "#")
+	if ok == false || strings.HasPrefix(dataStr, "#") == true {
+		return socketcan.CanFrame{}, fmt.Errorf("invalid CAN frame '%s'", s)
+	}
+	id, err := strconv.ParseUint(idStr, 16, 32)
+	if err != nil {
+		return socketcan.CanFrame{}, fmt.Errorf("invalid CAN ID '%s': %w", idStr, err)
+	}
+	res := socketcan.CanFrame{
+		ID:       uint32(id),
+		Extended: len(idStr) > 3,
+	}
+	if strings.HasPrefix(dataStr, "R") == true {
+		res.RTR = true
+		return res, nil
+	}
+	res.Data, err = hex.DecodeString(dataStr)
+	if err != nil {
+		return socketcan.CanFrame{}, fmt.Errorf("invalid CAN data '%s': %w", dataStr, err)
+	}
+	if len(res.Data) > 8 {
+		return socketcan.CanFrame{}, fmt.Errorf("invalid CAN data '%s': more than 8 bytes", dataStr)
+	}
+	res.Dlc = uint8(len(res.Data))
+	return res, nil
+}
+
+func parseCANLogEntry(line string) (CANLogEntry, error) {
+	fields := strings.Fields(line)
+	if len(fields) < 3 || len(fields) > 4 {
+		return CANLogEntry{}, fmt.Errorf("invalid number of fields %d", len(fields))
+	}
+	stamp := strings.TrimSuffix(strings.TrimPrefix(fields[0], "("), ")")
+	secStr, usecStr, ok := strings.Cut(stamp, ".")
+	if ok == false {
+		return CANLogEntry{}, fmt.Errorf("invalid timestamp '%s'", fields[0])
+	}
+	sec, err := strconv.ParseInt(secStr, 10, 64)
+	if err != nil {
+		return CANLogEntry{}, fmt.Errorf("invalid timestamp '%s': %w", fields[0], err)
+	}
+	usec, err := strconv.ParseInt(usecStr, 10, 64)
+	if err != nil {
+		return CANLogEntry{}, fmt.Errorf("invalid timestamp '%s': %w", fields[0], err)
+	}
+	f, err := parseCANFrame(fields[2])
+	if err != nil {
+		return CANLogEntry{}, err
+	}
+	res := CANLogEntry{
+		Time:      time.Unix(sec, usec*1000),
+		Interface: fields[1],
+		Frame:     f,
+	}
+	if len(fields) == 4 {
+		switch fields[3] {
+		case "R":
+		case "T":
+			res.Sent = true
+		default:
+			return CANLogEntry{}, fmt.Errorf("invalid direction '%s'", fields[3])
+		}
+	}
+	return res, nil
+}
+
+// ReadCANLog reads all entries of a candump log.
+func ReadCANLog(r io.Reader) ([]CANLogEntry, error) {
+	var res []CANLogEntry
+	scanner := bufio.NewScanner(r)
+	line := 0
+	for scanner.Scan() {
+		line += 1
+		if len(strings.TrimSpace(scanner.Text())) == 0 {
+			continue
+		}
+		e, err := parseCANLogEntry(scanner.Text())
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		res = append(res, e)
+	}
+	return res, scanner.Err()
+}
+
+// ReadCANLogFile reads all entries of a candump log file.
+func ReadCANLogFile(filename string) ([]CANLogEntry, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return ReadCANLog(f)
+}
+
+// recordingInterface tees all frames sent and received through a
+// socketcan.RawInterface into a candump log.
+type recordingInterface struct {
+	intf   socketcan.RawInterface
+	ifname string
+
+	mx  sync.Mutex
+	out io.WriteCloser
+}
+
+// NewRecordingInterface records the traffic of intf in a new candump
+// log in directory.
+func NewRecordingInterface(intf socketcan.RawInterface, ifname, directory string) (socketcan.RawInterface, error) {
+	if err := os.MkdirAll(directory, 0755); err != nil {
+		return nil, err
+	}
+	filename := filepath.Join(directory,
+		fmt.Sprintf("candump-%s-%s.log", ifname, time.Now().Format("2006-01-02_150405")))
+	f, _, err := zeus.CreateFileWithoutOverwrite(filename)
+	if err != nil {
+		return nil, err
+	}
+	return &recordingInterface{intf: intf, ifname: ifname, out: f}, nil
+}
+
+func (i *recordingInterface) record(f socketcan.CanFrame, sent bool) {
+	i.mx.Lock()
+	defer i.mx.Unlock()
+	if i.out == nil {
+		return
+	}
+	fmt.Fprintln(i.out, CANLogEntry{
+		Time:      time.Now(),
+		Interface: i.ifname,
+		Frame:     f,
+		Sent:      sent,
+	})
+}
+
+func (i *recordingInterface) Send(f socketcan.CanFrame) error {
+	if err := i.intf.Send(f); err != nil {
+		return err
+	}
+	i.record(f, true)
+	return nil
+}
+
+func (i *recordingInterface) Receive() (socketcan.CanFrame, error) {
+	f, err := i.intf.Receive()
+	if err == nil {
+		i.record(f, false)
+	}
+	return f, err
+}
+
+func (i *recordingInterface) Close() error {
+	err := i.intf.Close()
+	i.mx.Lock()
+	defer i.mx.Unlock()
+	if i.out == nil {
+		return err
+	}
+	if cerr := i.out.Close(); err == nil {
+		err = cerr
+	}
+	i.out = nil
+	return err
+}
+
+// ReplayInterface is a socketcan.RawInterface receiving the frames of
+// a candump log, with their original timing accelerated by a speed
+// factor. Sent frames are discarded.
+type ReplayInterface struct {
+	entries []CANLogEntry
+	speed   float64
+
+	next      int
+	last      time.Time
+	quit      chan struct{}
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+// NewReplayInterface replays the frames received in entries. speed
+// accelerates the replay, and defaults to 1.0.
+func NewReplayInterface(entries []CANLogEntry, speed float64) *ReplayInterface {
+	if speed <= 0 {
+		speed = 1.0
+	}
+	res := &ReplayInterface{
+		speed: speed,
+		quit:  make(chan struct{}),
+		done:  make(chan struct{}),
+	}
+	for _, e := range entries {
+		if e.Sent == false {
+			res.entries = append(res.entries, e)
+		}
+	}
+	if len(res.entries) == 0 {
+		close(res.done)
+	}
+	return res
+}
+
+// Done is closed once all frames were received.
+func (i *ReplayInterface) Done() <-chan struct{} {
+	return i.done
+}
+
+func (i *ReplayInterface) Send(f socketcan.CanFrame) error {
+	select {
+	case <-i.quit:
+		return syscall.EBADF
+	default:
+		return nil
+	}
+}
+
+func (i *ReplayInterface) wait(d time.Duration) error {
+	if d <= 0 {
+		return nil
+	}
+	select {
+	case <-i.quit:
+		return syscall.EBADF
+	case <-time.After(d):
+		return nil
+	}
+}
+
+func (i *ReplayInterface) Receive() (socketcan.CanFrame, error) {
+	if i.next >= len(i.entries) {
+		<-i.quit
+		return socketcan.CanFrame{}, syscall.EBADF
+	}
+	e := i.entries[i.next]
+	if i.last.IsZero() == false {
+		if err := i.wait(time.Duration(float64(e.Time.Sub(i.last)) / i.speed)); err != nil {
+			return socketcan.CanFrame{}, err
+		}
+	}
+	i.last = e.Time
+	i.next += 1
+	if i.next == len(i.entries) {
+		close(i.done)
+	}
+	return e.Frame, nil
+}
+
+func (i *ReplayInterface) Close() error {
+	i.closeOnce.Do(func() { close(i.quit) })
+	return nil
+}
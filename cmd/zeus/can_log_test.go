@@ -0,0 +1,156 @@
+package main
+
+import (
+	"path/filepath"
+	"strings"
+	"time"
+
+	socketcan "github.com/atuleu/golang-socketcan"
+	"github.com/formicidae-tracker/libarke/src-go/arke"
+	. "gopkg.in/check.v1"
+)
+
+type CANLogSuite struct {
+	dir string
+}
+
+var _ = Suite(&CANLogSuite{})
+
+func (s *CANLogSuite) SetUpTest(c *C) {
+	s.dir = c.MkDir()
+}
+
+func (s *CANLogSuite) TestFormat(c *C) {
+	testdata := []struct {
+		Line  string
+		Entry CANLogEntry
+	}{
+		{
+			Line: "(1436509052.249713) slcan0 1C1#DEADBEEF R",
+			Entry: CANLogEntry{
+				Time:      time.Unix(1436509052, 249713000),
+				Interface: "slcan0",
+				Frame:     socketcan.CanFrame{ID: 0x1c1, Dlc: 4, Data: []byte{0xde, 0xad, 0xbe, 0xef}},
+			},
+		},
+		{
+			Line: "(1436509052.000010) slcan1 7FF#00 T",
+			Entry: CANLogEntry{
+				Time:      time.Unix(1436509052, 10000),
+				Interface: "slcan1",
+				Frame:     socketcan.CanFrame{ID: 0x7ff, Dlc: 1, Data: []byte{0}},
+				Sent:      true,
+			},
+		},
+		{
+			Line: "(0000000012.000000) vcan0 00000123#R R",
+			Entry: CANLogEntry{
+				Time:      time.Unix(12, 0),
+				Interface: "vcan0",
+				Frame:     socketcan.CanFrame{ID: 0x123, Extended: true, RTR: true},
+			},
+		},
+	}
+	for _, d := range testdata {
+		e, err := parseCANLogEntry(d.Line)
+		if c.Check(err, IsNil) == false {
+			continue
+		}
+		c.Check(e.Time.Equal(d.Entry.Time), Equals, true)
+		e.Time = d.Entry.Time
+		c.Check(e, DeepEquals, d.Entry)
+		c.Check(d.Entry.String(), Equals, d.Line)
+	}
+
+	// candump logs without direction are received frames.
+	e, err := parseCANLogEntry("(1436509052.249713) can0 123#")
+	c.Check(err, IsNil)
+	c.Check(e.Sent, Equals, false)
+	c.Check(e.Frame.Dlc, Equals, uint8(0))
+
+	for _, line := range []string{
+		"(1436509052.249713) can0",
+		"(1436509052) can0 123#00",
+		"(1436509052.249713) can0 123##100",
+		"(1436509052.249713) can0 XYZ#00",
+		"(1436509052.249713) can0 123#0",
+		"(1436509052.249713) can0 123#000102030405060708",
+		"(1436509052.249713) can0 123#00 X",
+	} {
+		_, err := parseCANLogEntry(line)
+		c.Check(err, NotNil, Commentf("line: %s", line))
+	}
+
+	_, err = ReadCANLog(strings.NewReader("(1.000000) can0 123#00\n\n(2.000000) can0 foo\n"))
+	c.Check(err, ErrorMatches, "line 3: .*")
+}
+
+func (s *CANLogSuite) TestRecordAndReplay(c *C) {
+	stub := NewStubRawInterface()
+	intf, err := NewRecordingInterface(stub, "slcan0", s.dir)
+	c.Assert(err, IsNil)
+
+	go func() {
+		stub.enqueue(&arke.ZeusReport{Humidity: 50.0, Temperature: [4]float32{25, 0, 0, 0}}, 1)
+		time.Sleep(200 * time.Millisecond)
+		stub.enqueue(&arke.ZeusStatus{Status: arke.ZeusActive}, 1)
+	}()
+	sent := arke.MakeResetRequest(arke.ZeusClass, 1)
+	c.Assert(intf.Send(sent), IsNil)
+	received := []socketcan.CanFrame{}
+	for i := 0; i < 2; i++ {
+		f, err := intf.Receive()
+		c.Assert(err, IsNil)
+		received = append(received, f)
+	}
+	c.Assert(intf.Close(), IsNil)
+
+	files, err := filepath.Glob(filepath.Join(s.dir, "candump-slcan0-*.log"))
+	c.Assert(err, IsNil)
+	c.Assert(files, HasLen, 1)
+	entries, err := ReadCANLogFile(files[0])
+	c.Assert(err, IsNil)
+	c.Assert(entries, HasLen, 3)
+	c.Check(entries[0].Sent, Equals, true)
+	c.Check(entries[0].Frame.ID, Equals, sent.ID)
+	c.Check(entries[1].Sent, Equals, false)
+	c.Check(entries[2].Time.Sub(entries[1].Time) >= 200*time.Millisecond, Equals, true)
+
+	replay := NewReplayInterface(entries, 4.0)
+	defer replay.Close()
+	c.Check(replay.Send(sent), IsNil)
+	start := time.Now()
+	for _, expected := range received {
+		f, err := replay.Receive()
+		c.Assert(err, IsNil)
+		c.Check(f.ID, Equals, expected.ID)
+		c.Check(f.Data, DeepEquals, expected.Data[:expected.Dlc])
+	}
+	elapsed := time.Since(start)
+	c.Check(elapsed >= 50*time.Millisecond, Equals, true, Commentf("elapsed: %s", elapsed))
+	c.Check(elapsed < 150*time.Millisecond, Equals, true, Commentf("elapsed: %s", elapsed))
+	select {
+	case <-replay.Done():
+	default:
+		c.Errorf("replay should be done")
+	}
+
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		replay.Close()
+	}()
+	_, err = replay.Receive()
+	c.Check(err, NotNil)
+	c.Check(replay.Send(sent), NotNil)
+}
+
+func (s *CANLogSuite) TestReplayUnknownInterface(c *C) {
+	var replays []*ReplayInterface
+	factory := replayFactory([]CANLogEntry{{Interface: "slcan0"}}, 1.0, &replays)
+	_, err := factory("slcan1")
+	c.Check(err, ErrorMatches, "no frames recorded on 'slcan1'")
+	intf, err := factory("slcan0")
+	c.Check(err, IsNil)
+	c.Check(replays, HasLen, 1)
+	c.Check(intf.Close(), IsNil)
+}
@@ -2,6 +2,8 @@ package main
 
 import (
 	"fmt"
+	"math"
+	"sync"
 	"time"
 
 	"github.com/formicidae-tracker/libarke/src-go/arke"
@@ -14,6 +16,8 @@ type capability interface {
 	Requirements() []arke.NodeClass
 	SetDevices(devices map[arke.NodeClass]*Device)
 	Action(s zeus.State) error
+	// Idle leaves the devices in the safe state of the zone.
+	Idle(safe SafeStateDefinition) error
 	Callbacks() map[arke.MessageClass]callback
 	Close() error
 }
@@ -44,20 +48,74 @@ func (d *resetableDevice) MayReset(window time.Duration) error {
 	return d.device.SendHeartbeatRequest()
 }
 
+// climateChannels are the channels controlled by a
+// ClimateControllable.
+type climateChannels struct {
+	Temperature, Humidity, Wind bool
+}
+
 type ClimateControllable struct {
 	withNotus    bool
 	withCelaeno  bool
+	channels     climateChannels
+	notusShare   float64
 	lastSetPoint *arke.ZeusSetPoint
 	celaeno      *resetableDevice
 	zeus         *resetableDevice
 	notus        *resetableDevice
+
+	// protects the set points from the callbacks.
+	mx sync.Mutex
+	// followTemperature is set when the temperature set point
+	// follows the measured temperature, instead of the targets.
+	followTemperature bool
+	measured          zeus.Temperature
+	pending           *zeus.State
+	// followed is the last target whose humidity is converted at
+	// the measured temperature.
+	followed *zeus.State
 }
 
-func NewClimateControllable(forceHumidity, useNotus bool) *ClimateControllable {
+// followedTemperatureStep is the change of the measured temperature
+// that updates the followed temperature set point.
+const followedTemperatureStep = 0.1
+
+// NewClimateControllable controls the channels of the climate. If
+// followTemperature is set, the temperature set point follows the
+// measured one, so the Zeus does not drive it. An uncontrolled
+// humidity is not raised. If useNotus is set, notusShare % of the wind
+// is driven by the Notus.
+func NewClimateControllable(channels climateChannels, followTemperature, useNotus bool, notusShare float64) *ClimateControllable {
 	return &ClimateControllable{
-		withCelaeno: forceHumidity,
-		withNotus:   useNotus,
+		withCelaeno:       channels.Humidity,
+		withNotus:         useNotus,
+		channels:          channels,
+		followTemperature: followTemperature,
+		notusShare:        notusShare,
+		measured:          zeus.UndefinedTemperature,
+	}
+}
+
+// splitWind returns the Zeus and Notus fan powers for wind.
+func (c *ClimateControllable) splitWind(wind zeus.Wind) (zeusPower, notusPower uint8) {
+	power := zeus.Clamp(wind) / 100.0 * 255
+	if c.withNotus == false {
+		return uint8(power), 0
 	}
+	notus := power * c.notusShare / 100.0
+	return uint8(power - notus), uint8(notus)
+}
+
+func (c *ClimateControllable) sendWind(wind zeus.Wind) error {
+	zeusPower, notusPower := c.splitWind(wind)
+	c.lastSetPoint.Wind = zeusPower
+	if err := c.zeus.SendMessage(c.lastSetPoint); err != nil {
+		return err
+	}
+	if c.notus == nil {
+		return nil
+	}
+	return c.notus.SendMessage(&arke.NotusSetPoint{Power: notusPower})
 }
 
 func (c *ClimateControllable) Requirements() []arke.NodeClass {
@@ -106,24 +164,115 @@ func (c *ClimateControllable) Close() error {
 var zeusFanNames = []string{"Zeus Wind", "Zeus Extraction Right", "Zeus Extraction Left"}
 
 func (c *ClimateControllable) Action(s zeus.State) error {
-	if c.withCelaeno == true {
-		c.lastSetPoint = &arke.ZeusSetPoint{
-			Temperature: float32(zeus.Clamp(s.Temperature)),
-			Humidity:    float32(zeus.Clamp(s.Humidity)),
-			Wind:        uint8(zeus.Clamp(s.Wind) / 100.0 * 255),
+	c.mx.Lock()
+	defer c.mx.Unlock()
+	return c.action(s)
+}
+
+func (c *ClimateControllable) action(s zeus.State) error {
+	temperature := s.Temperature
+	if c.followTemperature == true {
+		if zeus.IsUndefined(c.measured) == true {
+			// sent once the temperature is measured.
+			c.pending = &s
+			return nil
 		}
-	} else {
-		c.lastSetPoint = &arke.ZeusSetPoint{
-			Temperature: float32(zeus.Clamp(s.Temperature)),
-			Humidity:    float32(s.Humidity.MinValue()),
-			Wind:        uint8(zeus.Clamp(s.Wind) / 100.0 * 255),
+		temperature = c.measured
+		c.followed = nil
+		if s.VPD != nil || s.DewPoint != nil {
+			c.followed = &s
 		}
 	}
+	setPoint := zeus.Clamp(temperature)
+	// a vpd or dew-point target follows the sent temperature.
+	target := s.HumidityAt(zeus.Temperature(setPoint))
+	humidity := target.MinValue()
+	if c.channels.Humidity == true {
+		humidity = zeus.Clamp(target)
+	}
+	wind := zeus.UndefinedWind
+	if c.channels.Wind == true {
+		wind = s.Wind
+	}
+	c.lastSetPoint = &arke.ZeusSetPoint{
+		Temperature: float32(setPoint),
+		Humidity:    float32(humidity),
+	}
+	return c.sendWind(wind)
+}
+
+// followMeasured sets the temperature set point to the measured
+// temperature when it is not controlled.
+func (c *ClimateControllable) followMeasured(alarms chan<- zeus.Alarm, mm *StampedMessage) error {
+	report, ok := mm.M.(*arke.ZeusReport)
+	if ok == false {
+		return fmt.Errorf("Invalid message type %v", mm.M.MessageClassID())
+	}
+	c.mx.Lock()
+	defer c.mx.Unlock()
+	measured := zeus.Temperature(report.Temperature[0])
+	if math.IsNaN(measured.Value()) == true {
+		return nil
+	}
+	if zeus.IsUndefined(c.measured) == false &&
+		math.Abs(measured.Value()-c.measured.Value()) < followedTemperatureStep {
+		return nil
+	}
+	c.measured = measured
+	if c.pending != nil {
+		s := *c.pending
+		c.pending = nil
+		return c.action(s)
+	}
+	if c.followed != nil {
+		return c.action(*c.followed)
+	}
+	if c.lastSetPoint == nil {
+		return nil
+	}
+	c.lastSetPoint.Temperature = float32(zeus.Clamp(measured))
 	return c.zeus.SendMessage(c.lastSetPoint)
 }
 
+// Idle keeps regulating the last target with the safe wind, or resets
+// the devices if the temperature is off.
+func (c *ClimateControllable) Idle(safe SafeStateDefinition) error {
+	c.mx.Lock()
+	defer c.mx.Unlock()
+	if safe.Temperature == SafeTemperatureOff {
+		for _, d := range []*resetableDevice{c.celaeno, c.notus} {
+			if d == nil {
+				continue
+			}
+			if err := d.device.SendResetRequest(); err != nil {
+				return err
+			}
+		}
+		return c.zeus.device.SendResetRequest()
+	}
+	if c.lastSetPoint == nil {
+		return nil
+	}
+	setPoint := *c.lastSetPoint
+	c.lastSetPoint = &setPoint
+	return c.sendWind(zeus.Wind(safe.Wind))
+}
+
+// resendSetPoint sends the last set point to the Zeus, if any.
+func (c *ClimateControllable) resendSetPoint() (bool, error) {
+	c.mx.Lock()
+	defer c.mx.Unlock()
+	if c.lastSetPoint == nil {
+		return false, nil
+	}
+	return true, c.zeus.SendMessage(c.lastSetPoint)
+}
+
 func (c *ClimateControllable) Callbacks() map[arke.MessageClass]callback {
 	res := map[arke.MessageClass]callback{}
+	if c.followTemperature == true {
+		res[arke.ZeusReportMessage] = c.followMeasured
+	}
 	if c.withCelaeno == true {
 		res[arke.CelaenoStatusMessage] = func(alarms chan<- zeus.Alarm, mm *StampedMessage) error {
 			m, ok := mm.M.(*arke.CelaenoStatus)
@@ -157,8 +306,8 @@ func (c *ClimateControllable) Callbacks() map[arke.MessageClass]callback {
 			if m.Status&arke.ZeusActive != 0 {
 				alarms <- zeus.SensorReadoutIssue
 				c.zeus.MayReset(FanResetWindow)
-			} else if c.lastSetPoint != nil {
-				if err := c.zeus.SendMessage(c.lastSetPoint); err != nil {
+			} else if sent, err := c.resendSetPoint(); sent == true {
+				if err != nil {
 					return err
 				}
 			} else {
@@ -167,7 +316,10 @@ func (c *ClimateControllable) Callbacks() map[arke.MessageClass]callback {
 		}
 
 		if m.Status&arke.ZeusHumidityUnreachable != 0 {
-			return c.celaeno.MayReset(FanResetWindow)
+			// a monitored humidity has no Celaeno to reset.
+			if c.celaeno != nil {
+				return c.celaeno.MayReset(FanResetWindow)
+			}
 		} else {
 			alarms <- zeus.HumidityUnreachable
 		}
@@ -212,6 +364,13 @@ func (c *LightControllable) Action(s zeus.State) error {
 	})
 }
 
+func (c *LightControllable) Idle(safe SafeStateDefinition) error {
+	return c.Action(zeus.State{
+		VisibleLight: zeus.Light(safe.VisibleLight),
+		UVLight:      zeus.Light(safe.UVLight),
+	})
+}
+
 func (c *LightControllable) Close() error {
 	return nil
 }
@@ -257,6 +416,8 @@ func (r *ClimateRecordable) SetDevices(map[arke.NodeClass]*Device) {}
 
 func (r *ClimateRecordable) Action(s zeus.State) error { return nil }
 
+func (r *ClimateRecordable) Idle(SafeStateDefinition) error { return nil }
+
 func checkBound(v, min, max zeus.BoundedUnit) bool {
 	if zeus.IsUndefined(min) == false && v.Value() < min.Value() {
 		return false
@@ -311,11 +472,24 @@ func (r *ClimateRecordable) Callbacks() map[arke.MessageClass]callback {
 func ComputeClimateRequirements(climate zeus.ZoneClimate, definition ZoneDefinition, reporters []ClimateReporter) []capability {
 	res := []capability{}
 
+	minT, maxT := climate.MinimalTemperature, climate.MaximalTemperature
+	if definition.Channels.TemperatureMode() == IgnoredChannel {
+		minT, maxT = zeus.UndefinedTemperature, zeus.UndefinedTemperature
+	}
+	minH, maxH := climate.MinimalHumidity, climate.MaximalHumidity
+	if definition.Channels.HumidityMode() == IgnoredChannel {
+		minH, maxH = zeus.UndefinedHumidity, zeus.UndefinedHumidity
+	}
+
 	needClimateReport := len(reporters) > 0
-	if zeus.IsUndefined(climate.MinimalTemperature) == false || zeus.IsUndefined(climate.MaximalTemperature) == false {
+	if zeus.IsUndefined(minT) == false || zeus.IsUndefined(maxT) == false {
+		needClimateReport = true
+	}
+	if zeus.IsUndefined(minH) == false || zeus.IsUndefined(maxH) == false {
 		needClimateReport = true
 	}
-	if zeus.IsUndefined(climate.MinimalHumidity) == false || zeus.IsUndefined(climate.MaximalHumidity) == false {
+	if definition.Channels.TemperatureMode() == MonitoredChannel ||
+		definition.Channels.HumidityMode() == MonitoredChannel {
 		needClimateReport = true
 	}
 
@@ -325,36 +499,40 @@ func ComputeClimateRequirements(climate zeus.ZoneClimate, definition ZoneDefinit
 			chans = append(chans, n.ReportChannel())
 		}
 
-		res = append(res, NewClimateRecordableCapability(climate.MinimalTemperature,
-			climate.MaximalTemperature,
-			climate.MinimalHumidity,
-			climate.MaximalHumidity,
+		res = append(res, NewClimateRecordableCapability(minT,
+			maxT,
+			minH,
+			maxH,
 			definition.TemperatureAux,
 			chans))
 	}
 
+	channels := definition.Channels
 	controlLight := false
-	controlTemperature := false
-	controlHumidity := false
-	controlWind := false
+	controlled := climateChannels{}
 
 	for _, s := range climate.States {
-		if zeus.IsUndefined(s.Humidity) == false {
-			controlHumidity = true
+		if s.HasHumidityTarget() && channels.HumidityMode() == ControlledChannel {
+			controlled.Humidity = true
 		}
-		if zeus.IsUndefined(s.Temperature) == false {
-			controlTemperature = true
+		if zeus.IsUndefined(s.Temperature) == false && channels.TemperatureMode() == ControlledChannel {
+			controlled.Temperature = true
 		}
-		if zeus.IsUndefined(s.Wind) == false {
-			controlWind = true
+		if zeus.IsUndefined(s.Wind) == false && channels.WindMode() == ControlledChannel {
+			controlled.Wind = true
 		}
-		if zeus.IsUndefined(s.VisibleLight) == false || zeus.IsUndefined(s.UVLight) == false {
+		if (zeus.IsUndefined(s.VisibleLight) == false || zeus.IsUndefined(s.UVLight) == false) &&
+			channels.LightMode() == ControlledChannel {
 			controlLight = true
 		}
 	}
 
-	if controlTemperature == true || controlWind == true {
-		res = append(res, NewClimateControllable(controlHumidity, definition.HasNotusDevice))
+	if controlled != (climateChannels{}) {
+		// only a temperature configured as not controlled follows
+		// the measure, seasons without temperature targets keep
+		// the lowest set point.
+		follow := channels.TemperatureMode() != ControlledChannel
+		res = append(res, NewClimateControllable(controlled, follow, definition.HasNotusDevice, definition.notusWindShare()))
 	}
 
 	if controlLight == true {
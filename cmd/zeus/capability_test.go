@@ -0,0 +1,207 @@
+package main
+
+import (
+	"github.com/formicidae-tracker/libarke/src-go/arke"
+	"github.com/formicidae-tracker/zeus/internal/zeus"
+	. "gopkg.in/check.v1"
+)
+
+type CapabilitySuite struct{}
+
+var _ = Suite(&CapabilitySuite{})
+
+func requirements(capabilities []capability) [][]arke.NodeClass {
+	res := make([][]arke.NodeClass, 0, len(capabilities))
+	for _, c := range capabilities {
+		res = append(res, c.Requirements())
+	}
+	return res
+}
+
+func (s *CapabilitySuite) TestChannelModes(c *C) {
+	climate := zeus.ZoneClimate{
+		MinimalTemperature: 20,
+		MaximalTemperature: zeus.UndefinedTemperature,
+		MinimalHumidity:    zeus.UndefinedHumidity,
+		MaximalHumidity:    zeus.UndefinedHumidity,
+		States: []zeus.State{
+			{
+				Name:         "day",
+				Temperature:  26,
+				Humidity:     60,
+				Wind:         100,
+				VisibleLight: 100,
+				UVLight:      zeus.UndefinedLight,
+			},
+		},
+	}
+
+	testdata := []struct {
+		Channels     ChannelsDefinition
+		Requirements [][]arke.NodeClass
+		Controlled   climateChannels
+	}{
+		{
+			Channels: ChannelsDefinition{},
+			Requirements: [][]arke.NodeClass{
+				{arke.ZeusClass},
+				{arke.ZeusClass, arke.CelaenoClass},
+				{arke.HeliosClass},
+			},
+			Controlled: climateChannels{Temperature: true, Humidity: true, Wind: true},
+		},
+		{
+			Channels: ChannelsDefinition{
+				Temperature: MonitoredChannel,
+				Wind:        IgnoredChannel,
+				Light:       IgnoredChannel,
+			},
+			Requirements: [][]arke.NodeClass{
+				{arke.ZeusClass},
+				{arke.ZeusClass, arke.CelaenoClass},
+			},
+			Controlled: climateChannels{Humidity: true},
+		},
+		{
+			Channels: ChannelsDefinition{
+				Humidity: MonitoredChannel,
+				Light:    IgnoredChannel,
+			},
+			Requirements: [][]arke.NodeClass{
+				{arke.ZeusClass},
+				{arke.ZeusClass},
+			},
+			Controlled: climateChannels{Temperature: true, Wind: true},
+		},
+		{
+			Channels: ChannelsDefinition{
+				Temperature: IgnoredChannel,
+				Humidity:    IgnoredChannel,
+				Wind:        IgnoredChannel,
+			},
+			Requirements: [][]arke.NodeClass{
+				{arke.HeliosClass},
+			},
+		},
+	}
+
+	for _, d := range testdata {
+		capabilities := ComputeClimateRequirements(climate, ZoneDefinition{Channels: d.Channels}, nil)
+		c.Check(requirements(capabilities), DeepEquals, d.Requirements, Commentf("channels: %+v", d.Channels))
+		for _, cap := range capabilities {
+			if controllable, ok := cap.(*ClimateControllable); ok == true {
+				c.Check(controllable.channels, Equals, d.Controlled)
+			}
+		}
+	}
+}
+
+func (s *CapabilitySuite) TestMonitoredTemperatureFollowsMeasure(c *C) {
+	controllable := NewClimateControllable(climateChannels{Humidity: true}, true, false, 0)
+	controllable.SetDevices(map[arke.NodeClass]*Device{
+		arke.ZeusClass:    &Device{Class: arke.ZeusClass, ID: 1, intf: NewStubRawInterface()},
+		arke.CelaenoClass: &Device{Class: arke.CelaenoClass, ID: 1, intf: NewStubRawInterface()},
+	})
+	report := func(t float32) {
+		c.Check(controllable.followMeasured(nil, &StampedMessage{
+			M: &arke.ZeusReport{Humidity: 50, Temperature: [4]float32{t, 0, 0, 0}},
+		}), IsNil)
+	}
+
+	c.Check(controllable.Action(zeus.State{Temperature: 30, Humidity: 60}), IsNil)
+	c.Check(controllable.lastSetPoint, IsNil)
+
+	for _, d := range []struct {
+		Measured, SetPoint float32
+	}{
+		{23.0, 23.0},
+		{23.05, 23.0},
+		{24.5, 24.5},
+		{21.0, 21.0},
+	} {
+		report(d.Measured)
+		c.Assert(controllable.lastSetPoint, NotNil)
+		c.Check(controllable.lastSetPoint.Temperature, Equals, d.SetPoint)
+		c.Check(controllable.lastSetPoint.Humidity, Equals, float32(60))
+	}
+}
+
+func (s *CapabilitySuite) TestVPDFollowsMeasuredTemperature(c *C) {
+	controllable := NewClimateControllable(climateChannels{Humidity: true}, true, false, 0)
+	controllable.SetDevices(map[arke.NodeClass]*Device{
+		arke.ZeusClass:    &Device{Class: arke.ZeusClass, ID: 1, intf: NewStubRawInterface()},
+		arke.CelaenoClass: &Device{Class: arke.CelaenoClass, ID: 1, intf: NewStubRawInterface()},
+	})
+	vpd := 1.0
+	c.Check(controllable.Action(zeus.State{
+		Temperature: zeus.UndefinedTemperature,
+		Humidity:    zeus.UndefinedHumidity,
+		VPD:         &vpd,
+	}), IsNil)
+
+	for _, measured := range []float32{20.0, 25.0} {
+		c.Check(controllable.followMeasured(nil, &StampedMessage{
+			M: &arke.ZeusReport{Humidity: 50, Temperature: [4]float32{measured, 0, 0, 0}},
+		}), IsNil)
+		expected, err := zeus.HumidityFromVaporPressureDeficit(zeus.Temperature(measured), vpd)
+		c.Assert(err, IsNil)
+		c.Assert(controllable.lastSetPoint, NotNil)
+		c.Check(controllable.lastSetPoint.Temperature, Equals, measured)
+		c.Check(controllable.lastSetPoint.Humidity, Equals, float32(expected))
+	}
+}
+
+func (s *CapabilitySuite) TestOnlyConfiguredTemperatureFollowsMeasure(c *C) {
+	climate := zeus.ZoneClimate{
+		MinimalTemperature: zeus.UndefinedTemperature,
+		MaximalTemperature: zeus.UndefinedTemperature,
+		MinimalHumidity:    zeus.UndefinedHumidity,
+		MaximalHumidity:    zeus.UndefinedHumidity,
+		States: []zeus.State{
+			{
+				Name:         "windy",
+				Temperature:  zeus.UndefinedTemperature,
+				Humidity:     zeus.UndefinedHumidity,
+				Wind:         50,
+				VisibleLight: zeus.UndefinedLight,
+				UVLight:      zeus.UndefinedLight,
+			},
+		},
+	}
+	for _, d := range []struct {
+		Mode   ChannelMode
+		Follow bool
+	}{
+		{"", false},
+		{ControlledChannel, false},
+		{MonitoredChannel, true},
+		{IgnoredChannel, true},
+	} {
+		definition := ZoneDefinition{Channels: ChannelsDefinition{Temperature: d.Mode}}
+		capabilities := ComputeClimateRequirements(climate, definition, nil)
+		found := false
+		for _, cap := range capabilities {
+			controllable, ok := cap.(*ClimateControllable)
+			if ok == false {
+				continue
+			}
+			found = true
+			c.Check(controllable.followTemperature, Equals, d.Follow, Commentf("mode: '%s'", d.Mode))
+			_, ok = controllable.Callbacks()[arke.ZeusReportMessage]
+			c.Check(ok, Equals, d.Follow, Commentf("mode: '%s'", d.Mode))
+		}
+		c.Check(found, Equals, true, Commentf("mode: '%s'", d.Mode))
+	}
+}
+
+func (s *CapabilitySuite) TestHumidityUnreachableWithoutCelaeno(c *C) {
+	controllable := NewClimateControllable(climateChannels{Temperature: true}, false, false, 0)
+	controllable.SetDevices(map[arke.NodeClass]*Device{
+		arke.ZeusClass: &Device{Class: arke.ZeusClass, ID: 1, intf: NewStubRawInterface()},
+	})
+	alarms := make(chan zeus.Alarm, 10)
+	err := controllable.Callbacks()[arke.ZeusStatusMessage](alarms, &StampedMessage{
+		M: &arke.ZeusStatus{Status: arke.ZeusActive | arke.ZeusHumidityUnreachable},
+	})
+	c.Check(err, IsNil)
+}
@@ -1,12 +1,8 @@
 package main
 
 import (
-	"bufio"
 	"fmt"
-	"io"
 	"os"
-	"strconv"
-	"strings"
 	"time"
 
 	"github.com/formicidae-tracker/zeus/internal/zeus"
@@ -18,145 +14,166 @@ type ClimateReporter interface {
 }
 
 type fileClimateReporter struct {
-	File   *os.File
-	NumAux int
-	Format string
-	Start  time.Time
-	Chan   chan zeus.ClimateReport
+	File    *rotatingFile
+	NumAux  int
+	Start   time.Time
+	Chan    chan zeus.ClimateReport
+	Targets chan zeus.ClimateTarget
+	encoder zeus.ClimateLogEncoder
 }
 
 func (n *fileClimateReporter) ReportChannel() chan<- zeus.ClimateReport {
 	return n.Chan
 }
 
+func (n *fileClimateReporter) TargetChannel() chan<- zeus.ClimateTarget {
+	return n.Targets
+}
+
 func (n *fileClimateReporter) Report(ready chan<- struct{}) {
 	close(ready)
-	asInterface := make([]interface{}, n.NumAux+3)
-	for cr := range n.Chan {
-		if len(cr.Temperatures) != n.NumAux+1 {
-			continue
-		}
-		asInterface[0] = cr.Time.Sub(n.Start).Nanoseconds() / 1e6
-		asInterface[1] = cr.Humidity
-		for i, t := range cr.Temperatures {
-			asInterface[i+2] = t
+	target := zeus.UndefinedState()
+	reports, targets := n.Chan, n.Targets
+	// targets are received until closed, not to block their sender
+	// once the reports are closed.
+	for reports != nil || targets != nil {
+		select {
+		case t, ok := <-targets:
+			if ok == false {
+				targets = nil
+			} else {
+				target = t.Current
+			}
+		case cr, ok := <-reports:
+			if ok == false {
+				reports = nil
+				continue
+			}
+			if len(cr.Temperatures) != n.NumAux+1 {
+				continue
+			}
+			// targets sent before the report applies to it.
+			for drained := false; drained == false; {
+				select {
+				case t, ok := <-targets:
+					if ok == false {
+						targets = nil
+						drained = true
+					} else {
+						target = t.Current
+					}
+				default:
+					drained = true
+				}
+			}
+			n.encoder.Encode(n.File, cr, target)
 		}
-
-		fmt.Fprintf(n.File,
-			n.Format,
-			asInterface...)
 	}
 	n.File.Close()
 }
 
-func NewFileClimateReporter(filename string, numAux int) (ClimateReporter, string, error) {
-	res := &fileClimateReporter{
-		Chan:   make(chan zeus.ClimateReport, 10),
-		Start:  time.Now(),
-		NumAux: numAux,
+// resumeClimateLog opens filename for appending if it is a climate
+// log in the format and with the aux count of header, and returns its
+// start time.
+func resumeClimateLog(filename string, header zeus.ClimateLogHeader) (*os.File, time.Time, bool) {
+	existing, err := zeus.ReadClimateLogHeader(filename)
+	if err != nil || existing.Format != header.Format || existing.AuxCount != header.AuxCount {
+		return nil, time.Time{}, false
 	}
-
-	var err error
-	var fname string
-	res.File, fname, err = zeus.CreateFileWithoutOverwrite(filename)
+	file, err := os.OpenFile(filename, os.O_WRONLY|os.O_APPEND, 0644)
 	if err != nil {
-		return nil, "", err
-	}
-
-	res.Format = "%d %.2f %.2f" + strings.Repeat(" %.2f", numAux) + "\n"
-	header := "# Time (ms) Relative Humidity (%) Temperature (°C)"
-	for i := 0; i < numAux; i++ {
-		header += fmt.Sprintf(" Aux %d (°C)", i+1)
+		return nil, time.Time{}, false
 	}
-
-	fmt.Fprintf(res.File, "# Starting date %s\n%s\n", res.Start.Format(time.RFC3339Nano), header)
-
-	return res, fname, nil
+	return file, existing.Start, true
 }
 
-func readStartDate(r *bufio.Reader) (time.Time, error) {
-	l, err := r.ReadString('\n')
-	if err != nil {
-		return time.Time{}, err
+// openClimateLog creates a climate log described by header, whose
+// start is filled in. If resume is true and filename is a climate log
+// with the same format and aux count, it is appended to instead.
+func openClimateLog(filename string, header zeus.ClimateLogHeader, rotation LogRotationDefinition, resume bool) (*fileClimateReporter, string, error) {
+	if header.AuxCount < len(header.AuxNames) {
+		return nil, "", fmt.Errorf("too many aux names (%d) for %d aux sensors", len(header.AuxNames), header.AuxCount)
+	}
+	names := make([]string, header.AuxCount)
+	for i := range names {
+		names[i] = fmt.Sprintf("Aux %d", i+1)
+		if i < len(header.AuxNames) && len(header.AuxNames[i]) > 0 {
+			names[i] = header.AuxNames[i]
+		}
 	}
+	header.AuxNames = names
 
-	l = strings.TrimPrefix(l, "# Starting date")
-	l = strings.TrimSpace(l)
-	return time.Parse(time.RFC3339Nano, l)
-}
-
-func readNumAux(r *bufio.Reader) (int, error) {
-	l, err := r.ReadString('\n')
-	if err != nil {
-		return 0, err
+	var newEncoder func(header zeus.ClimateLogHeader) zeus.ClimateLogEncoder
+	switch header.Format {
+	case zeus.TextClimateLog:
+		newEncoder = func(header zeus.ClimateLogHeader) zeus.ClimateLogEncoder {
+			return zeus.NewTextClimateLogEncoder(header.Start, header.AuxCount)
+		}
+	case zeus.CSVClimateLog:
+		newEncoder = zeus.NewCSVClimateLogEncoder
+	case zeus.JSONLClimateLog:
+		newEncoder = zeus.NewJSONLClimateLogEncoder
+	default:
+		return nil, "", fmt.Errorf("invalid climate log format '%s'", header.Format)
 	}
-	res := strings.Count(l, "(°C)") - 1
-	if res < 0 {
-		return 0, fmt.Errorf("invalid header '%s'", strings.TrimSpace(l))
+
+	res := &fileClimateReporter{
+		Chan:    make(chan zeus.ClimateReport, 10),
+		Targets: make(chan zeus.ClimateTarget, 1),
+		Start:   time.Now(),
+		NumAux:  header.AuxCount,
 	}
-	return res, nil
-}
 
-func readClimateReport(r *bufio.Reader, start time.Time, numAux int) (zeus.ClimateReport, error) {
-	res := zeus.ClimateReport{}
-	l, err := r.ReadString('\n')
-	if err != nil {
-		return res, err
+	var file *os.File
+	fname := filename
+	appending := false
+	if resume == true {
+		file, res.Start, appending = resumeClimateLog(filename, header)
 	}
-	l = strings.TrimSpace(l)
-	valuesStr := strings.Split(l, " ")
-	if len(valuesStr) < 3+numAux {
-		return res, fmt.Errorf("invalid line '%s': too few values", l)
+	if appending == false {
+		var err error
+		file, fname, err = zeus.CreateFileWithoutOverwrite(filename)
+		if err != nil {
+			return nil, "", err
+		}
 	}
-	ms, err := strconv.ParseInt(valuesStr[0], 10, 64)
-	if err != nil {
-		return res, fmt.Errorf("invalid timestamp '%s': %s", valuesStr[0], err)
+	header.Start = res.Start
+	res.encoder = newEncoder(header)
+
+	var err error
+	if appending == true {
+		res.File, err = AppendRotatingFile(file, res.encoder.Header(), rotation)
+	} else {
+		res.File, err = NewRotatingFile(file, res.encoder.Header(), rotation)
 	}
-	res.Time = start.Add(time.Duration(ms) * time.Millisecond)
-	h, err := strconv.ParseFloat(valuesStr[1], 64)
 	if err != nil {
-		return res, fmt.Errorf("invalid humidity '%s': %s", valuesStr[1], err)
-	}
-	res.Humidity = zeus.Humidity(h)
-	res.Temperatures = make([]zeus.Temperature, numAux+1)
-	for i, tStr := range valuesStr[2:(numAux + 3)] {
-		t, err := strconv.ParseFloat(tStr, 64)
-		if err != nil {
-			return res, fmt.Errorf("invalid temperature '%s': %s", tStr, err)
-		}
-		res.Temperatures[i] = zeus.Temperature(t)
+		file.Close()
+		return nil, "", err
 	}
-	return res, nil
+
+	return res, fname, nil
 }
 
-func ReadClimateFile(filename string) ([]zeus.ClimateReport, error) {
-	f, err := os.Open(filename)
+func NewFileClimateReporter(filename string, numAux int, rotation LogRotationDefinition) (ClimateReporter, string, error) {
+	res, fname, err := openClimateLog(filename, zeus.ClimateLogHeader{
+		Format:   zeus.TextClimateLog,
+		AuxCount: numAux,
+	}, rotation, false)
 	if err != nil {
-		return nil, err
+		return nil, "", err
 	}
-	defer f.Close()
+	return res, fname, nil
+}
 
-	reader := bufio.NewReader(f)
-	startDate, err := readStartDate(reader)
-	if err != nil {
-		return nil, err
+// NewStructuredClimateReporter creates a climate log in the CSV or
+// JSON Lines format. The start of header is filled in.
+func NewStructuredClimateReporter(filename string, header zeus.ClimateLogHeader, rotation LogRotationDefinition) (ClimateReporter, string, error) {
+	if header.Format != zeus.CSVClimateLog && header.Format != zeus.JSONLClimateLog {
+		return nil, "", fmt.Errorf("invalid climate log format '%s'", header.Format)
 	}
-	numAux, err := readNumAux(reader)
+	res, fname, err := openClimateLog(filename, header, rotation, false)
 	if err != nil {
-		return nil, err
-	}
-
-	var res []zeus.ClimateReport
-	for {
-		cr, err := readClimateReport(reader, startDate, numAux)
-		if err == io.EOF {
-			break
-		}
-		if err != nil {
-			return res, err
-		}
-		res = append(res, cr)
+		return nil, "", err
 	}
-
-	return res, nil
+	return res, fname, nil
 }
@@ -0,0 +1,352 @@
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/gob"
+	"encoding/json"
+	"fmt"
+	"math"
+	"path"
+	"time"
+
+	"github.com/formicidae-tracker/olympus/pkg/tm"
+	"github.com/formicidae-tracker/zeus/internal/zeus"
+	"github.com/sirupsen/logrus"
+	bolt "go.etcd.io/bbolt"
+)
+
+var (
+	reportsBucket = []byte("reports")
+	targetsBucket = []byte("targets")
+	alarmsBucket  = []byte("alarms")
+)
+
+// climateStore is an embedded store of the climate reports, targets
+// and alarm events of a zone, indexed by time.
+type climateStore struct {
+	db *bolt.DB
+}
+
+// storedTarget is a ClimateTarget with the time it was emitted.
+type storedTarget struct {
+	Time   time.Time
+	Target zeus.ClimateTarget
+}
+
+func OpenClimateStore(filename string) (*climateStore, error) {
+	db, err := bolt.Open(filename, 0644, &bolt.Options{Timeout: time.Second})
+	if err != nil {
+		return nil, fmt.Errorf("could not open climate store '%s': %w", filename, err)
+	}
+	err = db.Update(func(tx *bolt.Tx) error {
+		for _, b := range [][]byte{reportsBucket, targetsBucket, alarmsBucket} {
+			if _, err := tx.CreateBucketIfNotExists(b); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+	return &climateStore{db: db}, nil
+}
+
+func (s *climateStore) Close() error {
+	return s.db.Close()
+}
+
+// keys are the big endian time in nanoseconds followed by a sequence
+// number, which keeps them unique and sorted by time. The zero time
+// maps to the first possible key.
+func storeKey(t time.Time, seq uint64) []byte {
+	res := make([]byte, 16)
+	if t.IsZero() == false {
+		binary.BigEndian.PutUint64(res, uint64(t.UnixNano()))
+	}
+	binary.BigEndian.PutUint64(res[8:], seq)
+	return res
+}
+
+func storeKeyPrefix(t time.Time) []byte {
+	return storeKey(t, 0)[:8]
+}
+
+func storeKeyTime(k []byte) time.Time {
+	return time.Unix(0, int64(binary.BigEndian.Uint64(k)))
+}
+
+func encodeReport(r zeus.ClimateReport) []byte {
+	res := make([]byte, 8*(len(r.Temperatures)+1))
+	binary.BigEndian.PutUint64(res, math.Float64bits(r.Humidity.Value()))
+	for i, t := range r.Temperatures {
+		binary.BigEndian.PutUint64(res[8*(i+1):], math.Float64bits(t.Value()))
+	}
+	return res
+}
+
+func decodeReport(k, v []byte) (zeus.ClimateReport, error) {
+	if len(v) < 8 || len(v)%8 != 0 {
+		return zeus.ClimateReport{}, fmt.Errorf("invalid report size %d", len(v))
+	}
+	res := zeus.ClimateReport{
+		Time:         storeKeyTime(k),
+		Humidity:     zeus.Humidity(math.Float64frombits(binary.BigEndian.Uint64(v))),
+		Temperatures: make([]zeus.Temperature, len(v)/8-1),
+	}
+	for i := range res.Temperatures {
+		res.Temperatures[i] = zeus.Temperature(math.Float64frombits(binary.BigEndian.Uint64(v[8*(i+1):])))
+	}
+	return res, nil
+}
+
+func (s *climateStore) put(bucket []byte, t time.Time, value []byte) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket(bucket)
+		seq, err := b.NextSequence()
+		if err != nil {
+			return err
+		}
+		return b.Put(storeKey(t, seq), value)
+	})
+}
+
+func (s *climateStore) AddReport(r zeus.ClimateReport) error {
+	return s.put(reportsBucket, r.Time, encodeReport(r))
+}
+
+func (s *climateStore) AddTarget(t time.Time, target zeus.ClimateTarget) error {
+	// targets holds undefined values that JSON cannot represent.
+	buf := bytes.NewBuffer(nil)
+	if err := gob.NewEncoder(buf).Encode(target); err != nil {
+		return err
+	}
+	return s.put(targetsBucket, t, buf.Bytes())
+}
+
+func (s *climateStore) AddAlarm(e zeus.AlarmEvent) error {
+	data, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	return s.put(alarmsBucket, e.Time, data)
+}
+
+// walk calls fn for each entry in [start;end[. A zero end means no
+// upper bound.
+func (s *climateStore) walk(bucket []byte, start, end time.Time, fn func(k, v []byte) error) error {
+	return s.db.View(func(tx *bolt.Tx) error {
+		c := tx.Bucket(bucket).Cursor()
+		var endKey []byte
+		if end.IsZero() == false {
+			endKey = storeKeyPrefix(end)
+		}
+		for k, v := c.Seek(storeKeyPrefix(start)); k != nil; k, v = c.Next() {
+			if endKey != nil && bytes.Compare(k, endKey) >= 0 {
+				return nil
+			}
+			if err := fn(k, v); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// Reports returns all reports in [start;end[. A zero end means no
+// upper bound.
+func (s *climateStore) Reports(start, end time.Time) ([]zeus.ClimateReport, error) {
+	var res []zeus.ClimateReport
+	err := s.walk(reportsBucket, start, end, func(k, v []byte) error {
+		r, err := decodeReport(k, v)
+		if err != nil {
+			return err
+		}
+		res = append(res, r)
+		return nil
+	})
+	return res, err
+}
+
+// Targets returns all targets emitted in [start;end[. A zero end
+// means no upper bound.
+func (s *climateStore) Targets(start, end time.Time) ([]storedTarget, error) {
+	var res []storedTarget
+	err := s.walk(targetsBucket, start, end, func(k, v []byte) error {
+		t := storedTarget{Time: storeKeyTime(k)}
+		if err := gob.NewDecoder(bytes.NewReader(v)).Decode(&t.Target); err != nil {
+			return err
+		}
+		res = append(res, t)
+		return nil
+	})
+	return res, err
+}
+
+// Alarms returns all alarm events in [start;end[. A zero end means
+// no upper bound.
+func (s *climateStore) Alarms(start, end time.Time) ([]zeus.AlarmEvent, error) {
+	var res []zeus.AlarmEvent
+	err := s.walk(alarmsBucket, start, end, func(k, v []byte) error {
+		e := zeus.AlarmEvent{}
+		if err := json.Unmarshal(v, &e); err != nil {
+			return err
+		}
+		res = append(res, e)
+		return nil
+	})
+	return res, err
+}
+
+// indexRange calls fn on the entries since a given time, from the
+// start-th one to the end-th one excluded, with the same semantic
+// than clampRange.
+func (s *climateStore) indexRange(bucket []byte, since time.Time, start, end int, fn func(k, v []byte) error) error {
+	if err := checkRange(start, end); err != nil {
+		return err
+	}
+	return s.db.View(func(tx *bolt.Tx) error {
+		c := tx.Bucket(bucket).Cursor()
+		i := 0
+		for k, v := c.Seek(storeKeyPrefix(since)); k != nil; k, v = c.Next() {
+			if end > 0 && i >= end {
+				return nil
+			}
+			if i >= start {
+				if err := fn(k, v); err != nil {
+					return err
+				}
+			}
+			i++
+		}
+		if end > 0 && i < end || end <= 0 && start >= i {
+			if end <= 0 {
+				end = i
+			}
+			return fmt.Errorf("unsufficient data size %d for [%d;%d[", i, start, end)
+		}
+		return nil
+	})
+}
+
+// ReportRange returns the reports since a given time, from the
+// start-th one to the end-th one excluded. A non-positive end
+// returns all remaining reports.
+func (s *climateStore) ReportRange(since time.Time, start, end int) ([]zeus.ClimateReport, error) {
+	var res []zeus.ClimateReport
+	err := s.indexRange(reportsBucket, since, start, end, func(k, v []byte) error {
+		r, err := decodeReport(k, v)
+		if err != nil {
+			return err
+		}
+		res = append(res, r)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// AlarmRange returns the alarm events since a given time, from the
+// start-th one to the end-th one excluded. A non-positive end
+// returns all remaining events.
+func (s *climateStore) AlarmRange(since time.Time, start, end int) ([]zeus.AlarmEvent, error) {
+	var res []zeus.AlarmEvent
+	err := s.indexRange(alarmsBucket, since, start, end, func(k, v []byte) error {
+		e := zeus.AlarmEvent{}
+		if err := json.Unmarshal(v, &e); err != nil {
+			return err
+		}
+		res = append(res, e)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// Prune removes all data older than before.
+func (s *climateStore) Prune(before time.Time) error {
+	limit := storeKeyPrefix(before)
+	for _, bucket := range [][]byte{reportsBucket, targetsBucket, alarmsBucket} {
+		for {
+			// removes in chunks to keep transactions small.
+			n := 0
+			err := s.db.Update(func(tx *bolt.Tx) error {
+				c := tx.Bucket(bucket).Cursor()
+				for k, _ := c.First(); k != nil && n < 10000 && bytes.Compare(k, limit) < 0; k, _ = c.First() {
+					if err := c.Delete(); err != nil {
+						return err
+					}
+					n++
+				}
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+			if n < 10000 {
+				break
+			}
+		}
+	}
+	return nil
+}
+
+// storeReporter records everything happening in a zone in a
+// climateStore and enforces its retention.
+type storeReporter struct {
+	zoneStream
+
+	store     *climateStore
+	retention time.Duration
+	log       *logrus.Entry
+}
+
+func (r *storeReporter) report(report zeus.ClimateReport) {
+	if err := r.store.AddReport(report); err != nil {
+		r.log.WithError(err).Error("could not store report")
+	}
+}
+
+func (r *storeReporter) target(target zeus.ClimateTarget) {
+	if err := r.store.AddTarget(time.Now(), target); err != nil {
+		r.log.WithError(err).Error("could not store target")
+	}
+}
+
+func (r *storeReporter) alarm(event zeus.AlarmEvent) {
+	if err := r.store.AddAlarm(event); err != nil {
+		r.log.WithError(err).Error("could not store alarm event")
+	}
+}
+
+func (r *storeReporter) prune() {
+	if r.retention <= 0 {
+		return
+	}
+	if err := r.store.Prune(time.Now().Add(-r.retention)); err != nil {
+		r.log.WithError(err).Error("could not prune store")
+	}
+}
+
+func (r *storeReporter) Report(ready chan<- struct{}) {
+	ticker := time.NewTicker(time.Hour)
+	defer ticker.Stop()
+	close(ready)
+	r.prune()
+	r.stream(r, ticker.C, r.prune)
+}
+
+func NewStoreReporter(zone string, store *climateStore, retention time.Duration) *storeReporter {
+	return &storeReporter{
+		zoneStream: newZoneStream(),
+		store:      store,
+		retention:  retention,
+		log:        tm.NewLogger(path.Join("zone", zone, "store")),
+	}
+}
@@ -0,0 +1,140 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/formicidae-tracker/zeus/internal/zeus"
+	. "gopkg.in/check.v1"
+)
+
+type ClimateStoreSuite struct {
+	TmpDir string
+	Store  *climateStore
+	Start  time.Time
+}
+
+var _ = Suite(&ClimateStoreSuite{})
+
+func (s *ClimateStoreSuite) SetUpTest(c *C) {
+	var err error
+	s.TmpDir, err = ioutil.TempDir("", "zeus-store")
+	c.Assert(err, IsNil)
+	s.Store, err = OpenClimateStore(filepath.Join(s.TmpDir, "box.db"))
+	c.Assert(err, IsNil)
+	s.Start = time.Date(2023, 3, 1, 12, 0, 0, 0, time.UTC)
+	for i := 0; i < 10; i++ {
+		c.Assert(s.Store.AddReport(zeus.ClimateReport{
+			Time:         s.Start.Add(time.Duration(i) * time.Minute),
+			Humidity:     zeus.Humidity(50 + i),
+			Temperatures: []zeus.Temperature{20, zeus.Temperature(21 + i)},
+		}), IsNil)
+	}
+	for i := 0; i < 3; i++ {
+		c.Assert(s.Store.AddAlarm(zeus.AlarmEvent{
+			Identifier: "foo",
+			Status:     zeus.AlarmStatus(i % 2),
+			Time:       s.Start.Add(time.Duration(3*i) * time.Minute),
+		}), IsNil)
+	}
+}
+
+func (s *ClimateStoreSuite) TearDownTest(c *C) {
+	if s.Store != nil {
+		c.Check(s.Store.Close(), IsNil)
+	}
+	c.Check(os.RemoveAll(s.TmpDir), IsNil)
+}
+
+func (s *ClimateStoreSuite) TestTimeRangeQueries(c *C) {
+	reports, err := s.Store.Reports(s.Start.Add(2*time.Minute), s.Start.Add(5*time.Minute))
+	c.Assert(err, IsNil)
+	c.Assert(len(reports), Equals, 3)
+	for i, r := range reports {
+		c.Check(r.Time.Equal(s.Start.Add(time.Duration(i+2)*time.Minute)), Equals, true)
+		c.Check(r.Humidity, Equals, zeus.Humidity(52+i))
+		c.Check(r.Temperatures, DeepEquals, []zeus.Temperature{20, zeus.Temperature(23 + i)})
+	}
+
+	reports, err = s.Store.Reports(s.Start.Add(8*time.Minute), time.Time{})
+	c.Assert(err, IsNil)
+	c.Check(len(reports), Equals, 2)
+
+	events, err := s.Store.Alarms(s.Start.Add(time.Minute), time.Time{})
+	c.Assert(err, IsNil)
+	c.Assert(len(events), Equals, 2)
+	c.Check(events[0].Status, Equals, zeus.AlarmOff)
+	c.Check(events[1].Time.Equal(s.Start.Add(6*time.Minute)), Equals, true)
+
+	target := zeus.ClimateTarget{
+		Current: zeus.State{
+			Name:         "day",
+			Temperature:  26,
+			Humidity:     zeus.UndefinedHumidity,
+			Wind:         zeus.UndefinedWind,
+			VisibleLight: 100,
+			UVLight:      zeus.UndefinedLight,
+		},
+	}
+	c.Assert(s.Store.AddTarget(s.Start, target), IsNil)
+	targets, err := s.Store.Targets(time.Time{}, time.Time{})
+	c.Assert(err, IsNil)
+	c.Assert(len(targets), Equals, 1)
+	c.Check(targets[0].Time.Equal(s.Start), Equals, true)
+	c.Check(targets[0].Target, DeepEquals, target)
+}
+
+func (s *ClimateStoreSuite) TestIndexRangeQueries(c *C) {
+	since := s.Start.Add(5 * time.Minute)
+	testdata := []struct {
+		Start, End int
+		Expected   []int
+		Error      string
+	}{
+		{0, 0, []int{5, 6, 7, 8, 9}, ""},
+		{2, 0, []int{7, 8, 9}, ""},
+		{1, 3, []int{6, 7}, ""},
+		{0, 5, []int{5, 6, 7, 8, 9}, ""},
+		{0, 6, nil, `unsufficient data size 5 for \[0;6\[`},
+		{5, 0, nil, `unsufficient data size 5 for \[5;5\[`},
+		{3, 2, nil, `invalid range \[3;2\[`},
+	}
+
+	for _, d := range testdata {
+		comment := Commentf("range [%d;%d[", d.Start, d.End)
+		reports, err := s.Store.ReportRange(since, d.Start, d.End)
+		if len(d.Error) > 0 {
+			c.Check(err, ErrorMatches, d.Error, comment)
+			continue
+		}
+		c.Assert(err, IsNil, comment)
+		c.Assert(len(reports), Equals, len(d.Expected), comment)
+		for i, r := range reports {
+			c.Check(r.Time.Equal(s.Start.Add(time.Duration(d.Expected[i])*time.Minute)), Equals, true, comment)
+		}
+	}
+
+	events, err := s.Store.AlarmRange(since, 0, 0)
+	c.Assert(err, IsNil)
+	c.Check(len(events), Equals, 1)
+}
+
+func (s *ClimateStoreSuite) TestPruneAndPersistence(c *C) {
+	c.Assert(s.Store.Prune(s.Start.Add(4*time.Minute)), IsNil)
+	c.Assert(s.Store.Close(), IsNil)
+
+	var err error
+	s.Store, err = OpenClimateStore(filepath.Join(s.TmpDir, "box.db"))
+	c.Assert(err, IsNil)
+
+	reports, err := s.Store.Reports(time.Time{}, time.Time{})
+	c.Assert(err, IsNil)
+	c.Assert(len(reports), Equals, 6)
+	c.Check(reports[0].Time.Equal(s.Start.Add(4*time.Minute)), Equals, true)
+
+	events, err := s.Store.Alarms(time.Time{}, time.Time{})
+	c.Assert(err, IsNil)
+	c.Check(len(events), Equals, 1)
+}
@@ -2,30 +2,348 @@ package main
 
 import (
 	"fmt"
+	"net/url"
 	"os"
 	"regexp"
+	"time"
 
+	"github.com/formicidae-tracker/libarke/src-go/arke"
+	"github.com/formicidae-tracker/zeus/internal/zeus"
 	flags "github.com/jessevdk/go-flags"
 	yaml "gopkg.in/yaml.v2"
 )
 
 type ZoneDefinition struct {
-	CANInterface   string `yaml:"can-interface"`
-	DevicesID      uint   `yaml:"devices-id"`
-	TemperatureAux int    `yaml:"temperature-aux"`
-	HasNotusDevice bool   `yaml:"has-notus-device"`
+	CANInterface        string                `yaml:"can-interface"`
+	DevicesID           uint                  `yaml:"devices-id"`
+	TemperatureAux      int                   `yaml:"temperature-aux"`
+	TemperatureAuxNames []string              `yaml:"temperature-aux-names,omitempty"`
+	HasNotusDevice      bool                  `yaml:"has-notus-device"`
+	NotusWindShare      float64               `yaml:"notus-wind-share"`
+	Retention           time.Duration         `yaml:"retention"`
+	DisableTextLogs     bool                  `yaml:"disable-text-logs"`
+	ClimateLogFormat    string                `yaml:"climate-log-format"`
+	LogRotation         LogRotationDefinition `yaml:"log-rotation"`
+	MQTT                *MQTTDefinition       `yaml:"mqtt,omitempty"`
+	InfluxDB            *InfluxDBDefinition   `yaml:"influxdb,omitempty"`
+	SafeState           SafeStateDefinition   `yaml:"safe-state"`
+	Channels            ChannelsDefinition    `yaml:"channels"`
+}
+
+// climateLogExtension returns the file extension of the climate log
+// for the configured format: 'txt' (the default), 'csv' or 'jsonl'.
+func (d ZoneDefinition) climateLogExtension() string {
+	switch d.ClimateLogFormat {
+	case zeus.CSVClimateLog, zeus.JSONLClimateLog:
+		return d.ClimateLogFormat
+	default:
+		return "txt"
+	}
+}
+
+// defaultNotusWindShare is the share of the wind, in %, driven by the
+// Notus when notus-wind-share is not set.
+const defaultNotusWindShare = 50.0
+
+// notusWindShare returns the share of the wind driven by the Notus,
+// 50% unless set.
+func (d ZoneDefinition) notusWindShare() float64 {
+	if d.NotusWindShare == 0 {
+		return defaultNotusWindShare
+	}
+	return d.NotusWindShare
+}
+
+// LogRotationDefinition configures rotation of the climate and alarm
+// text logs. Rotated segments are gzip compressed. Segments and the
+// logs of previous climate starts are removed once older than
+// Retention. Zero values disable the corresponding feature.
+type LogRotationDefinition struct {
+	MaxSize   int64         `yaml:"max-size"`
+	MaxAge    time.Duration `yaml:"max-age"`
+	Retention time.Duration `yaml:"retention"`
+}
+
+func (d LogRotationDefinition) check() error {
+	if d.MaxSize < 0 || d.MaxAge < 0 || d.Retention < 0 {
+		return fmt.Errorf("invalid log-rotation: negative values")
+	}
+	return nil
+}
+
+type ChannelMode string
+
+const (
+	// ControlledChannel channels follow the targets of the season, it
+	// is the default.
+	ControlledChannel ChannelMode = "controlled"
+	// MonitoredChannel channels are recorded and checked against the
+	// bounds of the season, but not controlled.
+	MonitoredChannel ChannelMode = "monitored"
+	// IgnoredChannel channels are neither controlled nor checked.
+	IgnoredChannel ChannelMode = "ignored"
+)
+
+// ChannelsDefinition sets how each climate channel of a zone is
+// handled.
+type ChannelsDefinition struct {
+	Temperature ChannelMode `yaml:"temperature"`
+	Humidity    ChannelMode `yaml:"humidity"`
+	Wind        ChannelMode `yaml:"wind"`
+	Light       ChannelMode `yaml:"light"`
+}
+
+func modeOrDefault(m ChannelMode) ChannelMode {
+	if len(m) == 0 {
+		return ControlledChannel
+	}
+	return m
+}
+
+func (d ChannelsDefinition) TemperatureMode() ChannelMode { return modeOrDefault(d.Temperature) }
+func (d ChannelsDefinition) HumidityMode() ChannelMode    { return modeOrDefault(d.Humidity) }
+func (d ChannelsDefinition) WindMode() ChannelMode        { return modeOrDefault(d.Wind) }
+func (d ChannelsDefinition) LightMode() ChannelMode       { return modeOrDefault(d.Light) }
+
+func (d ChannelsDefinition) check() error {
+	for name, mode := range map[string]ChannelMode{
+		"temperature": d.TemperatureMode(),
+		"humidity":    d.HumidityMode(),
+	} {
+		switch mode {
+		case ControlledChannel, MonitoredChannel, IgnoredChannel:
+		default:
+			return fmt.Errorf("invalid %s channel mode '%s' (should be controlled, monitored or ignored)", name, mode)
+		}
+	}
+	// wind and light are not measured.
+	for name, mode := range map[string]ChannelMode{
+		"wind":  d.WindMode(),
+		"light": d.LightMode(),
+	} {
+		switch mode {
+		case ControlledChannel, IgnoredChannel:
+		default:
+			return fmt.Errorf("invalid %s channel mode '%s' (should be controlled or ignored)", name, mode)
+		}
+	}
+	return nil
+}
+
+const (
+	SafeTemperatureHold = "hold"
+	SafeTemperatureOff  = "off"
+)
+
+// SafeStateDefinition is the state the devices of a zone are left in
+// when its climate stops.
+type SafeStateDefinition struct {
+	// Temperature is 'hold' (the default) to keep regulating the last
+	// target temperature and humidity, or 'off' to reset the Zeus and
+	// Celaeno, which then stay idle until their next set point.
+	Temperature string `yaml:"temperature"`
+	// Wind, VisibleLight and UVLight are in %, 0 by default.
+	Wind         float64 `yaml:"wind"`
+	VisibleLight float64 `yaml:"visible-light"`
+	UVLight      float64 `yaml:"uv-light"`
+}
+
+func (d SafeStateDefinition) check() error {
+	switch d.Temperature {
+	case "", SafeTemperatureHold, SafeTemperatureOff:
+	default:
+		return fmt.Errorf("invalid safe-state temperature '%s' (should be hold or off)", d.Temperature)
+	}
+	for _, v := range []float64{d.Wind, d.VisibleLight, d.UVLight} {
+		if v < 0 || v > 100 {
+			return fmt.Errorf("invalid safe-state value %g (should be in [0,100])", v)
+		}
+	}
+	return nil
+}
+
+// MQTTDefinition configures publication of a zone climate to a MQTT
+// broker. In topics, '{host}' and '{zone}' are replaced by the host
+// and zone name.
+type MQTTDefinition struct {
+	Broker       string `yaml:"broker"`
+	ClientID     string `yaml:"client-id"`
+	Username     string `yaml:"username"`
+	Password     string `yaml:"password"`
+	QoS          byte   `yaml:"qos"`
+	Retain       bool   `yaml:"retain"`
+	ClimateTopic string `yaml:"climate-topic"`
+	TargetTopic  string `yaml:"target-topic"`
+	AlarmTopic   string `yaml:"alarm-topic"`
+}
+
+func (d MQTTDefinition) check() error {
+	if len(d.Broker) == 0 {
+		return fmt.Errorf("missing mqtt broker")
+	}
+	if d.QoS > 2 {
+		return fmt.Errorf("invalid mqtt qos %d (should be in [0,2])", d.QoS)
+	}
+	return nil
+}
+
+// InfluxDBDefinition configures writing of a zone climate in InfluxDB
+// line protocol to an HTTP write endpoint, e.g.
+// 'http://localhost:8086/api/v2/write?org=lab&bucket=zeus'.
+type InfluxDBDefinition struct {
+	URL         string        `yaml:"url"`
+	Token       string        `yaml:"token"`
+	FlushPeriod time.Duration `yaml:"flush-period"`
+}
+
+func (d InfluxDBDefinition) check() error {
+	u, err := url.Parse(d.URL)
+	if err != nil {
+		return fmt.Errorf("invalid influxdb url: %w", err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return fmt.Errorf("invalid influxdb url '%s': scheme should be http or https", d.URL)
+	}
+	if d.FlushPeriod < 0 {
+		return fmt.Errorf("invalid influxdb flush-period %s", d.FlushPeriod)
+	}
+	return nil
 }
 
 func (d ZoneDefinition) ID() string {
 	return fmt.Sprintf("%s/%d", d.CANInterface, d.DevicesID)
 }
 
+// InterfaceType is the kind of a CAN interface.
+type InterfaceType string
+
+const (
+	// SlcanInterface is a serial line adapter opened with slcand.
+	SlcanInterface InterfaceType = "slcan"
+	// NativeInterface is a SocketCAN interface of a kernel driver,
+	// e.g. a MCP2515 HAT or a gs_usb adapter.
+	NativeInterface InterfaceType = "native"
+	// VirtualInterface is a vcan interface, for testing.
+	VirtualInterface InterfaceType = "virtual"
+)
+
+// slcanBitrates maps the bitrates supported by slcand to their setup
+// code.
+var slcanBitrates = map[int]string{
+	10000:   "0",
+	20000:   "1",
+	50000:   "2",
+	100000:  "3",
+	125000:  "4",
+	250000:  "5",
+	500000:  "6",
+	800000:  "7",
+	1000000: "8",
+}
+
+// InterfaceDefinition defines a CAN interface. It can be written as
+// the device of a slcan interface alone.
+type InterfaceDefinition struct {
+	Type InterfaceType `yaml:"type"`
+	// Device is the adapter of a slcan interface, see
+	// serialDeviceResolver.
+	Device string `yaml:"device"`
+	// Bitrate of slcan and native interfaces, DefaultCANBitrate if
+	// zero.
+	Bitrate int `yaml:"bitrate"`
+}
+
+func (d *InterfaceDefinition) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	if err := unmarshal(&d.Device); err == nil {
+		d.Type = SlcanInterface
+		return nil
+	}
+	type definitionYAML InterfaceDefinition
+	res := definitionYAML{Type: SlcanInterface}
+	if err := unmarshal(&res); err != nil {
+		return err
+	}
+	*d = InterfaceDefinition(res)
+	return nil
+}
+
+// InterfaceType returns the type of the interface, slcan by default.
+func (d InterfaceDefinition) InterfaceType() InterfaceType {
+	if len(d.Type) == 0 {
+		return SlcanInterface
+	}
+	return d.Type
+}
+
+// CANBitrate returns the bitrate of the interface.
+func (d InterfaceDefinition) CANBitrate() int {
+	if d.Bitrate == 0 {
+		return DefaultCANBitrate
+	}
+	return d.Bitrate
+}
+
+func (d InterfaceDefinition) check(ifname string) error {
+	switch d.InterfaceType() {
+	case SlcanInterface:
+		if regexp.MustCompile(`slcan[0-9]+`).MatchString(ifname) == false {
+			return fmt.Errorf("invalid interface name")
+		}
+		if len(d.Device) == 0 {
+			return fmt.Errorf("missing device")
+		}
+		if d.Device == serialNumberPrefix {
+			return fmt.Errorf("empty serial number")
+		}
+		if _, ok := slcanBitrates[d.CANBitrate()]; ok == false {
+			return fmt.Errorf("unsupported slcan bitrate %d", d.CANBitrate())
+		}
+	case NativeInterface, VirtualInterface:
+		if regexp.MustCompile(`^[a-zA-Z][a-zA-Z0-9_-]{0,14}$`).MatchString(ifname) == false {
+			return fmt.Errorf("invalid interface name")
+		}
+		if len(d.Device) > 0 {
+			return fmt.Errorf("%s interfaces have no device", d.Type)
+		}
+		if d.Type == VirtualInterface && d.Bitrate != 0 {
+			return fmt.Errorf("virtual interfaces have no bitrate")
+		}
+		if d.Bitrate < 0 {
+			return fmt.Errorf("invalid bitrate %d", d.Bitrate)
+		}
+	default:
+		return fmt.Errorf("invalid type '%s' (should be slcan, native or virtual)", d.Type)
+	}
+	return nil
+}
+
 type Config struct {
-	Olympus      string                    `yaml:"olympus"`
-	Interfaces   map[string]string         `yaml:"interfaces"`
-	Zones        map[string]ZoneDefinition `yaml:"zones"`
-	OTELEndpoint string                    `yaml:"otel_collector_endpoint"`
-	Verbosity    int                       `yaml:"verbosity"`
+	Olympus        string                         `yaml:"olympus"`
+	Interfaces     map[string]InterfaceDefinition `yaml:"interfaces"`
+	Zones          map[string]ZoneDefinition      `yaml:"zones"`
+	OTELEndpoint   string                         `yaml:"otel_collector_endpoint"`
+	MetricsAddress string                         `yaml:"metrics-address"`
+	Verbosity      int                            `yaml:"verbosity"`
+	// MinimumFirmware is the minimum firmware version of each device
+	// class, e.g. 'zeus: 1.2'. Older devices raise an alarm.
+	MinimumFirmware map[string]string `yaml:"minimum-firmware"`
+	// CANLogDirectory, if set, is where the traffic of all CAN
+	// interfaces is recorded as candump logs.
+	CANLogDirectory string `yaml:"can-log-directory"`
+	// Emulate replaces the CAN interfaces by emulated Arke nodes,
+	// regulating the climate of EmulatedPlant. Faults of the
+	// EmulatedScenario file are injected in the nodes.
+	Emulate          bool             `yaml:"emulate"`
+	EmulatedPlant    *PlantParameters `yaml:"emulated-plant,omitempty"`
+	EmulatedScenario string           `yaml:"emulated-scenario"`
+}
+
+// plantParameters returns the parameters of the emulated plants.
+func (c Config) plantParameters() PlantParameters {
+	if c.EmulatedPlant == nil {
+		return DefaultPlantParameters()
+	}
+	return *c.EmulatedPlant
 }
 
 const DEFAULT_CONFIG_PATH = "/etc/default/zeus.yml"
@@ -68,18 +386,58 @@ func (c Config) checkZones() error {
 			return fmt.Errorf("Invalid zone definition '%s': devices ID %d on interface '%s' are used by zone '%s'", name, definition.DevicesID, definition.CANInterface, oName)
 		}
 		mapping[def] = name
+
+		if definition.Retention < 0 {
+			return fmt.Errorf("Invalid zone definition '%s': invalid retention %s", name, definition.Retention)
+		}
+		switch definition.ClimateLogFormat {
+		case "", zeus.TextClimateLog, zeus.CSVClimateLog, zeus.JSONLClimateLog:
+		default:
+			return fmt.Errorf("Invalid zone definition '%s': invalid climate-log-format '%s' (should be text, csv or jsonl)", name, definition.ClimateLogFormat)
+		}
+		if len(definition.TemperatureAuxNames) > definition.TemperatureAux {
+			return fmt.Errorf("Invalid zone definition '%s': %d temperature-aux-names for %d temperature-aux", name, len(definition.TemperatureAuxNames), definition.TemperatureAux)
+		}
+		if err := definition.LogRotation.check(); err != nil {
+			return fmt.Errorf("Invalid zone definition '%s': %w", name, err)
+		}
+		if definition.NotusWindShare < 0 || definition.NotusWindShare > 100 {
+			return fmt.Errorf("Invalid zone definition '%s': invalid notus-wind-share %g (should be in [0,100])", name, definition.NotusWindShare)
+		}
+		if definition.NotusWindShare > 0 && definition.HasNotusDevice == false {
+			return fmt.Errorf("Invalid zone definition '%s': notus-wind-share requires has-notus-device", name)
+		}
+		if err := definition.Channels.check(); err != nil {
+			return fmt.Errorf("Invalid zone definition '%s': %w", name, err)
+		}
+		if err := definition.SafeState.check(); err != nil {
+			return fmt.Errorf("Invalid zone definition '%s': %w", name, err)
+		}
+		if definition.MQTT != nil {
+			if err := definition.MQTT.check(); err != nil {
+				return fmt.Errorf("Invalid zone definition '%s': %w", name, err)
+			}
+		}
+		if definition.InfluxDB != nil {
+			if err := definition.InfluxDB.check(); err != nil {
+				return fmt.Errorf("Invalid zone definition '%s': %w", name, err)
+			}
+		}
 	}
 	return nil
 }
 
 func (c Config) checkInterfaces() error {
 	mapping := map[string]string{}
-	rx := regexp.MustCompile(`slcan[0-9]+`)
-	for ifname, devname := range c.Interfaces {
-		if rx.MatchString(ifname) == false {
-			return fmt.Errorf("Invalid interface definition '%s': invalid interface name", ifname)
+	for ifname, definition := range c.Interfaces {
+		if err := definition.check(ifname); err != nil {
+			return fmt.Errorf("Invalid interface definition '%s': %w", ifname, err)
+		}
+		if definition.InterfaceType() != SlcanInterface {
+			continue
 		}
 
+		devname := definition.Device
 		if oName, ok := mapping[devname]; ok == true {
 			return fmt.Errorf("Invalid interface definition '%s': device '%s' is already used by interface %s", ifname, devname, oName)
 		}
@@ -88,9 +446,44 @@ func (c Config) checkInterfaces() error {
 	return nil
 }
 
+func (c Config) checkMinimumFirmware() error {
+	for name, version := range c.MinimumFirmware {
+		if _, err := NameToArkeNodeClass(name); err != nil {
+			return fmt.Errorf("Invalid minimum-firmware: %w", err)
+		}
+		if _, err := ParseFirmwareVersion(version); err != nil {
+			return fmt.Errorf("Invalid minimum-firmware for %s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// minimumFirmware returns the checked MinimumFirmware by device class.
+func (c Config) minimumFirmware() map[arke.NodeClass]FirmwareVersion {
+	res := make(map[arke.NodeClass]FirmwareVersion)
+	for name, version := range c.MinimumFirmware {
+		class, err := NameToArkeNodeClass(name)
+		if err != nil {
+			continue
+		}
+		if v, err := ParseFirmwareVersion(version); err == nil {
+			res[class] = v
+		}
+	}
+	return res
+}
+
 func (c Config) Check() error {
 	if err := c.checkInterfaces(); err != nil {
 		return err
 	}
+	if err := c.checkMinimumFirmware(); err != nil {
+		return err
+	}
+	if c.EmulatedPlant != nil {
+		if err := c.EmulatedPlant.check(); err != nil {
+			return fmt.Errorf("Invalid emulated-plant: %w", err)
+		}
+	}
 	return c.checkZones()
 }
@@ -0,0 +1,311 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+
+	"github.com/formicidae-tracker/olympus/pkg/tm"
+	"github.com/formicidae-tracker/zeus/internal/zeus"
+	"github.com/formicidae-tracker/zeus/pkg/zeuspb"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+)
+
+// ConfigReload lists the changes of a reloaded configuration that
+// were applied, and the ones that were refused as they would disrupt
+// a running climate or need a restart of zeus.
+type ConfigReload struct {
+	Applied []string
+	Refused []string
+}
+
+func (r *ConfigReload) apply(format string, args ...interface{}) {
+	r.Applied = append(r.Applied, fmt.Sprintf(format, args...))
+}
+
+func (r *ConfigReload) refuse(format string, args ...interface{}) {
+	r.Refused = append(r.Refused, fmt.Sprintf(format, args...))
+}
+
+// reloadConfig applies the changes of c that do not disrupt the
+// running zones. Refused changes are kept out of the current
+// configuration, so they are listed again by the next reload.
+func (z *Zeus) reloadConfig(c Config) (*ConfigReload, error) {
+	if err := c.Check(); err != nil {
+		return nil, fmt.Errorf("Invalid config: %s", err)
+	}
+	z.mx.Lock()
+	defer z.mx.Unlock()
+
+	res := &ConfigReload{}
+	z.reloadOlympus(c, res)
+	z.reloadTelemetry(c, res)
+	z.reloadStatic(c, res)
+	z.reloadMinimumFirmware(c, res)
+	z.reloadInterfaces(c, res)
+	z.reloadZones(c, res)
+
+	for _, change := range res.Applied {
+		z.logger.WithField("change", change).Info("configuration change applied")
+	}
+	for _, change := range res.Refused {
+		z.logger.WithField("change", change).Warn("configuration change refused")
+	}
+	if len(res.Applied) == 0 && len(res.Refused) == 0 {
+		z.logger.Info("configuration unchanged")
+	}
+	return res, nil
+}
+
+// olympusReporter is implemented by the runners whose reports to
+// olympus can move to another host.
+type olympusReporter interface {
+	SetOlympusHost(host string)
+}
+
+// reloadOlympus moves the reports of the running zones to the new
+// host. Enabling or disabling the reports requires restarting their
+// climate.
+func (z *Zeus) reloadOlympus(c Config, res *ConfigReload) {
+	if c.Olympus == z.config.Olympus {
+		return
+	}
+	if z.isRunning() == true && (len(c.Olympus) == 0 || len(z.config.Olympus) == 0) {
+		res.refuse("olympus: climate is running, its reports cannot be enabled or disabled")
+		return
+	}
+	z.config.Olympus = c.Olympus
+	z.olympusHost = c.Olympus
+	for _, runner := range z.runners {
+		if r, ok := runner.(olympusReporter); ok == true {
+			r.SetOlympusHost(c.Olympus)
+		}
+	}
+	res.apply("olympus: '%s'", c.Olympus)
+}
+
+func (z *Zeus) reloadTelemetry(c Config, res *ConfigReload) {
+	if c.OTELEndpoint == z.config.OTELEndpoint && c.Verbosity == z.config.Verbosity {
+		return
+	}
+	if len(c.OTELEndpoint) == 0 && len(z.config.OTELEndpoint) > 0 {
+		res.refuse("otel_collector_endpoint: disabling telemetry requires restarting zeus")
+		return
+	}
+	// the metrics export is the only setup that can fail, traces
+	// are only switched once it succeeded.
+	var meters *sdkmetric.MeterProvider
+	if c.OTELEndpoint != z.config.OTELEndpoint {
+		var err error
+		meters, err = setUpMetricsExport(c.OTELEndpoint, "zeus")
+		if err != nil {
+			res.refuse("otel_collector_endpoint: could not set up metrics export: %s", err)
+			return
+		}
+	}
+	if len(c.OTELEndpoint) > 0 {
+		tm.SetUpTelemetry(tm.OtelProviderArgs{
+			CollectorURL:   c.OTELEndpoint,
+			ServiceName:    "zeus",
+			ServiceVersion: zeus.ZEUS_VERSION,
+			Level:          tm.VerboseLevel(c.Verbosity),
+		})
+	}
+	if meters != nil {
+		if z.meters != nil {
+			if err := z.meters.Shutdown(context.Background()); err != nil {
+				z.logger.WithError(err).Warn("could not shutdown previous metrics export")
+			}
+		}
+		z.meters = meters
+		z.config.OTELEndpoint = c.OTELEndpoint
+		res.apply("otel_collector_endpoint: '%s'", c.OTELEndpoint)
+	}
+	if c.Verbosity == z.config.Verbosity {
+		return
+	}
+	if len(z.config.OTELEndpoint) == 0 {
+		// as on startup, the verbosity is only the level of telemetry.
+		res.refuse("verbosity: requires otel_collector_endpoint")
+		return
+	}
+	z.config.Verbosity = c.Verbosity
+	res.apply("verbosity: %d", c.Verbosity)
+}
+
+// reloadStatic refuses the changes of settings only read at startup,
+// and of the CAN log directory while interfaces are opened.
+func (z *Zeus) reloadStatic(c Config, res *ConfigReload) {
+	if c.MetricsAddress != z.config.MetricsAddress {
+		res.refuse("metrics-address: requires restarting zeus")
+	}
+	if c.Emulate != z.config.Emulate ||
+		reflect.DeepEqual(c.EmulatedPlant, z.config.EmulatedPlant) == false ||
+		c.EmulatedScenario != z.config.EmulatedScenario {
+		res.refuse("emulation: requires restarting zeus")
+	}
+	if c.CANLogDirectory == z.config.CANLogDirectory {
+		return
+	}
+	if z.isRunning() == true {
+		res.refuse("can-log-directory: climate is running")
+		return
+	}
+	z.config.CANLogDirectory = c.CANLogDirectory
+	z.canLogDir = c.CANLogDirectory
+	res.apply("can-log-directory: '%s'", c.CANLogDirectory)
+}
+
+func (z *Zeus) reloadMinimumFirmware(c Config, res *ConfigReload) {
+	if reflect.DeepEqual(c.MinimumFirmware, z.config.MinimumFirmware) == true {
+		return
+	}
+	z.config.MinimumFirmware = c.MinimumFirmware
+	for _, inventory := range z.inventories {
+		inventory.SetMinimum(c.minimumFirmware())
+	}
+	res.apply("minimum-firmware")
+}
+
+// usedInterfaces returns the interfaces of the running zones.
+func (z *Zeus) usedInterfaces() map[string]bool {
+	res := make(map[string]bool)
+	for name := range z.runners {
+		res[z.definitions[name].CANInterface] = true
+	}
+	return res
+}
+
+func (z *Zeus) reloadInterfaces(c Config, res *ConfigReload) {
+	used := z.usedInterfaces()
+	names := make(map[string]bool)
+	for name := range c.Interfaces {
+		names[name] = true
+	}
+	for name := range z.config.Interfaces {
+		names[name] = true
+	}
+
+	interfaces := make(map[string]InterfaceDefinition)
+	for name, def := range z.config.Interfaces {
+		interfaces[name] = def
+	}
+	for _, name := range sortedKeys(names) {
+		def, ok := c.Interfaces[name]
+		old, existed := z.config.Interfaces[name]
+		if ok == existed && def == old {
+			continue
+		}
+		if used[name] == true {
+			res.refuse("interface %s: used by a running zone", name)
+			continue
+		}
+		if ok == false {
+			delete(interfaces, name)
+			res.apply("interface %s removed", name)
+			continue
+		}
+		interfaces[name] = def
+		if existed == true {
+			res.apply("interface %s changed, run zeus open-interfaces again to apply it", name)
+		} else {
+			res.apply("interface %s added, run zeus open-interfaces again to open it", name)
+		}
+	}
+	z.config.Interfaces = interfaces
+}
+
+func (z *Zeus) reloadZones(c Config, res *ConfigReload) {
+	names := make(map[string]bool)
+	for name := range c.Zones {
+		names[name] = true
+	}
+	for name := range z.definitions {
+		names[name] = true
+	}
+
+	for _, name := range sortedKeys(names) {
+		def, ok := c.Zones[name]
+		old, existed := z.definitions[name]
+		if ok == existed && reflect.DeepEqual(def, old) == true {
+			continue
+		}
+		if _, running := z.runners[name]; running == true {
+			res.refuse("zone '%s': climate is running", name)
+			continue
+		}
+		if _, defined := z.config.Interfaces[def.CANInterface]; ok == true && defined == false {
+			res.refuse("zone '%s': interface %s is not applied", name, def.CANInterface)
+			continue
+		}
+		if other, used := z.runningZoneWithDevices(def); ok == true && used == true {
+			res.refuse("zone '%s': devices ID %d on interface %s are used by running zone '%s'",
+				name, def.DevicesID, def.CANInterface, other)
+			continue
+		}
+		if ok == false {
+			// the emulated interfaces read the same map.
+			delete(z.definitions, name)
+			delete(z.inventories, name)
+			res.apply("zone '%s' removed", name)
+			continue
+		}
+		z.definitions[name] = def
+		if existed == false {
+			z.inventories[name] = newDeviceInventory(z.config.minimumFirmware())
+			res.apply("zone '%s' added", name)
+			continue
+		}
+		if def.CANInterface != old.CANInterface || def.DevicesID != old.DevicesID {
+			z.inventories[name] = newDeviceInventory(z.config.minimumFirmware())
+		}
+		res.apply("zone '%s' changed", name)
+	}
+}
+
+// runningZoneWithDevices returns the running zone using the devices
+// of def. Other zones take their new definition, which were checked.
+func (z *Zeus) runningZoneWithDevices(def ZoneDefinition) (string, bool) {
+	for name := range z.runners {
+		other := z.definitions[name]
+		if other.CANInterface == def.CANInterface && other.DevicesID == def.DevicesID {
+			return name, true
+		}
+	}
+	return "", false
+}
+
+func sortedKeys(m map[string]bool) []string {
+	res := make([]string, 0, len(m))
+	for k := range m {
+		res = append(res, k)
+	}
+	sort.Strings(res)
+	return res
+}
+
+func (z *Zeus) ReloadConfig(ctx context.Context, e *zeuspb.Empty) (*zeuspb.ConfigReload, error) {
+	var err error
+	ctx, span := z.tracer.Start(ctx, "zeus/ReloadConfig")
+	defer func() { endWithError(span, err) }()
+
+	if z.configLoader == nil {
+		err = fmt.Errorf("configuration was not loaded from a file")
+		return nil, err
+	}
+	var c *Config
+	c, err = z.configLoader()
+	if err != nil {
+		return nil, err
+	}
+	var res *ConfigReload
+	res, err = z.reloadConfig(*c)
+	if err != nil {
+		return nil, err
+	}
+	return &zeuspb.ConfigReload{
+		Applied: res.Applied,
+		Refused: res.Refused,
+	}, nil
+}
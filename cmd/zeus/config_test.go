@@ -5,6 +5,7 @@ import (
 	"os"
 
 	. "gopkg.in/check.v1"
+	yaml "gopkg.in/yaml.v2"
 )
 
 type ConfigSuite struct {
@@ -14,9 +15,9 @@ var _ = Suite(&ConfigSuite{})
 
 var complexConfig = &Config{
 	Olympus: "olympus.local",
-	Interfaces: map[string]string{
-		"slcan0": "/dev/ttyS0",
-		"slcan1": "/dev/ttyS1",
+	Interfaces: map[string]InterfaceDefinition{
+		"slcan0": {Type: SlcanInterface, Device: "/dev/ttyS0"},
+		"slcan1": {Type: SlcanInterface, Device: "/dev/ttyS1"},
 	},
 	Zones: map[string]ZoneDefinition{
 		"box": ZoneDefinition{
@@ -72,24 +73,90 @@ fsdj: sdf
 	c.Check(err, Not(IsNil))
 }
 
+func (s *ConfigSuite) TestInterfaceDefinitions(c *C) {
+	config := &Config{}
+	c.Assert(yaml.Unmarshal([]byte(`---
+interfaces:
+  slcan0: serial:0035002E5734
+  slcan1:
+    device: /dev/ttyACM1
+    bitrate: 500000
+  can0:
+    type: native
+  vcan0:
+    type: virtual
+`), config), IsNil)
+	c.Check(config.Interfaces, DeepEquals, map[string]InterfaceDefinition{
+		"slcan0": {Type: SlcanInterface, Device: "serial:0035002E5734"},
+		"slcan1": {Type: SlcanInterface, Device: "/dev/ttyACM1", Bitrate: 500000},
+		"can0":   {Type: NativeInterface},
+		"vcan0":  {Type: VirtualInterface},
+	})
+	c.Check(config.Check(), IsNil)
+	c.Check(config.Interfaces["can0"].CANBitrate(), Equals, DefaultCANBitrate)
+	c.Check(config.Interfaces["slcan1"].CANBitrate(), Equals, 500000)
+	c.Check(InterfaceDefinition{Device: "/dev/ttyS0"}.InterfaceType(), Equals, SlcanInterface)
+}
+
 func (s *ConfigSuite) TestErrorChecking(c *C) {
 	testdata := map[*Config]string{
 		&Config{}:     "",
 		complexConfig: "",
 		&Config{
-			Interfaces: map[string]string{
-				"dlcan0": "/dev/ttyS0",
+			Interfaces: map[string]InterfaceDefinition{
+				"dlcan0": {Type: SlcanInterface, Device: "/dev/ttyS0"},
 			},
 		}: "Invalid interface definition 'dlcan0': invalid interface name",
 		&Config{
-			Interfaces: map[string]string{
-				"slcan0": "/dev/ttyS0",
-				"slcan1": "/dev/ttyS0",
+			Interfaces: map[string]InterfaceDefinition{
+				"slcan0": {Type: SlcanInterface, Device: "/dev/ttyS0"},
+				"slcan1": {Type: SlcanInterface, Device: "/dev/ttyS0"},
 			},
 		}: "Invalid interface definition 'slcan.*': device '/dev/ttyS0' is already used by interface slcan.*",
 		&Config{
-			Interfaces: map[string]string{
-				"slcan0": "/dev/ttyS0",
+			Interfaces: map[string]InterfaceDefinition{
+				"slcan0": {Type: SlcanInterface, Device: "serial:"},
+			},
+		}: "Invalid interface definition 'slcan0': empty serial number",
+		&Config{
+			Interfaces: map[string]InterfaceDefinition{
+				"slcan0": {Type: SlcanInterface, Device: "/dev/ttyS0", Bitrate: 42},
+			},
+		}: "Invalid interface definition 'slcan0': unsupported slcan bitrate 42",
+		&Config{
+			Interfaces: map[string]InterfaceDefinition{
+				"slcan0": {Type: SlcanInterface},
+			},
+		}: "Invalid interface definition 'slcan0': missing device",
+		&Config{
+			Interfaces: map[string]InterfaceDefinition{
+				"can0":  {Type: NativeInterface, Bitrate: 500000},
+				"vcan0": {Type: VirtualInterface},
+			},
+		}: "",
+		&Config{
+			Interfaces: map[string]InterfaceDefinition{
+				"can0": {Type: NativeInterface, Device: "/dev/ttyS0"},
+			},
+		}: "Invalid interface definition 'can0': native interfaces have no device",
+		&Config{
+			Interfaces: map[string]InterfaceDefinition{
+				"vcan0": {Type: VirtualInterface, Bitrate: 500000},
+			},
+		}: "Invalid interface definition 'vcan0': virtual interfaces have no bitrate",
+		&Config{
+			Interfaces: map[string]InterfaceDefinition{
+				"can0 ": {Type: NativeInterface},
+			},
+		}: "Invalid interface definition 'can0 ': invalid interface name",
+		&Config{
+			Interfaces: map[string]InterfaceDefinition{
+				"can0": {Type: "ethernet"},
+			},
+		}: "Invalid interface definition 'can0': invalid type 'ethernet' .*",
+		&Config{
+			Interfaces: map[string]InterfaceDefinition{
+				"slcan0": {Type: SlcanInterface, Device: "/dev/ttyS0"},
 			},
 			Zones: map[string]ZoneDefinition{
 				"box": ZoneDefinition{
@@ -99,8 +166,8 @@ func (s *ConfigSuite) TestErrorChecking(c *C) {
 			},
 		}: "Invalid zone definition 'box': undefined CAN interface 'slcan1'",
 		&Config{
-			Interfaces: map[string]string{
-				"slcan0": "/dev/ttyS0",
+			Interfaces: map[string]InterfaceDefinition{
+				"slcan0": {Type: SlcanInterface, Device: "/dev/ttyS0"},
 			},
 			Zones: map[string]ZoneDefinition{
 				"box": ZoneDefinition{
@@ -110,8 +177,8 @@ func (s *ConfigSuite) TestErrorChecking(c *C) {
 			},
 		}: "Invalid zone definition 'box': invalid devices-id .*",
 		&Config{
-			Interfaces: map[string]string{
-				"slcan0": "/dev/ttyS0",
+			Interfaces: map[string]InterfaceDefinition{
+				"slcan0": {Type: SlcanInterface, Device: "/dev/ttyS0"},
 			},
 			Zones: map[string]ZoneDefinition{
 				"box": ZoneDefinition{
@@ -121,8 +188,8 @@ func (s *ConfigSuite) TestErrorChecking(c *C) {
 			},
 		}: "Invalid zone definition 'box': invalid devices-id .*",
 		&Config{
-			Interfaces: map[string]string{
-				"slcan0": "/dev/ttyS0",
+			Interfaces: map[string]InterfaceDefinition{
+				"slcan0": {Type: SlcanInterface, Device: "/dev/ttyS0"},
 			},
 			Zones: map[string]ZoneDefinition{
 				"box": ZoneDefinition{
@@ -135,6 +202,140 @@ func (s *ConfigSuite) TestErrorChecking(c *C) {
 				},
 			},
 		}: "Invalid zone definition 'box.*': devices ID 1 on interface 'slcan0' are used by zone 'box.*'",
+		&Config{
+			Interfaces: map[string]InterfaceDefinition{
+				"slcan0": {Type: SlcanInterface, Device: "/dev/ttyS0"},
+			},
+			Zones: map[string]ZoneDefinition{
+				"box": ZoneDefinition{
+					CANInterface: "slcan0",
+					DevicesID:    1,
+					MQTT:         &MQTTDefinition{Broker: "tcp://localhost:1883", QoS: 3},
+				},
+			},
+		}: "Invalid zone definition 'box': invalid mqtt qos 3 .*",
+		&Config{
+			Interfaces: map[string]InterfaceDefinition{
+				"slcan0": {Type: SlcanInterface, Device: "/dev/ttyS0"},
+			},
+			Zones: map[string]ZoneDefinition{
+				"box": ZoneDefinition{
+					CANInterface: "slcan0",
+					DevicesID:    1,
+					InfluxDB:     &InfluxDBDefinition{URL: "localhost:8086"},
+				},
+			},
+		}: "Invalid zone definition 'box': invalid influxdb url .*",
+		&Config{
+			Interfaces: map[string]InterfaceDefinition{
+				"slcan0": {Type: SlcanInterface, Device: "/dev/ttyS0"},
+			},
+			Zones: map[string]ZoneDefinition{
+				"box": ZoneDefinition{
+					CANInterface:     "slcan0",
+					DevicesID:        1,
+					ClimateLogFormat: "xml",
+				},
+			},
+		}: "Invalid zone definition 'box': invalid climate-log-format 'xml' .*",
+		&Config{
+			Interfaces: map[string]InterfaceDefinition{
+				"slcan0": {Type: SlcanInterface, Device: "/dev/ttyS0"},
+			},
+			Zones: map[string]ZoneDefinition{
+				"box": ZoneDefinition{
+					CANInterface:        "slcan0",
+					DevicesID:           1,
+					TemperatureAux:      1,
+					TemperatureAuxNames: []string{"nest", "foraging"},
+				},
+			},
+		}: "Invalid zone definition 'box': 2 temperature-aux-names for 1 temperature-aux",
+		&Config{
+			Interfaces: map[string]InterfaceDefinition{
+				"slcan0": {Type: SlcanInterface, Device: "/dev/ttyS0"},
+			},
+			Zones: map[string]ZoneDefinition{
+				"box": ZoneDefinition{
+					CANInterface: "slcan0",
+					DevicesID:    1,
+					SafeState:    SafeStateDefinition{Temperature: "cold"},
+				},
+			},
+		}: "Invalid zone definition 'box': invalid safe-state temperature 'cold' .*",
+		&Config{
+			Interfaces: map[string]InterfaceDefinition{
+				"slcan0": {Type: SlcanInterface, Device: "/dev/ttyS0"},
+			},
+			Zones: map[string]ZoneDefinition{
+				"box": ZoneDefinition{
+					CANInterface: "slcan0",
+					DevicesID:    1,
+					SafeState:    SafeStateDefinition{Temperature: SafeTemperatureOff, Wind: 120},
+				},
+			},
+		}: "Invalid zone definition 'box': invalid safe-state value 120 .*",
+		&Config{
+			Interfaces: map[string]InterfaceDefinition{
+				"slcan0": {Type: SlcanInterface, Device: "/dev/ttyS0"},
+			},
+			Zones: map[string]ZoneDefinition{
+				"box": ZoneDefinition{
+					CANInterface:   "slcan0",
+					DevicesID:      1,
+					NotusWindShare: 30,
+				},
+			},
+		}: "Invalid zone definition 'box': notus-wind-share requires has-notus-device",
+		&Config{
+			Interfaces: map[string]InterfaceDefinition{
+				"slcan0": {Type: SlcanInterface, Device: "/dev/ttyS0"},
+			},
+			Zones: map[string]ZoneDefinition{
+				"box": ZoneDefinition{
+					CANInterface:   "slcan0",
+					DevicesID:      1,
+					HasNotusDevice: true,
+					NotusWindShare: 130,
+				},
+			},
+		}: "Invalid zone definition 'box': invalid notus-wind-share 130 .*",
+		&Config{
+			Interfaces: map[string]InterfaceDefinition{
+				"slcan0": {Type: SlcanInterface, Device: "/dev/ttyS0"},
+			},
+			Zones: map[string]ZoneDefinition{
+				"box": ZoneDefinition{
+					CANInterface: "slcan0",
+					DevicesID:    1,
+					Channels:     ChannelsDefinition{Temperature: "regulated"},
+				},
+			},
+		}: "Invalid zone definition 'box': invalid temperature channel mode 'regulated' .*",
+		&Config{
+			Interfaces: map[string]InterfaceDefinition{
+				"slcan0": {Type: SlcanInterface, Device: "/dev/ttyS0"},
+			},
+			Zones: map[string]ZoneDefinition{
+				"box": ZoneDefinition{
+					CANInterface: "slcan0",
+					DevicesID:    1,
+					Channels:     ChannelsDefinition{Wind: MonitoredChannel},
+				},
+			},
+		}: "Invalid zone definition 'box': invalid wind channel mode 'monitored' \\(should be controlled or ignored\\)",
+		&Config{
+			MinimumFirmware: map[string]string{"zeus": "1.2", "celaeno": "1.1.3"},
+		}: "",
+		&Config{
+			MinimumFirmware: map[string]string{"hades": "1.2"},
+		}: "Invalid minimum-firmware: Unknown node class 'hades'",
+		&Config{
+			MinimumFirmware: map[string]string{"zeus": "1"},
+		}: "Invalid minimum-firmware for zeus: invalid firmware version '1'",
+		&Config{
+			EmulatedPlant: &PlantParameters{AmbientCoupling: 0.1},
+		}: "Invalid emulated-plant: invalid tank-autonomy 0s",
 	}
 
 	for config, expectedError := range testdata {
@@ -146,3 +347,8 @@ func (s *ConfigSuite) TestErrorChecking(c *C) {
 		}
 	}
 }
+
+func (s *ConfigSuite) TestNotusWindShareDefault(c *C) {
+	c.Check(ZoneDefinition{HasNotusDevice: true}.notusWindShare(), Equals, 50.0)
+	c.Check(ZoneDefinition{HasNotusDevice: true, NotusWindShare: 40}.notusWindShare(), Equals, 40.0)
+}
@@ -4,4 +4,10 @@ import "time"
 
 const (
 	FanResetWindow = 10 * time.Minute
+	// LinkMinBackoff and LinkMaxBackoff bound the delay between
+	// attempts to bring a CAN link back up.
+	LinkMinBackoff = 1 * time.Second
+	LinkMaxBackoff = 1 * time.Minute
+	// DefaultCANBitrate is the bitrate of Arke CAN buses.
+	DefaultCANBitrate = 250000
 )
@@ -10,9 +10,10 @@ import (
 )
 
 type Device struct {
-	Class arke.NodeClass
-	intf  socketcan.RawInterface
-	ID    arke.NodeID
+	Class     arke.NodeClass
+	intf      socketcan.RawInterface
+	ID        arke.NodeID
+	inventory *deviceInventory
 }
 
 func (d *Device) SendMessage(m arke.SendableMessage) error {
@@ -20,6 +21,9 @@ func (d *Device) SendMessage(m arke.SendableMessage) error {
 }
 
 func (d *Device) SendResetRequest() error {
+	if d.inventory != nil {
+		d.inventory.Reset(DeviceDefinition{Class: d.Class, ID: d.ID})
+	}
 	return d.intf.Send(arke.MakeResetRequest(d.Class, d.ID))
 }
 
@@ -31,12 +35,14 @@ var nameToNodeClass = map[string]arke.NodeClass{
 	"zeus":    arke.ZeusClass,
 	"celaeno": arke.CelaenoClass,
 	"helios":  arke.HeliosClass,
+	"notus":   arke.NotusClass,
 }
 
 var nodeClassToNode = map[arke.NodeClass]string{
 	arke.ZeusClass:    "Zeus",
 	arke.CelaenoClass: "Celaeno",
 	arke.HeliosClass:  "Helios",
+	arke.NotusClass:   "Notus",
 }
 
 func NameToArkeNodeClass(s string) (arke.NodeClass, error) {
@@ -0,0 +1,158 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/formicidae-tracker/libarke/src-go/arke"
+)
+
+// FirmwareVersion is the firmware version of an Arke device, as
+// reported in its heartbeats.
+type FirmwareVersion struct {
+	Major, Minor, Patch, Tweak uint8
+}
+
+// ParseFirmwareVersion parses a 'major.minor[.patch[.tweak]]' version.
+func ParseFirmwareVersion(s string) (FirmwareVersion, error) {
+	parts := strings.Split(strings.TrimPrefix(s, "v"), ".")
+	if len(parts) < 2 || len(parts) > 4 {
+		return FirmwareVersion{}, fmt.Errorf("invalid firmware version '%s'", s)
+	}
+	values := make([]uint8, 4)
+	for i, p := range parts {
+		v, err := strconv.ParseUint(p, 10, 8)
+		if err != nil {
+			return FirmwareVersion{}, fmt.Errorf("invalid firmware version '%s'", s)
+		}
+		values[i] = uint8(v)
+	}
+	return FirmwareVersion{values[0], values[1], values[2], values[3]}, nil
+}
+
+func heartbeatVersion(h *arke.HeartBeatData) FirmwareVersion {
+	return FirmwareVersion{h.MajorVersion, h.MinorVersion, h.PatchVersion, h.TweakVersion}
+}
+
+// Known returns false for heartbeats without a version.
+func (v FirmwareVersion) Known() bool {
+	return v != FirmwareVersion{}
+}
+
+// Before returns true if v is older than o.
+func (v FirmwareVersion) Before(o FirmwareVersion) bool {
+	a := []uint8{v.Major, v.Minor, v.Patch, v.Tweak}
+	b := []uint8{o.Major, o.Minor, o.Patch, o.Tweak}
+	for i := range a {
+		if a[i] != b[i] {
+			return a[i] < b[i]
+		}
+	}
+	return false
+}
+
+func (v FirmwareVersion) String() string {
+	if v.Tweak != 0 {
+		return fmt.Sprintf("%d.%d.%d.%d", v.Major, v.Minor, v.Patch, v.Tweak)
+	}
+	return fmt.Sprintf("%d.%d.%d", v.Major, v.Minor, v.Patch)
+}
+
+// DeviceRecord is what zeus knows about a device of a zone.
+type DeviceRecord struct {
+	Class     arke.NodeClass
+	ID        arke.NodeID
+	Version   FirmwareVersion
+	FirstSeen time.Time
+	LastSeen  time.Time
+	// Resets counts the reset requests sent to the device.
+	Resets int
+}
+
+// deviceInventory records the devices of a zone. It outlives the
+// climate runners, so it also holds the devices of past experiments.
+type deviceInventory struct {
+	mx      sync.Mutex
+	devices map[DeviceDefinition]*DeviceRecord
+	minimum map[arke.NodeClass]FirmwareVersion
+}
+
+func newDeviceInventory(minimum map[arke.NodeClass]FirmwareVersion) *deviceInventory {
+	return &deviceInventory{
+		devices: make(map[DeviceDefinition]*DeviceRecord),
+		minimum: minimum,
+	}
+}
+
+func (i *deviceInventory) record(d DeviceDefinition) *DeviceRecord {
+	r, ok := i.devices[d]
+	if ok == false {
+		r = &DeviceRecord{Class: d.Class, ID: d.ID}
+		i.devices[d] = r
+	}
+	return r
+}
+
+// Seen records a heartbeat of d. Heartbeats without a version keep
+// the last known one.
+func (i *deviceInventory) Seen(d DeviceDefinition, version FirmwareVersion, now time.Time) {
+	i.mx.Lock()
+	defer i.mx.Unlock()
+	r := i.record(d)
+	if r.FirstSeen.IsZero() == true {
+		r.FirstSeen = now
+	}
+	r.LastSeen = now
+	if version.Known() == true {
+		r.Version = version
+	}
+}
+
+// Reset records a reset request sent to d, which may not have been
+// seen yet.
+func (i *deviceInventory) Reset(d DeviceDefinition) {
+	i.mx.Lock()
+	defer i.mx.Unlock()
+	i.record(d).Resets++
+}
+
+// SetMinimum changes the minimum firmware versions.
+func (i *deviceInventory) SetMinimum(minimum map[arke.NodeClass]FirmwareVersion) {
+	i.mx.Lock()
+	defer i.mx.Unlock()
+	i.minimum = minimum
+}
+
+// Outdated returns the known version of d and the minimum version it
+// should run, if it is older.
+func (i *deviceInventory) Outdated(d DeviceDefinition) (version, minimum FirmwareVersion, outdated bool) {
+	i.mx.Lock()
+	defer i.mx.Unlock()
+	r, ok := i.devices[d]
+	if ok == false || r.Version.Known() == false {
+		return FirmwareVersion{}, FirmwareVersion{}, false
+	}
+	minimum, ok = i.minimum[d.Class]
+	return r.Version, minimum, ok && r.Version.Before(minimum)
+}
+
+// Devices returns the records of all devices, sorted by class and ID.
+func (i *deviceInventory) Devices() []DeviceRecord {
+	i.mx.Lock()
+	defer i.mx.Unlock()
+	res := make([]DeviceRecord, 0, len(i.devices))
+	for _, r := range i.devices {
+		res = append(res, *r)
+	}
+	sort.Slice(res, func(a, b int) bool {
+		if res[a].Class != res[b].Class {
+			return res[a].Class < res[b].Class
+		}
+		return res[a].ID < res[b].ID
+	})
+	return res
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"time"
+
+	"github.com/formicidae-tracker/libarke/src-go/arke"
+	. "gopkg.in/check.v1"
+)
+
+type DeviceInventorySuite struct{}
+
+var _ = Suite(&DeviceInventorySuite{})
+
+func (s *DeviceInventorySuite) TestFirmwareVersion(c *C) {
+	testdata := []struct {
+		Text     string
+		Expected FirmwareVersion
+		Message  string
+	}{
+		{"1.2", FirmwareVersion{Major: 1, Minor: 2}, ""},
+		{"v1.2.3", FirmwareVersion{Major: 1, Minor: 2, Patch: 3}, ""},
+		{"1.2.3.4", FirmwareVersion{1, 2, 3, 4}, ""},
+		{"1", FirmwareVersion{}, "invalid firmware version '1'"},
+		{"1.2.3.4.5", FirmwareVersion{}, "invalid firmware version '1.2.3.4.5'"},
+		{"1.256", FirmwareVersion{}, "invalid firmware version '1.256'"},
+	}
+	for _, d := range testdata {
+		v, err := ParseFirmwareVersion(d.Text)
+		if len(d.Message) > 0 {
+			c.Check(err, ErrorMatches, d.Message)
+			continue
+		}
+		c.Check(err, IsNil)
+		c.Check(v, Equals, d.Expected)
+	}
+
+	c.Check(FirmwareVersion{1, 2, 0, 0}.String(), Equals, "1.2.0")
+	c.Check(FirmwareVersion{1, 2, 3, 4}.String(), Equals, "1.2.3.4")
+	c.Check(FirmwareVersion{1, 1, 9, 0}.Before(FirmwareVersion{1, 2, 0, 0}), Equals, true)
+	c.Check(FirmwareVersion{1, 2, 0, 0}.Before(FirmwareVersion{1, 2, 0, 0}), Equals, false)
+	c.Check(FirmwareVersion{2, 0, 0, 0}.Before(FirmwareVersion{1, 2, 0, 0}), Equals, false)
+}
+
+func (s *DeviceInventorySuite) TestRecords(c *C) {
+	i := newDeviceInventory(map[arke.NodeClass]FirmwareVersion{
+		arke.CelaenoClass: {Major: 1, Minor: 1},
+	})
+	zeusDevice := DeviceDefinition{Class: arke.ZeusClass, ID: 1}
+	celaeno := DeviceDefinition{Class: arke.CelaenoClass, ID: 1}
+	start := time.Now()
+
+	i.Reset(celaeno)
+	i.Seen(celaeno, FirmwareVersion{Major: 1}, start)
+	i.Seen(celaeno, FirmwareVersion{}, start.Add(time.Second))
+	i.Seen(zeusDevice, FirmwareVersion{Major: 1, Minor: 4}, start.Add(2*time.Second))
+
+	c.Check(i.Devices(), DeepEquals, []DeviceRecord{
+		{
+			Class:     arke.CelaenoClass,
+			ID:        1,
+			Version:   FirmwareVersion{Major: 1},
+			FirstSeen: start,
+			LastSeen:  start.Add(time.Second),
+			Resets:    1,
+		},
+		{
+			Class:     arke.ZeusClass,
+			ID:        1,
+			Version:   FirmwareVersion{Major: 1, Minor: 4},
+			FirstSeen: start.Add(2 * time.Second),
+			LastSeen:  start.Add(2 * time.Second),
+		},
+	})
+
+	version, minimum, outdated := i.Outdated(celaeno)
+	c.Check(outdated, Equals, true)
+	c.Check(version, Equals, FirmwareVersion{Major: 1})
+	c.Check(minimum, Equals, FirmwareVersion{Major: 1, Minor: 1})
+	_, _, outdated = i.Outdated(zeusDevice)
+	c.Check(outdated, Equals, false)
+	_, _, outdated = i.Outdated(DeviceDefinition{Class: arke.HeliosClass, ID: 1})
+	c.Check(outdated, Equals, false)
+}
@@ -68,6 +68,7 @@ func (s *DeviceSuite) TestNameMapping(c *C) {
 		{Name: "celaeno", Class: arke.CelaenoClass},
 		{Name: "Helios", Class: arke.HeliosClass},
 		{Name: "helios", Class: arke.HeliosClass},
+		{Name: "Notus", Class: arke.NotusClass},
 	}
 
 	for _, d := range testdata {
@@ -5,6 +5,7 @@ import (
 	"io/ioutil"
 	"os"
 	"path/filepath"
+	"strings"
 	"sync"
 	"time"
 
@@ -30,16 +31,16 @@ func (s *FileClimateReporterSuite) TearDownSuite(c *C) {
 var _ = Suite(&FileClimateReporterSuite{})
 
 func (s *FileClimateReporterSuite) TestFileNameDoesNotOverwite(c *C) {
-	_, name1, err := NewFileClimateReporter(filepath.Join(s.TmpDir, "test.txt"), 0)
+	_, name1, err := NewFileClimateReporter(filepath.Join(s.TmpDir, "test.txt"), 0, LogRotationDefinition{})
 	c.Check(err, IsNil)
-	_, name2, err := NewFileClimateReporter(filepath.Join(s.TmpDir, "test.txt"), 0)
+	_, name2, err := NewFileClimateReporter(filepath.Join(s.TmpDir, "test.txt"), 0, LogRotationDefinition{})
 
 	c.Check(name1, Equals, filepath.Join(s.TmpDir, "test.txt"))
 	c.Check(name2, Equals, filepath.Join(s.TmpDir, "test.1.txt"))
 }
 
 func (s *FileClimateReporterSuite) TestFileNameWriting(c *C) {
-	fn, fname, err := NewFileClimateReporter(filepath.Join(s.TmpDir, "test.txt"), 3)
+	fn, fname, err := NewFileClimateReporter(filepath.Join(s.TmpDir, "test.txt"), 3, LogRotationDefinition{})
 	c.Assert(err, IsNil)
 
 	cr := zeus.ClimateReport{
@@ -56,99 +57,200 @@ func (s *FileClimateReporterSuite) TestFileNameWriting(c *C) {
 	<-ready
 
 	for i := 0; i < 4; i++ {
+		if i == 2 {
+			// targets and reports are not ordered between each other
+			// until the reports are written.
+			for j := 0; ; j++ {
+				data, _ := ioutil.ReadFile(fname)
+				if strings.Count(string(data), "\n") >= 5 {
+					break
+				}
+				c.Assert(j < 500, Equals, true, Commentf("reports were not written"))
+				time.Sleep(2 * time.Millisecond)
+			}
+			fn.(TargetReporter).TargetChannel() <- zeus.ClimateTarget{
+				Current: zeus.State{
+					Name:         "day",
+					Temperature:  26,
+					Humidity:     zeus.UndefinedHumidity,
+					Wind:         zeus.UndefinedWind,
+					VisibleLight: 100,
+					UVLight:      zeus.UndefinedLight,
+				},
+			}
+		}
 		cr.Time = fn.(*fileClimateReporter).Start.Add(time.Duration(i*333) * time.Millisecond)
 		fn.ReportChannel() <- cr
 	}
 	close(fn.ReportChannel())
+	close(fn.(TargetReporter).TargetChannel())
 	wg.Wait()
 
 	data, err := ioutil.ReadFile(fname)
 	c.Assert(err, IsNil)
 
-	c.Check(string(data), Equals, fmt.Sprintf(`# Starting date %s
-# Time (ms) Relative Humidity (%%) Temperature (°C) Aux 1 (°C) Aux 2 (°C) Aux 3 (°C)
-0 50.00 21.00 21.00 21.00 21.00
-333 50.00 21.00 21.00 21.00 21.00
-666 50.00 21.00 21.00 21.00 21.00
-999 50.00 21.00 21.00 21.00 21.00
+	c.Check(string(data), Equals, fmt.Sprintf(`# Zeus climate log v2
+# Starting date %s
+# Time (ms) Relative Humidity (%%) Temperature (°C) Aux 1 (°C) Aux 2 (°C) Aux 3 (°C) Target Temperature (°C) Target Humidity (%%) Target Wind (%%) Target Visible Light (%%) Target UV Light (%%) Dew Point (°C) Absolute Humidity (g/m³) VPD (kPa) Target State
+0 50.00 21.00 21.00 21.00 21.00 -Inf -Inf -Inf -Inf -Inf 10.18 9.14 1.241 ""
+333 50.00 21.00 21.00 21.00 21.00 -Inf -Inf -Inf -Inf -Inf 10.18 9.14 1.241 ""
+666 50.00 21.00 21.00 21.00 21.00 26.00 -Inf -Inf 100.00 -Inf 10.18 9.14 1.241 "day"
+999 50.00 21.00 21.00 21.00 21.00 26.00 -Inf -Inf 100.00 -Inf 10.18 9.14 1.241 "day"
 `, fn.(*fileClimateReporter).Start.Format(time.RFC3339Nano)))
 
+	entries, err := zeus.ReadClimateLog(fname)
+	c.Assert(err, IsNil)
+	c.Assert(len(entries), Equals, 4)
+	c.Check(entries[0].Target, DeepEquals, &zeus.State{
+		Temperature:  zeus.UndefinedTemperature,
+		Humidity:     zeus.UndefinedHumidity,
+		Wind:         zeus.UndefinedWind,
+		VisibleLight: zeus.UndefinedLight,
+		UVLight:      zeus.UndefinedLight,
+	})
+	c.Check(entries[3].Target, DeepEquals, &zeus.State{
+		Name:         "day",
+		Temperature:  26,
+		Humidity:     zeus.UndefinedHumidity,
+		Wind:         zeus.UndefinedWind,
+		VisibleLight: 100,
+		UVLight:      zeus.UndefinedLight,
+	})
+	c.Check(entries[3].Temperatures, DeepEquals, cr.Temperatures)
 }
 
-func (s *FileClimateReporterSuite) TestFileReading(c *C) {
-	tmpdir, err := ioutil.TempDir("", "zeus-read-season-file")
-	c.Assert(err, IsNil)
-	defer os.RemoveAll(tmpdir)
-	start := time.Now().Round(0) // removes monotonic clocks value for deep equals
-	startString := start.Format(time.RFC3339Nano)
-	testdata := []struct {
-		Content  string
-		Expected []zeus.ClimateReport
-		Error    string
-	}{
-		{
-			Content: "# Starting date " + startString + `
-# Time (ms) Relative Humidity (%) Temperature (°C)
-0 50.0 21.23
-502 51.3 24.5
-`,
-			Expected: []zeus.ClimateReport{
-				zeus.ClimateReport{Time: start, Humidity: 50.0, Temperatures: []zeus.Temperature{21.23}},
-				zeus.ClimateReport{Time: start.Add(502 * time.Millisecond), Humidity: 51.3, Temperatures: []zeus.Temperature{24.5}},
-			},
-		},
-		{
-			Content: "# Starting date " + startString + `
-# Time (ms) Relative Humidity (%) Temperature (°C) Aux 1 (°C)
-0 50.0 21.23 13.2
-502 51.3 24.5 15.7
-`,
-			Expected: []zeus.ClimateReport{
-				zeus.ClimateReport{Time: start, Humidity: 50.0, Temperatures: []zeus.Temperature{21.23, 13.2}},
-				zeus.ClimateReport{Time: start.Add(502 * time.Millisecond), Humidity: 51.3, Temperatures: []zeus.Temperature{24.5, 15.7}},
-			},
-		},
-		{
-			Content: "# Starting date " + startString + `fo
-# Time (ms) Relative Humidity (%) Temperature (°C) Aux 1 (°C)
-0 50.0 21.23 13.2
-502 51.3 24.5 15.7
-`,
-			Error: "parsing time \".*\": extra text: .*",
-		},
-		{
-			Content: "# Starting date " + startString + `
-# Time (ms) Relative Humidity (%)
-0 50.0 21.23 13.2
-502 51.3 24.5 15.7
-`,
-			Error: "invalid header .*",
-		},
-		{
-			Content: "# Starting date " + startString + `
-# Time (ms) Relative Humidity (%) Temperature (°C) Aux 1 (°C) Aux 2 (°C)
-0 50.0 21.23 13.2 34.1
-502 51.3 24.5 15.7
-`,
-			Error: "invalid line .*: too few values",
-			Expected: []zeus.ClimateReport{
-				zeus.ClimateReport{Time: start, Humidity: 50.0, Temperatures: []zeus.Temperature{21.23, 13.2, 34.1}},
-			},
-		},
+func (s *FileClimateReporterSuite) TestStructuredFormats(c *C) {
+	target := zeus.State{
+		Name:         "day, bright",
+		Temperature:  26,
+		Humidity:     zeus.UndefinedHumidity,
+		Wind:         zeus.UndefinedWind,
+		VisibleLight: 100,
+		UVLight:      zeus.UndefinedLight,
 	}
-	filename := filepath.Join(tmpdir, "log.txt")
-	for _, d := range testdata {
-		err := ioutil.WriteFile(filename, []byte(d.Content), 0644)
-		if c.Check(err, IsNil) == false {
-			continue
+	for _, format := range []string{zeus.CSVClimateLog, zeus.JSONLClimateLog} {
+		comment := Commentf("format: %s", format)
+		cr, filename, err := NewStructuredClimateReporter(filepath.Join(s.TmpDir, "box.climate."+format),
+			zeus.ClimateLogHeader{
+				Format:         format,
+				Zone:           "box",
+				Host:           "foo",
+				ZeusVersion:    "v0.5.0",
+				AuxCount:       2,
+				AuxNames:       []string{"nest"},
+				SeasonFileHash: "abcdef",
+			}, LogRotationDefinition{})
+		c.Assert(err, IsNil, comment)
+
+		ready := make(chan struct{})
+		done := make(chan struct{})
+		go func() {
+			cr.Report(ready)
+			close(done)
+		}()
+		<-ready
+
+		start := time.Date(2023, 3, 1, 12, 0, 0, 0, time.UTC)
+		cr.(TargetReporter).TargetChannel() <- zeus.ClimateTarget{Current: target}
+		expected := []zeus.ClimateReport{
+			{Time: start, Humidity: 55.5, Temperatures: []zeus.Temperature{21.23, 22, zeus.UndefinedTemperature}},
+			{Time: start.Add(500 * time.Millisecond), Humidity: zeus.UndefinedHumidity, Temperatures: []zeus.Temperature{21.5, 22.5, 19}},
+		}
+		for _, r := range expected {
+			cr.ReportChannel() <- r
 		}
-		result, err := ReadClimateFile(filename)
-		if len(d.Error) > 0 {
-			c.Check(err, ErrorMatches, d.Error)
-		} else {
-			c.Check(err, IsNil)
+		close(cr.ReportChannel())
+		close(cr.(TargetReporter).TargetChannel())
+		<-done
+
+		data, err := ioutil.ReadFile(filename)
+		c.Assert(err, IsNil, comment)
+		firstLine := strings.SplitN(string(data), "\n", 2)[0]
+		c.Check(firstLine, Matches, `(# )?\{"format":"`+format+`","zone":"box","host":"foo","zeus_version":"v0.5.0","start":"[^"]+","aux_count":2,"aux_names":\["nest","Aux 2"\],"season_file_hash":"abcdef"\}`, comment)
+		// the CSV column, or the only JSON entry with a defined humidity.
+		c.Check(strings.Count(string(data), "dew_point"), Equals, 1, comment)
+
+		entries, err := zeus.ReadClimateLog(filename)
+		c.Assert(err, IsNil, comment)
+		c.Assert(len(entries), Equals, len(expected), comment)
+		for i, e := range entries {
+			c.Check(e.Time.Equal(expected[i].Time), Equals, true, comment)
+			c.Check(e.Humidity, Equals, expected[i].Humidity, comment)
+			c.Check(e.Temperatures, DeepEquals, expected[i].Temperatures, comment)
+			c.Check(e.Target, DeepEquals, &target, comment)
 		}
-		c.Check(result, DeepEquals, d.Expected)
 
+		reports, err := zeus.ReadClimateFile(filename)
+		c.Assert(err, IsNil, comment)
+		c.Check(len(reports), Equals, len(expected), comment)
+	}
+}
+
+func (s *FileClimateReporterSuite) TestResumesExistingLog(c *C) {
+	filename := filepath.Join(s.TmpDir, "resumed.txt")
+	header := zeus.ClimateLogHeader{Format: zeus.TextClimateLog, AuxCount: 1}
+	write := func(r *fileClimateReporter, t time.Time) {
+		ready := make(chan struct{})
+		done := make(chan struct{})
+		go func() {
+			r.Report(ready)
+			close(done)
+		}()
+		<-ready
+		r.ReportChannel() <- zeus.ClimateReport{Time: t, Humidity: 50, Temperatures: []zeus.Temperature{21, 22}}
+		close(r.ReportChannel())
+		close(r.TargetChannel())
+		<-done
+	}
+
+	first, fname, err := openClimateLog(filename, header, LogRotationDefinition{}, true)
+	c.Assert(err, IsNil)
+	c.Check(fname, Equals, filename)
+	write(first, first.Start)
+
+	second, fname, err := openClimateLog(filename, header, LogRotationDefinition{}, true)
+	c.Assert(err, IsNil)
+	c.Check(fname, Equals, filename)
+	c.Check(second.Start.Equal(first.Start), Equals, true)
+	write(second, first.Start.Add(time.Hour))
+
+	entries, err := zeus.ReadClimateLog(filename)
+	c.Assert(err, IsNil)
+	c.Assert(len(entries), Equals, 2)
+	c.Check(entries[1].Time.Sub(entries[0].Time), Equals, time.Hour)
+
+	header.AuxCount = 2
+	_, fname, err = openClimateLog(filename, header, LogRotationDefinition{}, true)
+	c.Assert(err, IsNil)
+	c.Check(fname, Equals, filepath.Join(s.TmpDir, "resumed.1.txt"))
+}
+
+func (s *FileClimateReporterSuite) TestReceivesTargetsUntilClosed(c *C) {
+	cr, _, err := NewFileClimateReporter(filepath.Join(s.TmpDir, "targets.txt"), 0, LogRotationDefinition{})
+	c.Assert(err, IsNil)
+	ready := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		cr.Report(ready)
+		close(done)
+	}()
+	<-ready
+
+	close(cr.ReportChannel())
+	// more targets than the channel buffers, as the runner may still
+	// forward them once the reports are closed.
+	sent := make(chan struct{})
+	go func() {
+		for i := 0; i < 5; i++ {
+			cr.(TargetReporter).TargetChannel() <- zeus.ClimateTarget{}
+		}
+		close(cr.(TargetReporter).TargetChannel())
+		close(sent)
+	}()
+	select {
+	case <-sent:
+	case <-time.After(time.Second):
+		c.Fatalf("targets are not received after the reports are closed")
 	}
+	<-done
 }
@@ -0,0 +1,235 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/formicidae-tracker/olympus/pkg/tm"
+	"github.com/formicidae-tracker/zeus/internal/zeus"
+	"github.com/sirupsen/logrus"
+)
+
+// influxReporter writes climate reports, targets and alarm events of
+// a zone in InfluxDB line protocol to an HTTP write endpoint. Lines
+// are sent in batches every period. Failed batches are retried with
+// the next one, up to influxMaxPendingLines.
+type influxReporter struct {
+	zoneStream
+
+	url, token string
+	tags       string
+	period     time.Duration
+	client     *http.Client
+
+	pending []string
+	batches chan []string
+	done    chan struct{}
+
+	log *logrus.Entry
+}
+
+const influxMaxPendingLines = 10000
+
+var (
+	influxMeasurementEscaper = strings.NewReplacer(`,`, `\,`, ` `, `\ `)
+	influxTagEscaper         = strings.NewReplacer(`,`, `\,`, `=`, `\=`, ` `, `\ `)
+	influxStringEscaper      = strings.NewReplacer(`\`, `\\`, `"`, `\"`)
+)
+
+type influxLine struct {
+	measurement string
+	tags        []string
+	fields      []string
+}
+
+func (l *influxLine) tag(key, value string) {
+	if len(value) == 0 {
+		return
+	}
+	l.tags = append(l.tags, influxTagEscaper.Replace(key)+"="+influxTagEscaper.Replace(value))
+}
+
+func (l *influxLine) float(key string, u zeus.BoundedUnit) {
+	l.value(key, u.Value())
+}
+
+func (l *influxLine) value(key string, v float64) {
+	if math.IsNaN(v) || math.IsInf(v, 0) {
+		return
+	}
+	l.fields = append(l.fields, influxTagEscaper.Replace(key)+"="+strconv.FormatFloat(v, 'f', -1, 64))
+}
+
+func (l *influxLine) boolean(key string, v bool) {
+	l.fields = append(l.fields, influxTagEscaper.Replace(key)+"="+strconv.FormatBool(v))
+}
+
+func (l *influxLine) text(key, v string) {
+	l.fields = append(l.fields, influxTagEscaper.Replace(key)+`="`+influxStringEscaper.Replace(v)+`"`)
+}
+
+// format returns the line, or an empty string if it has no field.
+func (l *influxLine) format(commonTags string, t time.Time) string {
+	if len(l.fields) == 0 {
+		return ""
+	}
+	tags := commonTags
+	if len(l.tags) > 0 {
+		tags += "," + strings.Join(l.tags, ",")
+	}
+	return fmt.Sprintf("%s%s %s %d",
+		influxMeasurementEscaper.Replace(l.measurement),
+		tags,
+		strings.Join(l.fields, ","),
+		t.UnixNano())
+}
+
+func (r *influxReporter) push(l *influxLine, t time.Time) {
+	line := l.format(r.tags, t)
+	if len(line) == 0 {
+		return
+	}
+	r.pending = append(r.pending, line)
+}
+
+func (r *influxReporter) report(report zeus.ClimateReport) {
+	l := &influxLine{measurement: "zeus_climate"}
+	l.float("humidity", report.Humidity)
+	for i, t := range report.Temperatures {
+		if i == 0 {
+			l.float("temperature", t)
+		} else {
+			l.float(fmt.Sprintf("temperature_aux%d", i), t)
+		}
+	}
+	l.float("dew_point", report.DewPoint())
+	l.value("absolute_humidity", report.AbsoluteHumidity())
+	l.value("vpd", report.VaporPressureDeficit())
+	r.push(l, report.Time)
+}
+
+func (r *influxReporter) target(target zeus.ClimateTarget) {
+	l := &influxLine{measurement: "zeus_target"}
+	l.tag("state", target.Current.Name)
+	l.float("temperature", target.Current.Temperature)
+	l.float("humidity", target.Current.Humidity)
+	l.float("wind", target.Current.Wind)
+	l.float("visible_light", target.Current.VisibleLight)
+	l.float("uv_light", target.Current.UVLight)
+	r.push(l, time.Now())
+}
+
+func (r *influxReporter) alarm(event zeus.AlarmEvent) {
+	l := &influxLine{measurement: "zeus_alarm"}
+	l.tag("alarm", event.Identifier)
+	l.tag("level", event.Flags.Level())
+	l.boolean("active", event.Status == zeus.AlarmOn)
+	l.text("description", event.Description)
+	r.push(l, event.Time)
+}
+
+// flush hands pending lines to the writer, unless it is still busy
+// with a previous batch.
+func (r *influxReporter) flush() {
+	if len(r.pending) == 0 {
+		return
+	}
+	select {
+	case r.batches <- r.pending:
+		r.pending = nil
+	default:
+	}
+}
+
+func (r *influxReporter) write(lines []string) error {
+	req, err := http.NewRequest(http.MethodPost, r.url, strings.NewReader(strings.Join(lines, "\n")+"\n"))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "text/plain; charset=utf-8")
+	if len(r.token) > 0 {
+		req.Header.Set("Authorization", "Token "+r.token)
+	}
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("influxdb responded %s: %s", resp.Status, bytes.TrimSpace(body))
+	}
+	return nil
+}
+
+func (r *influxReporter) writeBatches() {
+	defer close(r.done)
+	var retry []string
+	for batch := range r.batches {
+		lines := append(retry, batch...)
+		if len(lines) > influxMaxPendingLines {
+			r.log.WithField("count", len(lines)-influxMaxPendingLines).Warn("dropping lines")
+			lines = lines[len(lines)-influxMaxPendingLines:]
+		}
+		if err := r.write(lines); err != nil {
+			r.log.WithError(err).WithField("lines", len(lines)).Error("could not write to influxdb")
+			retry = lines
+		} else {
+			retry = nil
+		}
+	}
+}
+
+func (r *influxReporter) Report(ready chan<- struct{}) {
+	go r.writeBatches()
+	ticker := time.NewTicker(r.period)
+	defer ticker.Stop()
+	close(ready)
+
+	r.stream(r, ticker.C, r.flush)
+
+	if len(r.pending) > 0 {
+		r.batches <- r.pending
+		r.pending = nil
+	}
+	close(r.batches)
+	<-r.done
+}
+
+func NewInfluxDBReporter(zone string, d InfluxDBDefinition) (*influxReporter, error) {
+	if err := d.check(); err != nil {
+		return nil, err
+	}
+	hostname, err := os.Hostname()
+	if err != nil {
+		return nil, err
+	}
+	period := d.FlushPeriod
+	if period == 0 {
+		period = 10 * time.Second
+	}
+
+	common := &influxLine{}
+	common.tag("host", hostname)
+	common.tag("zone", zone)
+
+	return &influxReporter{
+		zoneStream: newZoneStream(),
+		url:        d.URL,
+		token:      d.Token,
+		tags:       "," + strings.Join(common.tags, ","),
+		period:     period,
+		client:     &http.Client{Timeout: 10 * time.Second},
+		batches:    make(chan []string, 1),
+		done:       make(chan struct{}),
+		log:        tm.NewLogger(path.Join("zone", zone, "influxdb")),
+	}, nil
+}
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/formicidae-tracker/zeus/internal/zeus"
+	. "gopkg.in/check.v1"
+)
+
+type InfluxReporterSuite struct {
+	server *httptest.Server
+
+	mx       sync.Mutex
+	lines    []string
+	failures int
+	auth     string
+}
+
+var _ = Suite(&InfluxReporterSuite{})
+
+func (s *InfluxReporterSuite) SetUpTest(c *C) {
+	s.lines = nil
+	s.failures = 0
+	s.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.mx.Lock()
+		defer s.mx.Unlock()
+		if s.failures > 0 {
+			s.failures -= 1
+			http.Error(w, "unavailable", http.StatusServiceUnavailable)
+			return
+		}
+		s.auth = r.Header.Get("Authorization")
+		data, _ := io.ReadAll(r.Body)
+		s.lines = append(s.lines, strings.Split(strings.TrimSpace(string(data)), "\n")...)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+}
+
+func (s *InfluxReporterSuite) TearDownTest(c *C) {
+	s.server.Close()
+}
+
+func (s *InfluxReporterSuite) TestWritesLineProtocol(c *C) {
+	hostname, err := os.Hostname()
+	c.Assert(err, IsNil)
+	s.failures = 1
+
+	r, err := NewInfluxDBReporter("box", InfluxDBDefinition{
+		URL:         s.server.URL + "/api/v2/write?org=lab&bucket=zeus",
+		Token:       "secret",
+		FlushPeriod: 10 * time.Millisecond,
+	})
+	c.Assert(err, IsNil)
+
+	ready := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		r.Report(ready)
+		close(done)
+	}()
+	<-ready
+
+	t := time.Unix(1700000000, 0)
+	r.ReportChannel() <- zeus.ClimateReport{
+		Time:         t,
+		Humidity:     55.5,
+		Temperatures: []zeus.Temperature{22, 21.25},
+	}
+	// first batch will fail and should be retried with the next one
+	time.Sleep(50 * time.Millisecond)
+	r.TargetChannel() <- zeus.ClimateTarget{
+		Current: zeus.State{
+			Name:         "night time",
+			Temperature:  20,
+			Humidity:     zeus.UndefinedHumidity,
+			Wind:         zeus.UndefinedWind,
+			VisibleLight: 0,
+			UVLight:      zeus.UndefinedLight,
+		},
+	}
+	r.AlarmChannel() <- zeus.AlarmEvent{
+		Identifier:  "climate.humidity_out_of_bound",
+		Description: `humidity "out" of bound`,
+		Status:      zeus.AlarmOff,
+		Time:        t,
+	}
+	close(r.ReportChannel())
+	close(r.TargetChannel())
+	close(r.AlarmChannel())
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		c.Fatalf("reporter did not terminate")
+	}
+
+	tags := fmt.Sprintf("host=%s,zone=box", hostname)
+	s.mx.Lock()
+	defer s.mx.Unlock()
+	c.Check(s.auth, Equals, "Token secret")
+	c.Assert(len(s.lines), Equals, 3)
+	// items of different kinds are not ordered
+	sort.Strings(s.lines)
+	c.Check(s.lines[0], Equals, `zeus_alarm,`+tags+`,alarm=climate.humidity_out_of_bound,level=warning active=false,description="humidity \"out\" of bound" 1700000000000000000`)
+	c.Check(s.lines[1], Equals, "zeus_climate,"+tags+" humidity=55.5,temperature=22,temperature_aux1=21.25,dew_point=12.68389891129012,absolute_humidity=10.75088236617503,vpd=1.1741555376650596 1700000000000000000")
+	c.Check(s.lines[2], Matches, `zeus_target,`+tags+`,state=night\\ time temperature=20,visible_light=0 [0-9]+`)
+}
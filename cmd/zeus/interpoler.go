@@ -24,6 +24,7 @@ type interpoler struct {
 	Period time.Duration
 
 	logger     *logrus.Entry
+	zone       string
 	name       string
 	interpoler zeus.ClimateInterpoler
 	quit       chan struct{}
@@ -91,6 +92,7 @@ func (i *interpoler) Interpolate(ready chan<- struct{}) {
 			i.logger.Info("stopping interpolation loop")
 			return
 		case now := <-timer.C:
+			instruments.interpolerTick(i.zone)
 			new, nextTime, next := i.interpoler.CurrentInterpolation(now)
 			newIsTransition := new.End() != nil
 
@@ -110,18 +112,21 @@ func (i *interpoler) Interpolate(ready chan<- struct{}) {
 	}
 }
 
-func NewInterpoler(name string, states []zeus.State, transitions []zeus.Transition) (Interpoler, error) {
+// NewInterpoler creates an Interpoler computing day-relative
+// transitions from reference.
+func NewInterpoler(name string, states []zeus.State, transitions []zeus.Transition, reference time.Time) (Interpoler, error) {
 	hostname, err := os.Hostname()
 	if err != nil {
 		return nil, err
 	}
 	logger := tm.NewLogger(path.Join("zone", name, "climate"))
-	i, err := zeus.NewClimateInterpoler(states, transitions, time.Now().UTC())
+	i, err := zeus.NewClimateInterpoler(states, transitions, reference.UTC())
 	if err != nil {
 		return nil, err
 	}
 
 	return &interpoler{
+		zone:       name,
 		name:       path.Join(hostname, "zone", name),
 		interpoler: i,
 		logger:     logger,
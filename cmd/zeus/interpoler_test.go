@@ -27,7 +27,7 @@ func (s *InterpolationManagerSuite) TestInterpolationReportsUndefined(c *C) {
 		UVLight:      zeus.UndefinedLight,
 	}}
 
-	i, err := NewInterpoler("test-zone", states, []zeus.Transition{})
+	i, err := NewInterpoler("test-zone", states, []zeus.Transition{}, time.Now())
 	c.Assert(err, IsNil)
 
 	_, hook := test.NewNullLogger()
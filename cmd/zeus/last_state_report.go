@@ -1,12 +1,15 @@
 package main
 
 import (
+	"fmt"
+
 	"github.com/barkimedes/go-deepcopy"
 	"github.com/formicidae-tracker/zeus/internal/zeus"
 	"github.com/formicidae-tracker/zeus/pkg/zeuspb"
 )
 
 type lastStateReporter struct {
+	zone     string
 	requests chan chan *zeuspb.ZoneStatus
 
 	targets chan zeus.ClimateTarget
@@ -16,7 +19,13 @@ type lastStateReporter struct {
 }
 
 func (r *lastStateReporter) Report(ready chan<- struct{}) {
-	defer close(r.requests)
+	defer func() {
+		close(r.requests)
+		zoneTemperatureMetric.DeleteMatching("zone", r.zone)
+		zoneHumidityMetric.DeleteMatching("zone", r.zone)
+		zoneDerivedMetric.DeleteMatching("zone", r.zone)
+		zoneTargetMetric.DeleteMatching("zone", r.zone)
+	}()
 	close(ready)
 	for {
 		select {
@@ -25,6 +34,7 @@ func (r *lastStateReporter) Report(ready chan<- struct{}) {
 				r.targets = nil
 			} else {
 				r.last.Target = target.Current.AsPbTarget()
+				r.exportTarget(target.Current)
 			}
 		case report, ok := <-r.reports:
 			if ok == false {
@@ -34,6 +44,10 @@ func (r *lastStateReporter) Report(ready chan<- struct{}) {
 				if len(report.Temperatures) > 0 {
 					r.last.Temperature = zeus.AsFloat32Pointer(report.Temperatures[0])
 				}
+				r.last.DewPoint = zeus.AsFloat32Pointer(report.DewPoint())
+				r.last.AbsoluteHumidity = zeus.Float32Pointer(report.AbsoluteHumidity())
+				r.last.VaporPressureDeficit = zeus.Float32Pointer(report.VaporPressureDeficit())
+				r.exportReport(report)
 			}
 		case req := <-r.requests:
 			req <- deepcopy.MustAnything(&r.last).(*zeuspb.ZoneStatus)
@@ -45,8 +59,31 @@ func (r *lastStateReporter) Report(ready chan<- struct{}) {
 	}
 }
 
-func NewLastStateReporter() *lastStateReporter {
+func (r *lastStateReporter) exportReport(report zeus.ClimateReport) {
+	zoneHumidityMetric.Set(report.Humidity.Value(), r.zone)
+	zoneDerivedMetric.Set(report.DewPoint().Value(), r.zone, "dew_point_celsius")
+	zoneDerivedMetric.Set(report.AbsoluteHumidity(), r.zone, "absolute_humidity_grams_per_cubic_meter")
+	zoneDerivedMetric.Set(report.VaporPressureDeficit(), r.zone, "vapor_pressure_deficit_kilopascals")
+	for i, t := range report.Temperatures {
+		sensor := "main"
+		if i > 0 {
+			sensor = fmt.Sprintf("aux%d", i)
+		}
+		zoneTemperatureMetric.Set(t.Value(), r.zone, sensor)
+	}
+}
+
+func (r *lastStateReporter) exportTarget(s zeus.State) {
+	zoneTargetMetric.Set(s.Temperature.Value(), r.zone, "temperature")
+	zoneTargetMetric.Set(s.Humidity.Value(), r.zone, "humidity")
+	zoneTargetMetric.Set(s.Wind.Value(), r.zone, "wind")
+	zoneTargetMetric.Set(s.VisibleLight.Value(), r.zone, "visible_light")
+	zoneTargetMetric.Set(s.UVLight.Value(), r.zone, "uv_light")
+}
+
+func NewLastStateReporter(zone string) *lastStateReporter {
 	return &lastStateReporter{
+		zone:     zone,
 		requests: make(chan chan *zeuspb.ZoneStatus),
 		reports:  make(chan zeus.ClimateReport, 10),
 		targets:  make(chan zeus.ClimateTarget, 1),
@@ -0,0 +1,234 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"net"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+type metricKind string
+
+const (
+	gaugeMetric   metricKind = "gauge"
+	counterMetric metricKind = "counter"
+)
+
+type metricSample struct {
+	labels []string
+	value  float64
+}
+
+// metricVec is a family of samples sharing the same name and label
+// names, exported in the prometheus text exposition format.
+type metricVec struct {
+	name   string
+	help   string
+	kind   metricKind
+	labels []string
+
+	mx      sync.Mutex
+	samples map[string]*metricSample
+}
+
+type metricsRegistry struct {
+	mx       sync.RWMutex
+	families []*metricVec
+}
+
+var metrics = &metricsRegistry{}
+
+func (r *metricsRegistry) register(v *metricVec) *metricVec {
+	r.mx.Lock()
+	defer r.mx.Unlock()
+	r.families = append(r.families, v)
+	return v
+}
+
+func newGaugeVec(name, help string, labels ...string) *metricVec {
+	return metrics.register(&metricVec{
+		name:    name,
+		help:    help,
+		kind:    gaugeMetric,
+		labels:  labels,
+		samples: make(map[string]*metricSample),
+	})
+}
+
+func newCounterVec(name, help string, labels ...string) *metricVec {
+	return metrics.register(&metricVec{
+		name:    name,
+		help:    help,
+		kind:    counterMetric,
+		labels:  labels,
+		samples: make(map[string]*metricSample),
+	})
+}
+
+func (v *metricVec) sample(values []string) *metricSample {
+	if len(values) != len(v.labels) {
+		panic(fmt.Sprintf("metric %s: expected %d label values, got %d", v.name, len(v.labels), len(values)))
+	}
+	key := strings.Join(values, "\x00")
+	s, ok := v.samples[key]
+	if ok == false {
+		s = &metricSample{labels: append([]string(nil), values...)}
+		v.samples[key] = s
+	}
+	return s
+}
+
+// Set sets the value of a sample. Undefined values removes the
+// sample.
+func (v *metricVec) Set(value float64, labels ...string) {
+	if math.IsInf(value, -1) || math.IsNaN(value) {
+		v.Delete(labels...)
+		return
+	}
+	v.mx.Lock()
+	defer v.mx.Unlock()
+	v.sample(labels).value = value
+}
+
+func (v *metricVec) Add(delta float64, labels ...string) {
+	v.mx.Lock()
+	defer v.mx.Unlock()
+	v.sample(labels).value += delta
+}
+
+func (v *metricVec) Delete(labels ...string) {
+	v.mx.Lock()
+	defer v.mx.Unlock()
+	delete(v.samples, strings.Join(labels, "\x00"))
+}
+
+// DeleteMatching removes all samples whose label named label has
+// the given value.
+func (v *metricVec) DeleteMatching(label, value string) {
+	idx := -1
+	for i, l := range v.labels {
+		if l == label {
+			idx = i
+		}
+	}
+	if idx < 0 {
+		return
+	}
+	v.mx.Lock()
+	defer v.mx.Unlock()
+	for key, s := range v.samples {
+		if s.labels[idx] == value {
+			delete(v.samples, key)
+		}
+	}
+}
+
+// Value returns the current value of a sample, mainly for test
+// purposes.
+func (v *metricVec) Value(labels ...string) (float64, bool) {
+	v.mx.Lock()
+	defer v.mx.Unlock()
+	s, ok := v.samples[strings.Join(labels, "\x00")]
+	if ok == false {
+		return 0, false
+	}
+	return s.value, true
+}
+
+var labelValueEscaper = strings.NewReplacer(`\`, `\\`, "\n", `\n`, `"`, `\"`)
+
+func (v *metricVec) write(w io.Writer) error {
+	v.mx.Lock()
+	samples := make([]metricSample, 0, len(v.samples))
+	for _, s := range v.samples {
+		samples = append(samples, *s)
+	}
+	v.mx.Unlock()
+
+	sort.Slice(samples, func(i, j int) bool {
+		return strings.Join(samples[i].labels, "\x00") < strings.Join(samples[j].labels, "\x00")
+	})
+
+	if _, err := fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", v.name, v.help, v.name, v.kind); err != nil {
+		return err
+	}
+	for _, s := range samples {
+		labels := make([]string, len(v.labels))
+		for i, l := range v.labels {
+			labels[i] = fmt.Sprintf(`%s="%s"`, l, labelValueEscaper.Replace(s.labels[i]))
+		}
+		labelString := ""
+		if len(labels) > 0 {
+			labelString = "{" + strings.Join(labels, ",") + "}"
+		}
+		_, err := fmt.Fprintf(w, "%s%s %s\n", v.name, labelString,
+			strconv.FormatFloat(s.value, 'g', -1, 64))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *metricsRegistry) Write(w io.Writer) error {
+	r.mx.RLock()
+	defer r.mx.RUnlock()
+	for _, f := range r.families {
+		if err := f.write(w); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *metricsRegistry) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	r.Write(w)
+}
+
+// serveMetrics serves the metrics on address until quit is closed.
+func serveMetrics(address string, quit <-chan struct{}) (<-chan error, error) {
+	l, err := net.Listen("tcp", address)
+	if err != nil {
+		return nil, err
+	}
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", metrics)
+	server := &http.Server{Handler: mux}
+	errs := make(chan error, 1)
+	go func() {
+		<-quit
+		server.Close()
+	}()
+	go func() {
+		defer close(errs)
+		if err := server.Serve(l); err != http.ErrServerClosed {
+			errs <- err
+		}
+	}()
+	return errs, nil
+}
+
+var (
+	zoneTemperatureMetric = newGaugeVec("zeus_zone_temperature_celsius",
+		"Last temperature reported in a zone.", "zone", "sensor")
+	zoneHumidityMetric = newGaugeVec("zeus_zone_humidity_percent",
+		"Last relative humidity reported in a zone.", "zone")
+	zoneDerivedMetric = newGaugeVec("zeus_zone_derived",
+		"Last quantity derived from the humidity and temperature of a zone.", "zone", "quantity")
+	zoneTargetMetric = newGaugeVec("zeus_zone_target",
+		"Current climate target of a zone.", "zone", "quantity")
+	zoneAlarmMetric = newGaugeVec("zeus_zone_alarm_active",
+		"1 if an alarm is currently active in a zone, 0 otherwise.", "zone", "alarm", "level")
+	dispatcherDropMetric = newCounterVec("zeus_dispatcher_dropped_messages_total",
+		"Number of CAN messages dropped because their receiver was not ready.", "interface", "id")
+	heartbeatMetric = newCounterVec("zeus_device_heartbeats_total",
+		"Number of heartbeats received from a device.", "interface", "class", "id")
+	heartbeatTimeMetric = newGaugeVec("zeus_device_last_heartbeat_timestamp_seconds",
+		"Time of the last heartbeat received from a device.", "interface", "class", "id")
+)
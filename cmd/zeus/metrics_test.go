@@ -0,0 +1,53 @@
+package main
+
+import (
+	"bytes"
+	"math"
+
+	. "gopkg.in/check.v1"
+)
+
+type MetricsSuite struct{}
+
+var _ = Suite(&MetricsSuite{})
+
+func (s *MetricsSuite) TestExposition(c *C) {
+	registry := &metricsRegistry{}
+	gauge := registry.register(&metricVec{
+		name:    "test_gauge",
+		help:    "A test gauge.",
+		kind:    gaugeMetric,
+		labels:  []string{"zone"},
+		samples: make(map[string]*metricSample),
+	})
+	counter := registry.register(&metricVec{
+		name:    "test_total",
+		help:    "A test counter.",
+		kind:    counterMetric,
+		samples: make(map[string]*metricSample),
+	})
+
+	gauge.Set(21.5, "box")
+	gauge.Set(12, `a "quoted" zone`)
+	gauge.Set(math.Inf(-1), "undefined")
+	counter.Add(1)
+	counter.Add(2)
+
+	buffer := bytes.NewBuffer(nil)
+	c.Assert(registry.Write(buffer), IsNil)
+	c.Check(buffer.String(), Equals, `# HELP test_gauge A test gauge.
+# TYPE test_gauge gauge
+test_gauge{zone="a \"quoted\" zone"} 12
+test_gauge{zone="box"} 21.5
+# HELP test_total A test counter.
+# TYPE test_total counter
+test_total 3
+`)
+
+	gauge.DeleteMatching("zone", "box")
+	_, ok := gauge.Value("box")
+	c.Check(ok, Equals, false)
+	v, ok := counter.Value()
+	c.Check(ok, Equals, true)
+	c.Check(v, Equals, 3.0)
+}
@@ -0,0 +1,199 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"strings"
+	"time"
+
+	mqtt "github.com/eclipse/paho.mqtt.golang"
+	"github.com/formicidae-tracker/olympus/pkg/tm"
+	"github.com/formicidae-tracker/zeus/internal/zeus"
+	"github.com/sirupsen/logrus"
+)
+
+// mqttReporter publishes climate reports, targets and alarm events of
+// a zone as JSON messages to a MQTT broker.
+type mqttReporter struct {
+	zoneStream
+
+	zone    string
+	client  mqtt.Client
+	qos     byte
+	retain  bool
+	timeout time.Duration
+
+	climateTopic, targetTopic, alarmTopic string
+
+	log *logrus.Entry
+}
+
+type mqttState struct {
+	Name         string   `json:"name"`
+	Temperature  *float32 `json:"temperature,omitempty"`
+	Humidity     *float32 `json:"humidity,omitempty"`
+	Wind         *float32 `json:"wind,omitempty"`
+	VisibleLight *float32 `json:"visible_light,omitempty"`
+	UVLight      *float32 `json:"uv_light,omitempty"`
+}
+
+type mqttClimateReport struct {
+	Zone             string     `json:"zone"`
+	Time             time.Time  `json:"time"`
+	Humidity         *float32   `json:"humidity"`
+	Temperatures     []*float32 `json:"temperatures"`
+	DewPoint         *float32   `json:"dew_point,omitempty"`
+	AbsoluteHumidity *float32   `json:"absolute_humidity,omitempty"`
+	VPD              *float32   `json:"vpd,omitempty"`
+}
+
+type mqttClimateTarget struct {
+	Zone       string     `json:"zone"`
+	Time       time.Time  `json:"time"`
+	Current    mqttState  `json:"current"`
+	CurrentEnd *mqttState `json:"current_end,omitempty"`
+	Next       *mqttState `json:"next,omitempty"`
+	NextEnd    *mqttState `json:"next_end,omitempty"`
+	NextTime   *time.Time `json:"next_time,omitempty"`
+}
+
+type mqttAlarmEvent struct {
+	Zone        string    `json:"zone"`
+	Identifier  string    `json:"identifier"`
+	Description string    `json:"description"`
+	Level       string    `json:"level"`
+	Active      bool      `json:"active"`
+	Time        time.Time `json:"time"`
+}
+
+func buildMQTTState(s *zeus.State) *mqttState {
+	if s == nil {
+		return nil
+	}
+	return &mqttState{
+		Name:         s.Name,
+		Temperature:  zeus.AsFloat32Pointer(s.Temperature),
+		Humidity:     zeus.AsFloat32Pointer(s.Humidity),
+		Wind:         zeus.AsFloat32Pointer(s.Wind),
+		VisibleLight: zeus.AsFloat32Pointer(s.VisibleLight),
+		UVLight:      zeus.AsFloat32Pointer(s.UVLight),
+	}
+}
+
+func expandTopic(topic, host, zone string) string {
+	return strings.NewReplacer("{host}", host, "{zone}", zone).Replace(topic)
+}
+
+func (r *mqttReporter) publish(topic string, v interface{}) {
+	payload, err := json.Marshal(v)
+	if err != nil {
+		r.log.WithError(err).Error("could not encode message")
+		return
+	}
+	token := r.client.Publish(topic, r.qos, r.retain, payload)
+	go func() {
+		if token.WaitTimeout(r.timeout) == false {
+			r.log.WithField("topic", topic).Warn("publication timeout")
+		} else if token.Error() != nil {
+			r.log.WithError(token.Error()).WithField("topic", topic).Error("could not publish")
+		}
+	}()
+}
+
+func (r *mqttReporter) report(report zeus.ClimateReport) {
+	temperatures := make([]*float32, len(report.Temperatures))
+	for i, t := range report.Temperatures {
+		temperatures[i] = zeus.AsFloat32Pointer(t)
+	}
+	r.publish(r.climateTopic, mqttClimateReport{
+		Zone:             r.zone,
+		Time:             report.Time,
+		Humidity:         zeus.AsFloat32Pointer(report.Humidity),
+		Temperatures:     temperatures,
+		DewPoint:         zeus.AsFloat32Pointer(report.DewPoint()),
+		AbsoluteHumidity: zeus.Float32Pointer(report.AbsoluteHumidity()),
+		VPD:              zeus.Float32Pointer(report.VaporPressureDeficit()),
+	})
+}
+
+func (r *mqttReporter) target(target zeus.ClimateTarget) {
+	r.publish(r.targetTopic, mqttClimateTarget{
+		Zone:       r.zone,
+		Time:       time.Now(),
+		Current:    *buildMQTTState(&target.Current),
+		CurrentEnd: buildMQTTState(target.CurrentEnd),
+		Next:       buildMQTTState(target.Next),
+		NextEnd:    buildMQTTState(target.NextEnd),
+		NextTime:   target.NextTime,
+	})
+}
+
+func (r *mqttReporter) alarm(event zeus.AlarmEvent) {
+	r.publish(r.alarmTopic, mqttAlarmEvent{
+		Zone:        r.zone,
+		Identifier:  event.Identifier,
+		Description: event.Description,
+		Level:       event.Flags.Level(),
+		Active:      event.Status == zeus.AlarmOn,
+		Time:        event.Time,
+	})
+}
+
+func (r *mqttReporter) Report(ready chan<- struct{}) {
+	token := r.client.Connect()
+	go func() {
+		token.Wait()
+		if token.Error() != nil {
+			r.log.WithError(token.Error()).Error("could not connect to broker")
+		}
+	}()
+	close(ready)
+	r.stream(r, nil, nil)
+	r.client.Disconnect(uint(r.timeout.Milliseconds()))
+}
+
+func NewMQTTReporter(zone string, d MQTTDefinition) (*mqttReporter, error) {
+	if err := d.check(); err != nil {
+		return nil, err
+	}
+	hostname, err := os.Hostname()
+	if err != nil {
+		return nil, err
+	}
+
+	withDefault := func(topic, def string) string {
+		if len(topic) == 0 {
+			topic = def
+		}
+		return expandTopic(topic, hostname, zone)
+	}
+
+	clientID := d.ClientID
+	if len(clientID) == 0 {
+		clientID = fmt.Sprintf("zeus-%s-%s", hostname, zone)
+	}
+
+	options := mqtt.NewClientOptions().
+		AddBroker(d.Broker).
+		SetClientID(clientID).
+		SetUsername(d.Username).
+		SetPassword(d.Password).
+		SetAutoReconnect(true).
+		SetConnectRetry(true).
+		SetConnectRetryInterval(10 * time.Second)
+
+	return &mqttReporter{
+		zoneStream:   newZoneStream(),
+		zone:         zone,
+		client:       mqtt.NewClient(options),
+		qos:          d.QoS,
+		retain:       d.Retain,
+		timeout:      5 * time.Second,
+		climateTopic: withDefault(d.ClimateTopic, "zeus/{host}/{zone}/climate"),
+		targetTopic:  withDefault(d.TargetTopic, "zeus/{host}/{zone}/target"),
+		alarmTopic:   withDefault(d.AlarmTopic, "zeus/{host}/{zone}/alarms"),
+		log:          tm.NewLogger(path.Join("zone", zone, "mqtt")),
+	}, nil
+}
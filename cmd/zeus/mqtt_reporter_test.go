@@ -0,0 +1,161 @@
+package main
+
+import (
+	"encoding/json"
+	"net"
+	"os"
+	"time"
+
+	"github.com/eclipse/paho.mqtt.golang/packets"
+	"github.com/formicidae-tracker/zeus/internal/zeus"
+	. "gopkg.in/check.v1"
+)
+
+type MQTTReporterSuite struct {
+	listener  net.Listener
+	published chan *packets.PublishPacket
+}
+
+var _ = Suite(&MQTTReporterSuite{})
+
+// serveBroker is a minimal MQTT broker that accepts a single client
+// and forwards all publications to s.published.
+func (s *MQTTReporterSuite) serveBroker() {
+	conn, err := s.listener.Accept()
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+	for {
+		p, err := packets.ReadPacket(conn)
+		if err != nil {
+			return
+		}
+		switch pp := p.(type) {
+		case *packets.ConnectPacket:
+			ack := packets.NewControlPacket(packets.Connack).(*packets.ConnackPacket)
+			ack.Write(conn)
+		case *packets.PublishPacket:
+			s.published <- pp
+			if pp.Qos == 1 {
+				ack := packets.NewControlPacket(packets.Puback).(*packets.PubackPacket)
+				ack.MessageID = pp.MessageID
+				ack.Write(conn)
+			}
+		case *packets.PingreqPacket:
+			packets.NewControlPacket(packets.Pingresp).Write(conn)
+		case *packets.DisconnectPacket:
+			return
+		}
+	}
+}
+
+func (s *MQTTReporterSuite) SetUpTest(c *C) {
+	var err error
+	s.listener, err = net.Listen("tcp", "127.0.0.1:0")
+	c.Assert(err, IsNil)
+	s.published = make(chan *packets.PublishPacket, 10)
+	go s.serveBroker()
+}
+
+func (s *MQTTReporterSuite) TearDownTest(c *C) {
+	s.listener.Close()
+}
+
+func (s *MQTTReporterSuite) next(c *C) *packets.PublishPacket {
+	select {
+	case p := <-s.published:
+		return p
+	case <-time.After(5 * time.Second):
+		c.Fatalf("timeout while waiting for publication")
+	}
+	return nil
+}
+
+func (s *MQTTReporterSuite) TestPublishes(c *C) {
+	hostname, err := os.Hostname()
+	c.Assert(err, IsNil)
+	r, err := NewMQTTReporter("box", MQTTDefinition{
+		Broker:     "tcp://" + s.listener.Addr().String(),
+		QoS:        1,
+		AlarmTopic: "lab/{zone}/alarms",
+	})
+	c.Assert(err, IsNil)
+
+	ready := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		r.Report(ready)
+		close(done)
+	}()
+	<-ready
+	// publications made while connecting are lost with a clean session
+	for i := 0; r.client.IsConnectionOpen() == false; i++ {
+		c.Assert(i < 500, Equals, true, Commentf("client did not connect"))
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	now := time.Now().UTC().Round(0)
+	r.ReportChannel() <- zeus.ClimateReport{
+		Time:         now,
+		Humidity:     55,
+		Temperatures: []zeus.Temperature{22, zeus.UndefinedTemperature},
+	}
+	p := s.next(c)
+	c.Check(p.TopicName, Equals, "zeus/"+hostname+"/box/climate")
+	c.Check(p.Qos, Equals, byte(1))
+	report := map[string]interface{}{}
+	c.Assert(json.Unmarshal(p.Payload, &report), IsNil)
+	c.Check(report["humidity"], Equals, 55.0)
+	c.Check(report["temperatures"], DeepEquals, []interface{}{22.0, nil})
+	c.Check(report["dew_point"], Not(IsNil))
+	c.Check(report["absolute_humidity"], Not(IsNil))
+	c.Check(report["vpd"], Not(IsNil))
+
+	r.TargetChannel() <- zeus.ClimateTarget{
+		Current: zeus.State{
+			Name:         "day",
+			Temperature:  26,
+			Humidity:     zeus.UndefinedHumidity,
+			Wind:         zeus.UndefinedWind,
+			VisibleLight: 100,
+			UVLight:      zeus.UndefinedLight,
+		},
+	}
+	p = s.next(c)
+	c.Check(p.TopicName, Equals, "zeus/"+hostname+"/box/target")
+	target := mqttClimateTarget{}
+	c.Assert(json.Unmarshal(p.Payload, &target), IsNil)
+	c.Check(target.Current.Name, Equals, "day")
+	c.Assert(target.Current.Temperature, Not(IsNil))
+	c.Check(*target.Current.Temperature, Equals, float32(26))
+	c.Check(target.Current.Humidity, IsNil)
+	c.Check(target.Next, IsNil)
+
+	r.AlarmChannel() <- zeus.AlarmEvent{
+		Identifier: "climate.temperature_out_of_bound",
+		Flags:      zeus.Emergency,
+		Status:     zeus.AlarmOn,
+		Time:       now,
+	}
+	p = s.next(c)
+	c.Check(p.TopicName, Equals, "lab/box/alarms")
+	alarm := mqttAlarmEvent{}
+	c.Assert(json.Unmarshal(p.Payload, &alarm), IsNil)
+	c.Check(alarm, DeepEquals, mqttAlarmEvent{
+		Zone:       "box",
+		Identifier: "climate.temperature_out_of_bound",
+		Level:      "emergency",
+		Active:     true,
+		Time:       now,
+	})
+
+	close(r.ReportChannel())
+	close(r.TargetChannel())
+	close(r.AlarmChannel())
+	select {
+	case <-done:
+	case <-time.After(10 * time.Second):
+		c.Fatalf("reporter did not terminate")
+	}
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+
+	flags "github.com/jessevdk/go-flags"
+)
+
+type OpenInterfacesCommand struct {
+	Args struct {
+		Config flags.Filename
+	} `positional-args:"yes"`
+}
+
+// openInterface sets up ifname according to its type. Only slcan
+// interfaces are supervised.
+func openInterface(ifname string, definition InterfaceDefinition) (io.Closer, error) {
+	switch definition.InterfaceType() {
+	case NativeInterface:
+		return OpenNativeInterface(ifname, definition.CANBitrate())
+	case VirtualInterface:
+		return OpenVirtualInterface(ifname)
+	default:
+		return OpenSlcand(ifname, definition.Device, definition.CANBitrate())
+	}
+}
+
+func (c *OpenInterfacesCommand) Execute(args []string) error {
+	config, err := OpenConfigFromArg(c.Args.Config)
+	if err != nil {
+		return err
+	}
+	if err = config.Check(); err != nil {
+		return err
+	}
+	if err := resolveInterfaces(config.Interfaces, defaultSerialDeviceResolver); err != nil {
+		return err
+	}
+	managers := map[string]io.Closer{}
+	defer func() {
+		for ifname, manager := range managers {
+			err = manager.Close()
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "[zeus] could not close %s:%s\n", ifname, err)
+			}
+		}
+	}()
+	for ifname, definition := range config.Interfaces {
+		manager, err := openInterface(ifname, definition)
+		if err != nil {
+			return err
+		}
+		managers[ifname] = manager
+	}
+	sigint := make(chan os.Signal, 1)
+	signal.Notify(sigint, os.Interrupt)
+	<-sigint
+	return nil
+}
+
+// resolveInterfaces checks that the adapter of every slcan interface
+// is plugged, and that no adapter is used twice.
+func resolveInterfaces(interfaces map[string]InterfaceDefinition, r serialDeviceResolver) error {
+	used := map[string]string{}
+	for ifname, definition := range interfaces {
+		if definition.InterfaceType() != SlcanInterface {
+			continue
+		}
+		tty, err := r.resolve(definition.Device)
+		if err != nil {
+			return fmt.Errorf("interface %s: %w", ifname, err)
+		}
+		if other, ok := used[tty]; ok == true {
+			return fmt.Errorf("interface %s: adapter %s is also used by interface %s", ifname, tty, other)
+		}
+		used[tty] = ifname
+	}
+	return nil
+}
+
+func init() {
+	command, err := parser.AddCommand("open-interfaces",
+		"open CAN interfaces",
+		"Opens the CAN interfaces of the configuration: runs slcand for slcan interfaces, sets the bitrate of native ones and adds virtual ones. It requires super user right",
+		&OpenInterfacesCommand{})
+	if err != nil {
+		panic(err.Error())
+	}
+	command.Aliases = []string{"open-slcan-interfaces"}
+}
@@ -0,0 +1,146 @@
+package main
+
+import (
+	"context"
+	"time"
+
+	"github.com/formicidae-tracker/libarke/src-go/arke"
+	"github.com/formicidae-tracker/zeus/internal/zeus"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetricgrpc"
+	"go.opentelemetry.io/otel/metric"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/resource"
+	semconv "go.opentelemetry.io/otel/semconv/v1.17.0"
+)
+
+// climateInstruments are the OpenTelemetry instruments of the
+// climate pipeline. They are created on the global meter, which
+// simply discards measurements until a provider is set up.
+type climateInstruments struct {
+	framesReceived   metric.Int64Counter
+	framesParsed     metric.Int64Counter
+	parseErrors      metric.Int64Counter
+	callbackLatency  metric.Float64Histogram
+	interpolerTicks  metric.Int64Counter
+	setPointsSent    metric.Int64Counter
+	alarmTransitions metric.Int64Counter
+}
+
+func newClimateInstruments(meter metric.Meter) *climateInstruments {
+	res := &climateInstruments{}
+	// the global meter never returns an error, instruments are
+	// always usable.
+	res.framesReceived, _ = meter.Int64Counter("zeus.can.frames.received",
+		metric.WithDescription("Number of CAN frames received"),
+		metric.WithUnit("{frame}"))
+	res.framesParsed, _ = meter.Int64Counter("zeus.can.frames.parsed",
+		metric.WithDescription("Number of CAN frames successfully parsed as Arke messages"),
+		metric.WithUnit("{frame}"))
+	res.parseErrors, _ = meter.Int64Counter("zeus.can.parse_errors",
+		metric.WithDescription("Number of CAN frames that could not be parsed"),
+		metric.WithUnit("{frame}"))
+	res.callbackLatency, _ = meter.Float64Histogram("zeus.callback.duration",
+		metric.WithDescription("Time spent processing an Arke message by zone callbacks"),
+		metric.WithUnit("ms"))
+	res.interpolerTicks, _ = meter.Int64Counter("zeus.interpoler.ticks",
+		metric.WithDescription("Number of climate interpolation steps"),
+		metric.WithUnit("{tick}"))
+	res.setPointsSent, _ = meter.Int64Counter("zeus.setpoints.sent",
+		metric.WithDescription("Number of set-points sent to devices"),
+		metric.WithUnit("{setpoint}"))
+	res.alarmTransitions, _ = meter.Int64Counter("zeus.alarm.transitions",
+		metric.WithDescription("Number of alarm status changes"),
+		metric.WithUnit("{transition}"))
+	return res
+}
+
+var instruments = newClimateInstruments(otel.Meter(instrumentationName))
+
+func (i *climateInstruments) frameReceived(ifname string) {
+	i.framesReceived.Add(context.Background(), 1,
+		metric.WithAttributes(attribute.String("interface", ifname)))
+}
+
+func (i *climateInstruments) frameParsed(ifname string, class arke.MessageClass) {
+	i.framesParsed.Add(context.Background(), 1,
+		metric.WithAttributes(
+			attribute.String("interface", ifname),
+			attribute.String("class", class.String())))
+}
+
+func (i *climateInstruments) parseError(ifname string) {
+	i.parseErrors.Add(context.Background(), 1,
+		metric.WithAttributes(attribute.String("interface", ifname)))
+}
+
+func (i *climateInstruments) callbackDone(zone string, class arke.MessageClass, start time.Time) {
+	i.callbackLatency.Record(context.Background(),
+		float64(time.Since(start).Nanoseconds())/1e6,
+		metric.WithAttributes(
+			attribute.String("zone", zone),
+			attribute.String("class", class.String())))
+}
+
+func (i *climateInstruments) interpolerTick(zone string) {
+	i.interpolerTicks.Add(context.Background(), 1,
+		metric.WithAttributes(attribute.String("zone", zone)))
+}
+
+func (i *climateInstruments) setPointSent(zone, capability string) {
+	i.setPointsSent.Add(context.Background(), 1,
+		metric.WithAttributes(
+			attribute.String("zone", zone),
+			attribute.String("capability", capability)))
+}
+
+// setPointCapabilityName returns the name used to report set-points
+// of a capability, if it sends any.
+func setPointCapabilityName(c capability) (string, bool) {
+	switch c.(type) {
+	case *ClimateControllable:
+		return "climate", true
+	case *LightControllable:
+		return "light", true
+	default:
+		return "", false
+	}
+}
+
+func (i *climateInstruments) alarmTransition(zone string, event zeus.AlarmEvent) {
+	status := "on"
+	if event.Status == zeus.AlarmOff {
+		status = "off"
+	}
+	i.alarmTransitions.Add(context.Background(), 1,
+		metric.WithAttributes(
+			attribute.String("zone", zone),
+			attribute.String("alarm", event.Identifier),
+			attribute.String("level", event.Flags.Level()),
+			attribute.String("status", status)))
+}
+
+// setUpMetricsExport exports the global meter to the collector
+// used for traces.
+func setUpMetricsExport(collectorURL, serviceName string) (*sdkmetric.MeterProvider, error) {
+	exporter, err := otlpmetricgrpc.New(context.Background(),
+		otlpmetricgrpc.WithEndpoint(collectorURL),
+		otlpmetricgrpc.WithInsecure())
+	if err != nil {
+		return nil, err
+	}
+	res, err := resource.Merge(resource.Default(),
+		resource.NewWithAttributes(semconv.SchemaURL,
+			semconv.ServiceName(serviceName),
+			semconv.ServiceVersion(zeus.ZEUS_VERSION)))
+	if err != nil {
+		return nil, err
+	}
+	provider := sdkmetric.NewMeterProvider(
+		sdkmetric.WithResource(res),
+		sdkmetric.WithReader(sdkmetric.NewPeriodicReader(exporter,
+			sdkmetric.WithInterval(15*time.Second))))
+	otel.SetMeterProvider(provider)
+	return provider, nil
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"context"
+	"time"
+
+	"github.com/formicidae-tracker/libarke/src-go/arke"
+	"github.com/formicidae-tracker/zeus/internal/zeus"
+	"go.opentelemetry.io/otel/attribute"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	. "gopkg.in/check.v1"
+)
+
+type OTelMetricsSuite struct{}
+
+var _ = Suite(&OTelMetricsSuite{})
+
+func (s *OTelMetricsSuite) TestRecordsPipelineEvents(c *C) {
+	reader := sdkmetric.NewManualReader()
+	provider := sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))
+	defer provider.Shutdown(context.Background())
+	i := newClimateInstruments(provider.Meter(instrumentationName))
+
+	i.frameReceived("slcan0")
+	i.frameReceived("slcan0")
+	i.parseError("slcan0")
+	i.frameParsed("slcan0", arke.ZeusReportMessage)
+	i.callbackDone("box", arke.ZeusReportMessage, time.Now())
+	i.setPointSent("box", "climate")
+	i.alarmTransition("box", zeus.AlarmEvent{Identifier: "foo", Status: zeus.AlarmOn, Flags: zeus.Emergency})
+	i.alarmTransition("box", zeus.AlarmEvent{Identifier: "foo", Status: zeus.AlarmOff, Flags: zeus.Emergency})
+
+	data := metricdata.ResourceMetrics{}
+	c.Assert(reader.Collect(context.Background(), &data), IsNil)
+	c.Assert(len(data.ScopeMetrics), Equals, 1)
+
+	sums := map[string]metricdata.Sum[int64]{}
+	histograms := 0
+	for _, m := range data.ScopeMetrics[0].Metrics {
+		switch d := m.Data.(type) {
+		case metricdata.Sum[int64]:
+			sums[m.Name] = d
+		case metricdata.Histogram[float64]:
+			histograms += len(d.DataPoints)
+		}
+	}
+	c.Check(histograms, Equals, 1)
+
+	received := sums["zeus.can.frames.received"]
+	c.Assert(len(received.DataPoints), Equals, 1)
+	c.Check(received.DataPoints[0].Value, Equals, int64(2))
+
+	parsed := sums["zeus.can.frames.parsed"]
+	c.Assert(len(parsed.DataPoints), Equals, 1)
+	class, _ := parsed.DataPoints[0].Attributes.Value(attribute.Key("class"))
+	c.Check(class.AsString(), Equals, arke.ZeusReportMessage.String())
+
+	c.Check(len(sums["zeus.can.parse_errors"].DataPoints), Equals, 1)
+	c.Check(len(sums["zeus.setpoints.sent"].DataPoints), Equals, 1)
+	c.Check(len(sums["zeus.alarm.transitions"].DataPoints), Equals, 2)
+}
@@ -0,0 +1,259 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"time"
+
+	"github.com/formicidae-tracker/libarke/src-go/arke"
+	"github.com/formicidae-tracker/zeus/internal/zeus"
+	yaml "gopkg.in/yaml.v2"
+)
+
+const (
+	plantMaxStep            = 10 * time.Second
+	plantProportionalGain   = 2.0
+	plantUnreachableDelay   = 5 * time.Minute
+	plantTemperatureMargin  = 0.5
+	plantHumidityMargin     = 2.0
+	plantProgressMargin     = 0.2
+	plantWaterWarningLevel  = 0.25
+	plantWaterCriticalLevel = 0.1
+)
+
+// PlantParameters describes the thermal and hygrometric behavior of a
+// tracking box. Rates are expressed per minute.
+type PlantParameters struct {
+	AmbientTemperature float64 `yaml:"ambient-temperature"`
+	AmbientHumidity    float64 `yaml:"ambient-humidity"`
+	// AmbientCoupling is the fraction of the difference with the
+	// ambient climate lost every minute.
+	AmbientCoupling float64 `yaml:"ambient-coupling"`
+	// HeatingRate and CoolingRate are in °C per minute at full power.
+	HeatingRate float64 `yaml:"heating-rate"`
+	CoolingRate float64 `yaml:"cooling-rate"`
+	// EvaporationRate is in %RH per minute at full power.
+	EvaporationRate float64 `yaml:"evaporation-rate"`
+	// TankAutonomy is the time needed to empty the water tank at full
+	// evaporation.
+	TankAutonomy time.Duration `yaml:"tank-autonomy"`
+}
+
+func DefaultPlantParameters() PlantParameters {
+	return PlantParameters{
+		AmbientTemperature: 22.0,
+		AmbientHumidity:    40.0,
+		AmbientCoupling:    0.05,
+		HeatingRate:        0.8,
+		CoolingRate:        0.4,
+		EvaporationRate:    3.0,
+		TankAutonomy:       72 * time.Hour,
+	}
+}
+
+// OpenPlantParameters reads plant parameters from a YAML file. Missing
+// values keep their default.
+func OpenPlantParameters(filename string) (PlantParameters, error) {
+	res := DefaultPlantParameters()
+	content, err := os.ReadFile(filename)
+	if err != nil {
+		return res, err
+	}
+	if err := yaml.Unmarshal(content, &res); err != nil {
+		return res, err
+	}
+	return res, res.check()
+}
+
+func (p PlantParameters) check() error {
+	if p.AmbientCoupling <= 0 || p.AmbientCoupling > 1 {
+		return fmt.Errorf("invalid ambient-coupling %g (should be in ]0,1])", p.AmbientCoupling)
+	}
+	if p.HeatingRate < 0 || p.CoolingRate < 0 || p.EvaporationRate < 0 {
+		return fmt.Errorf("invalid plant rates: negative values")
+	}
+	if p.TankAutonomy <= 0 {
+		return fmt.Errorf("invalid tank-autonomy %s", p.TankAutonomy)
+	}
+	return nil
+}
+
+// PlantModel is a first-order model of a tracking box climate,
+// regulated toward the set points sent to its Zeus and Celaeno.
+type PlantModel struct {
+	PlantParameters
+
+	Temperature float64
+	Humidity    float64
+	// WaterLevel is the fraction of water left in the tank.
+	WaterLevel float64
+
+	temperatureTarget zeus.Temperature
+	humidityTarget    zeus.Humidity
+
+	temperatureSaturation saturationMonitor
+	humiditySaturation    saturationMonitor
+}
+
+// saturationMonitor measures for how long an actuator is saturated
+// without getting closer to its target.
+type saturationMonitor struct {
+	duration time.Duration
+	error    float64
+}
+
+func (s *saturationMonitor) update(saturated bool, error float64, dt time.Duration) {
+	if saturated == false {
+		s.duration = 0
+		return
+	}
+	if s.duration == 0 || error < s.error-plantProgressMargin {
+		s.duration = dt
+		s.error = error
+		return
+	}
+	s.duration += dt
+}
+
+// NewPlantModel returns a model at the ambient climate, with a full
+// water tank and no target.
+func NewPlantModel(p PlantParameters) *PlantModel {
+	return &PlantModel{
+		PlantParameters:   p,
+		Temperature:       p.AmbientTemperature,
+		Humidity:          p.AmbientHumidity,
+		WaterLevel:        1.0,
+		temperatureTarget: zeus.UndefinedTemperature,
+		humidityTarget:    zeus.UndefinedHumidity,
+	}
+}
+
+// SetTarget sets the regulated temperature and humidity. Undefined
+// values are not regulated.
+func (m *PlantModel) SetTarget(temperature zeus.Temperature, humidity zeus.Humidity) {
+	if temperature != m.temperatureTarget {
+		m.temperatureSaturation = saturationMonitor{}
+	}
+	if humidity != m.humidityTarget {
+		m.humiditySaturation = saturationMonitor{}
+	}
+	m.temperatureTarget = temperature
+	m.humidityTarget = humidity
+}
+
+func clampUnit(v, min, max float64) float64 {
+	return math.Min(math.Max(v, min), max)
+}
+
+// heatingPower returns the heating command in [-1,1], negative values
+// being cooling. It compensates the ambient losses at the target.
+func (m *PlantModel) heatingPower() float64 {
+	if zeus.IsUndefined(m.temperatureTarget) == true {
+		return 0
+	}
+	target := m.temperatureTarget.Value()
+	power := m.AmbientCoupling*(target-m.AmbientTemperature) + plantProportionalGain*(target-m.Temperature)
+	if power >= 0 {
+		if m.HeatingRate == 0 {
+			return 0
+		}
+		return clampUnit(power/m.HeatingRate, 0, 1)
+	}
+	if m.CoolingRate == 0 {
+		return 0
+	}
+	return clampUnit(power/m.CoolingRate, -1, 0)
+}
+
+// evaporationPower returns the humidifier command in [0,1]. The box
+// can only be humidified.
+func (m *PlantModel) evaporationPower() float64 {
+	if zeus.IsUndefined(m.humidityTarget) == true || m.WaterLevel <= 0 || m.EvaporationRate == 0 {
+		return 0
+	}
+	target := m.humidityTarget.Value()
+	power := m.AmbientCoupling*(target-m.AmbientHumidity) + plantProportionalGain*(target-m.Humidity)
+	return clampUnit(power/m.EvaporationRate, 0, 1)
+}
+
+func (m *PlantModel) step(dt time.Duration) {
+	minutes := dt.Minutes()
+	heating := m.heatingPower()
+	rate := m.HeatingRate
+	if heating < 0 {
+		rate = m.CoolingRate
+	}
+	m.Temperature += (m.AmbientCoupling*(m.AmbientTemperature-m.Temperature) + heating*rate) * minutes
+
+	evaporation := m.evaporationPower()
+	m.Humidity += (m.AmbientCoupling*(m.AmbientHumidity-m.Humidity) + evaporation*m.EvaporationRate) * minutes
+	m.Humidity = clampUnit(m.Humidity, 0, 100)
+	m.WaterLevel = math.Max(0, m.WaterLevel-evaporation*dt.Seconds()/m.TankAutonomy.Seconds())
+
+	m.temperatureSaturation.update(math.Abs(heating) >= 1 && m.missesTemperature() == true,
+		math.Abs(m.temperatureTarget.Value()-m.Temperature), dt)
+	m.humiditySaturation.update((evaporation >= 1 || m.WaterLevel <= 0) && m.missesHumidity() == true,
+		m.humidityTarget.Value()-m.Humidity, dt)
+}
+
+// Step advances the model by dt.
+func (m *PlantModel) Step(dt time.Duration) {
+	for dt > 0 {
+		step := dt
+		if step > plantMaxStep {
+			step = plantMaxStep
+		}
+		m.step(step)
+		dt -= step
+	}
+}
+
+func (m *PlantModel) missesTemperature() bool {
+	return zeus.IsUndefined(m.temperatureTarget) == false &&
+		math.Abs(m.temperatureTarget.Value()-m.Temperature) > plantTemperatureMargin
+}
+
+func (m *PlantModel) missesHumidity() bool {
+	return zeus.IsUndefined(m.humidityTarget) == false &&
+		m.humidityTarget.Value()-m.Humidity > plantHumidityMargin
+}
+
+// TemperatureUnreachable returns true if the heater or cooler is
+// saturated without getting closer to the target for some time.
+func (m *PlantModel) TemperatureUnreachable() bool {
+	return m.temperatureSaturation.duration >= plantUnreachableDelay
+}
+
+// HumidityUnreachable returns true if the humidifier is saturated, or
+// out of water, without getting closer to the target for some time.
+func (m *PlantModel) HumidityUnreachable() bool {
+	return m.humiditySaturation.duration >= plantUnreachableDelay
+}
+
+// ZeusStatus returns the unreachable flags of the model.
+func (m *PlantModel) ZeusStatus() arke.ZeusStatusValue {
+	res := arke.ZeusIdle
+	if m.TemperatureUnreachable() == true {
+		res |= arke.ZeusTemperatureUnreachable
+	}
+	if m.HumidityUnreachable() == true {
+		res |= arke.ZeusHumidityUnreachable
+	}
+	return res
+}
+
+func (m *PlantModel) WaterLevelStatus() arke.WaterLevelStatus {
+	if m.WaterLevel <= plantWaterCriticalLevel {
+		return arke.CelaenoWaterCritical
+	}
+	if m.WaterLevel <= plantWaterWarningLevel {
+		return arke.CelaenoWaterWarning
+	}
+	return arke.CelaenoWaterNominal
+}
+
+// Refill fills the water tank.
+func (m *PlantModel) Refill() {
+	m.WaterLevel = 1.0
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"math"
+	"time"
+
+	"github.com/formicidae-tracker/libarke/src-go/arke"
+	"github.com/formicidae-tracker/zeus/internal/zeus"
+	. "gopkg.in/check.v1"
+)
+
+type PlantModelSuite struct {
+	plant *PlantModel
+}
+
+var _ = Suite(&PlantModelSuite{})
+
+func (s *PlantModelSuite) SetUpTest(c *C) {
+	s.plant = NewPlantModel(DefaultPlantParameters())
+}
+
+func (s *PlantModelSuite) TestDriftsToAmbient(c *C) {
+	s.plant.Temperature = 30.0
+	s.plant.Humidity = 80.0
+	s.plant.Step(4 * time.Hour)
+	c.Check(math.Abs(s.plant.Temperature-22.0) < 0.01, Equals, true)
+	c.Check(math.Abs(s.plant.Humidity-40.0) < 0.01, Equals, true)
+	c.Check(s.plant.WaterLevel, Equals, 1.0)
+	c.Check(s.plant.ZeusStatus(), Equals, arke.ZeusIdle)
+}
+
+func (s *PlantModelSuite) TestReachesTarget(c *C) {
+	s.plant.SetTarget(26.0, 60.0)
+	s.plant.Step(2 * time.Minute)
+	// heating is limited to 0.8°C per minute.
+	c.Check(s.plant.Temperature < 23.7, Equals, true)
+
+	s.plant.Step(30 * time.Minute)
+	c.Check(math.Abs(s.plant.Temperature-26.0) < 0.01, Equals, true)
+	c.Check(math.Abs(s.plant.Humidity-60.0) < 0.01, Equals, true)
+	c.Check(s.plant.WaterLevel < 1.0, Equals, true)
+	c.Check(s.plant.ZeusStatus(), Equals, arke.ZeusIdle)
+
+	s.plant.SetTarget(18.0, zeus.UndefinedHumidity)
+	s.plant.Step(4 * time.Hour)
+	c.Check(math.Abs(s.plant.Temperature-18.0) < 0.01, Equals, true)
+	c.Check(math.Abs(s.plant.Humidity-40.0) < 0.01, Equals, true)
+}
+
+func (s *PlantModelSuite) TestTemperatureUnreachable(c *C) {
+	// the heater can at most hold 22 + 0.8 / 0.05 = 38°C.
+	s.plant.SetTarget(40.0, zeus.UndefinedHumidity)
+	s.plant.Step(20 * time.Minute)
+	c.Check(s.plant.TemperatureUnreachable(), Equals, false)
+	s.plant.Step(2 * time.Hour)
+	c.Check(s.plant.Temperature < 38.0, Equals, true)
+	c.Check(s.plant.ZeusStatus(), Equals, arke.ZeusTemperatureUnreachable)
+
+	s.plant.SetTarget(30.0, zeus.UndefinedHumidity)
+	s.plant.Step(time.Minute)
+	c.Check(s.plant.TemperatureUnreachable(), Equals, false)
+}
+
+func (s *PlantModelSuite) TestWaterDepletion(c *C) {
+	p := DefaultPlantParameters()
+	p.TankAutonomy = time.Hour
+	s.plant = NewPlantModel(p)
+	s.plant.SetTarget(zeus.UndefinedTemperature, 80.0)
+
+	s.plant.Step(30 * time.Minute)
+	c.Check(s.plant.WaterLevelStatus(), Equals, arke.CelaenoWaterNominal)
+	c.Check(s.plant.HumidityUnreachable(), Equals, false)
+
+	s.plant.Step(2 * time.Hour)
+	c.Check(s.plant.WaterLevel, Equals, 0.0)
+	c.Check(s.plant.WaterLevelStatus(), Equals, arke.CelaenoWaterCritical)
+	c.Check(s.plant.HumidityUnreachable(), Equals, true)
+	c.Check(s.plant.Humidity < 50.0, Equals, true)
+
+	s.plant.Refill()
+	s.plant.Step(30 * time.Minute)
+	c.Check(math.Abs(s.plant.Humidity-80.0) < 0.01, Equals, true)
+	c.Check(s.plant.HumidityUnreachable(), Equals, false)
+	c.Check(s.plant.WaterLevelStatus(), Equals, arke.CelaenoWaterNominal)
+}
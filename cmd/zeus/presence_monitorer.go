@@ -3,6 +3,7 @@ package main
 import (
 	"fmt"
 	"path"
+	"strconv"
 	"time"
 
 	socketcan "github.com/atuleu/golang-socketcan"
@@ -19,7 +20,7 @@ type DeviceDefinition struct {
 
 type PresenceMonitorer interface {
 	Monitor([]DeviceDefinition, chan<- zeus.Alarm, chan<- struct{})
-	Ping(class arke.NodeClass, ID arke.NodeID)
+	Ping(class arke.NodeClass, ID arke.NodeID, version FirmwareVersion)
 	Close() error
 }
 
@@ -27,8 +28,9 @@ type presenceMonitorer struct {
 	HeartBeatPeriod time.Duration
 
 	quit, done chan struct{}
-	pings      chan DeviceDefinition
+	pings      chan ping
 	logger     *logrus.Entry
+	inventory  *deviceInventory
 
 	ifname string
 	intf   socketcan.RawInterface
@@ -69,15 +71,28 @@ func (m *presenceMonitorer) Monitor(devices []DeviceDefinition, alarms chan<- ze
 		select {
 		case <-m.quit:
 			return
-		case def := <-m.pings:
+		case p := <-m.pings:
+			def := p.device
+			m.inventory.Seen(def, p.version, time.Now())
 			if _, ok := received[def]; ok == false {
 				m.logger.WithField("device", def).Warn("unmonitored device")
 				continue
 			}
 			received[def] = true
+			id := strconv.Itoa(int(def.ID))
+			heartbeatMetric.Add(1, m.ifname, Name(def.Class), id)
+			heartbeatTimeMetric.Set(float64(time.Now().Unix()), m.ifname, Name(def.Class), id)
 		case <-timeout.C:
+			if l, ok := m.intf.(linkMonitored); ok == true && l.LinkUp() == false {
+				// devices are unreachable, not missing.
+				alarms <- zeus.NewLinkDownAlarm(m.ifname)
+				continue
+			}
 			deviceRequest := make(map[arke.NodeClass]bool)
 			for d, ok := range received {
+				if version, minimum, outdated := m.inventory.Outdated(d); outdated == true {
+					alarms <- zeus.NewOutdatedFirmwareAlarm(m.ifname, d.Class, d.ID, version.String(), minimum.String())
+				}
 				if ok == true {
 					received[d] = false
 					continue
@@ -92,15 +107,23 @@ func (m *presenceMonitorer) Monitor(devices []DeviceDefinition, alarms chan<- ze
 	}
 }
 
-func (m *presenceMonitorer) Ping(class arke.NodeClass, ID arke.NodeID) {
-	m.pings <- DeviceDefinition{Class: class, ID: ID}
+type ping struct {
+	device  DeviceDefinition
+	version FirmwareVersion
+}
+
+func (m *presenceMonitorer) Ping(class arke.NodeClass, ID arke.NodeID, version FirmwareVersion) {
+	m.pings <- ping{DeviceDefinition{Class: class, ID: ID}, version}
 }
 
-func NewPresenceMonitorer(ifname string, intf socketcan.RawInterface) PresenceMonitorer {
+// NewPresenceMonitorer monitors the devices on intf. Their heartbeats
+// are recorded in inventory.
+func NewPresenceMonitorer(ifname string, intf socketcan.RawInterface, inventory *deviceInventory) PresenceMonitorer {
 	return &presenceMonitorer{
 		HeartBeatPeriod: zeus.HeartBeatPeriod,
-		pings:           make(chan DeviceDefinition, 5),
+		pings:           make(chan ping, 5),
 		logger:          tm.NewLogger(path.Join("monitor", ifname)),
+		inventory:       inventory,
 		ifname:          ifname,
 		intf:            intf,
 	}
@@ -20,7 +20,7 @@ var _ = Suite(&PresenceMonitorerSuite{})
 
 func (s *PresenceMonitorerSuite) SetUpTest(c *C) {
 	s.intf = NewStubRawInterface()
-	s.m = NewPresenceMonitorer("test-can", s.intf)
+	s.m = NewPresenceMonitorer("test-can", s.intf, newDeviceInventory(nil))
 	_, s.hook = test.NewNullLogger()
 	s.m.(*presenceMonitorer).logger.Logger.AddHook(s.hook)
 	s.m.(*presenceMonitorer).HeartBeatPeriod = 1 * time.Millisecond
@@ -49,9 +49,9 @@ func (s *PresenceMonitorerSuite) TestAlarmsMissingDevices(c *C) {
 		{Class: arke.HeliosClass, ID: 1},
 	}
 	go func() {
-		s.m.Ping(arke.ZeusClass, 1)
-		s.m.Ping(arke.CelaenoClass, 2)
-		s.m.Ping(arke.HeliosClass, 1)
+		s.m.Ping(arke.ZeusClass, 1, FirmwareVersion{})
+		s.m.Ping(arke.CelaenoClass, 2, FirmwareVersion{})
+		s.m.Ping(arke.HeliosClass, 1, FirmwareVersion{})
 	}()
 	ready := make(chan struct{})
 	go s.m.Monitor(devices, alarms, ready)
@@ -70,3 +70,51 @@ func (s *PresenceMonitorerSuite) TestAlarmsMissingDevices(c *C) {
 	c.Check(e.Message, Equals, "unmonitored device")
 
 }
+
+type downInterface struct {
+	*StubRawInterface
+}
+
+func (downInterface) LinkUp() bool {
+	return false
+}
+
+func (s *PresenceMonitorerSuite) TestAlarmsLinkDown(c *C) {
+	s.m = NewPresenceMonitorer("test-can", downInterface{s.intf}, newDeviceInventory(nil))
+	s.m.(*presenceMonitorer).HeartBeatPeriod = 1 * time.Millisecond
+	alarms := make(chan zeus.Alarm)
+	ready := make(chan struct{})
+	go s.m.Monitor([]DeviceDefinition{{Class: arke.ZeusClass, ID: 1}}, alarms, ready)
+	<-ready
+	a, ok := <-alarms
+	c.Check(ok, Equals, true)
+	c.Check(a, DeepEquals, zeus.NewLinkDownAlarm("test-can"))
+	c.Check(s.m.Close(), IsNil)
+}
+
+func (s *PresenceMonitorerSuite) TestAlarmsOutdatedFirmware(c *C) {
+	inventory := newDeviceInventory(map[arke.NodeClass]FirmwareVersion{
+		arke.ZeusClass: {Major: 1, Minor: 2},
+	})
+	s.m = NewPresenceMonitorer("test-can", s.intf, inventory)
+	s.m.(*presenceMonitorer).HeartBeatPeriod = 1 * time.Millisecond
+	alarms := make(chan zeus.Alarm)
+	s.m.Ping(arke.ZeusClass, 1, FirmwareVersion{Major: 1, Minor: 1, Patch: 4})
+	ready := make(chan struct{})
+	go s.m.Monitor([]DeviceDefinition{{Class: arke.ZeusClass, ID: 1}}, alarms, ready)
+	<-ready
+	a, ok := <-alarms
+	c.Check(ok, Equals, true)
+	c.Check(a, DeepEquals, zeus.NewOutdatedFirmwareAlarm("test-can", arke.ZeusClass, 1, "1.1.4", "1.2.0"))
+	go func() {
+		for range alarms {
+		}
+	}()
+	c.Check(s.m.Close(), IsNil)
+	close(alarms)
+
+	devices := inventory.Devices()
+	c.Assert(devices, HasLen, 1)
+	c.Check(devices[0].Version, Equals, FirmwareVersion{Major: 1, Minor: 1, Patch: 4})
+	c.Check(devices[0].FirstSeen.IsZero(), Equals, false)
+}
@@ -0,0 +1,123 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+	"time"
+
+	"github.com/adrg/xdg"
+	socketcan "github.com/atuleu/golang-socketcan"
+	"github.com/formicidae-tracker/zeus/internal/zeus"
+	flags "github.com/jessevdk/go-flags"
+)
+
+type ReplayCommand struct {
+	Config  flags.Filename `short:"c" long:"config" description:"zeus configuration file, defaults to /etc/default/zeus.yml"`
+	Speed   float64        `short:"s" long:"speed" description:"accelerates the replay by this factor" default:"1.0"`
+	DataDir string         `short:"d" long:"data-dir" description:"directory for the climate and alarm logs, defaults to a new temporary directory"`
+
+	Args struct {
+		Season flags.Filename
+		Log    flags.Filename
+	} `positional-args:"yes" required:"yes"`
+}
+
+// replayFactory returns an interface factory replaying, for each
+// interface, the frames it received in entries. All created
+// interfaces are appended to replays.
+func replayFactory(entries []CANLogEntry, speed float64, replays *[]*ReplayInterface) func(string) (socketcan.RawInterface, error) {
+	return func(ifname string) (socketcan.RawInterface, error) {
+		var frames []CANLogEntry
+		for _, e := range entries {
+			if e.Interface == ifname {
+				frames = append(frames, e)
+			}
+		}
+		if len(frames) == 0 {
+			return nil, fmt.Errorf("no frames recorded on '%s'", ifname)
+		}
+		res := NewReplayInterface(frames, speed)
+		*replays = append(*replays, res)
+		return res, nil
+	}
+}
+
+func (c *ReplayCommand) setUpDataDir() (string, error) {
+	dir := c.DataDir
+	if len(dir) == 0 {
+		var err error
+		dir, err = os.MkdirTemp("", "zeus-replay-")
+		if err != nil {
+			return "", err
+		}
+	}
+	// xdg only reads its base directories from the environment.
+	if err := os.Setenv("XDG_DATA_HOME", dir); err != nil {
+		return "", err
+	}
+	xdg.Reload()
+	return dir, nil
+}
+
+func (c *ReplayCommand) Execute(args []string) error {
+	entries, err := ReadCANLogFile(string(c.Args.Log))
+	if err != nil {
+		return fmt.Errorf("could not read '%s': %w", c.Args.Log, err)
+	}
+	season, err := zeus.ReadSeasonFile(string(c.Args.Season), os.Stderr)
+	if err != nil {
+		return err
+	}
+	config, err := OpenConfigFromArg(c.Config)
+	if err != nil {
+		return err
+	}
+	// never record a replay.
+	config.CANLogDirectory = ""
+
+	dir, err := c.setUpDataDir()
+	if err != nil {
+		return fmt.Errorf("could not set up data directory: %w", err)
+	}
+
+	z, err := OpenZeus(*config)
+	if err != nil {
+		return err
+	}
+	var replays []*ReplayInterface
+	z.intfFactory = replayFactory(entries, c.Speed, &replays)
+
+	if err := z.startClimate(*season); err != nil {
+		return err
+	}
+
+	sigint := make(chan os.Signal, 1)
+	signal.Notify(sigint, os.Interrupt)
+wait:
+	for _, r := range replays {
+		select {
+		case <-r.Done():
+		case <-sigint:
+			break wait
+		}
+	}
+	// lets the last callbacks run.
+	time.Sleep(500 * time.Millisecond)
+
+	if err := z.stopClimate(); err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "climate and alarm logs written in '%s'\n", dir)
+	return nil
+}
+
+func init() {
+	_, err := parser.AddCommand("replay",
+		"replays recorded CAN traffic",
+		"replays the frames received in a candump log, as recorded with 'can-log-directory', through the climate control of a season file, to reproduce alarms offline.",
+		&ReplayCommand{})
+	if err != nil {
+		panic(err.Error())
+	}
+}
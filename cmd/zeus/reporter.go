@@ -1,5 +1,76 @@
 package main
 
+import (
+	"time"
+
+	"github.com/formicidae-tracker/zeus/internal/zeus"
+)
+
 type Reporter interface {
 	Report(chan<- struct{})
 }
+
+// zoneStream holds the channels of a reporter that is at the same
+// time a ClimateReporter, a TargetReporter and an AlarmReporter.
+type zoneStream struct {
+	reports chan zeus.ClimateReport
+	targets chan zeus.ClimateTarget
+	alarms  chan zeus.AlarmEvent
+}
+
+func newZoneStream() zoneStream {
+	return zoneStream{
+		reports: make(chan zeus.ClimateReport, 10),
+		targets: make(chan zeus.ClimateTarget, 1),
+		alarms:  make(chan zeus.AlarmEvent, 10),
+	}
+}
+
+func (s *zoneStream) ReportChannel() chan<- zeus.ClimateReport {
+	return s.reports
+}
+
+func (s *zoneStream) TargetChannel() chan<- zeus.ClimateTarget {
+	return s.targets
+}
+
+func (s *zoneStream) AlarmChannel() chan<- zeus.AlarmEvent {
+	return s.alarms
+}
+
+// zoneStreamHandler receives the items of a zoneStream.
+type zoneStreamHandler interface {
+	report(zeus.ClimateReport)
+	target(zeus.ClimateTarget)
+	alarm(zeus.AlarmEvent)
+}
+
+// stream dispatches all items to h until every channel is
+// closed. tick, if non-nil, is called every time a value is received
+// on ticks.
+func (s zoneStream) stream(h zoneStreamHandler, ticks <-chan time.Time, tick func()) {
+	for s.reports != nil || s.targets != nil || s.alarms != nil {
+		select {
+		case r, ok := <-s.reports:
+			if ok == false {
+				s.reports = nil
+			} else {
+				h.report(r)
+			}
+		case t, ok := <-s.targets:
+			if ok == false {
+				s.targets = nil
+			} else {
+				h.target(t)
+			}
+		case e, ok := <-s.alarms:
+			if ok == false {
+				s.alarms = nil
+			} else {
+				h.alarm(e)
+			}
+		case <-ticks:
+			tick()
+		}
+	}
+}
@@ -0,0 +1,227 @@
+package main
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/formicidae-tracker/zeus/internal/zeus"
+)
+
+// rotatingFile is a log file that is rotated once it reaches a
+// maximal size or age. The rotated segments of <name> are named
+// <name>.<N>.gz, N increasing with each rotation. Each segment starts
+// with the same header, so it can be read on its own.
+type rotatingFile struct {
+	filename string
+	policy   LogRotationDefinition
+	header   []byte
+
+	file   *os.File
+	size   int64
+	opened time.Time
+	next   int
+}
+
+// NewRotatingFile takes ownership of an already created log file and
+// writes header to it.
+func NewRotatingFile(file *os.File, header string, policy LogRotationDefinition) (*rotatingFile, error) {
+	return newRotatingFile(file, header, policy, false)
+}
+
+// AppendRotatingFile takes ownership of a log file opened for
+// appending, which already starts with header if it is not empty.
+func AppendRotatingFile(file *os.File, header string, policy LogRotationDefinition) (*rotatingFile, error) {
+	return newRotatingFile(file, header, policy, true)
+}
+
+func newRotatingFile(file *os.File, header string, policy LogRotationDefinition, appending bool) (*rotatingFile, error) {
+	res := &rotatingFile{
+		filename: file.Name(),
+		policy:   policy,
+		header:   []byte(header),
+		file:     file,
+		opened:   time.Now(),
+		next:     1,
+	}
+	segments, err := zeus.ListLogSegments(res.filename)
+	if err != nil {
+		return nil, err
+	}
+	if len(segments) > 0 {
+		res.next = segments[len(segments)-1].Index + 1
+	}
+	if appending == true {
+		info, err := file.Stat()
+		if err != nil {
+			return nil, err
+		}
+		res.size = info.Size()
+	}
+	if res.size == 0 {
+		if err := res.writeHeader(); err != nil {
+			return nil, err
+		}
+	}
+	return res, res.prune()
+}
+
+func (f *rotatingFile) writeHeader() error {
+	n, err := f.file.Write(f.header)
+	f.size += int64(n)
+	return err
+}
+
+func (f *rotatingFile) needsRotation(incoming int) bool {
+	if f.size <= int64(len(f.header)) {
+		// never rotates an empty segment
+		return false
+	}
+	if f.policy.MaxSize > 0 && f.size+int64(incoming) > f.policy.MaxSize {
+		return true
+	}
+	return f.policy.MaxAge > 0 && time.Since(f.opened) >= f.policy.MaxAge
+}
+
+func (f *rotatingFile) Write(p []byte) (int, error) {
+	if f.file == nil {
+		return 0, os.ErrClosed
+	}
+	if f.needsRotation(len(p)) == true {
+		if err := f.rotate(); err != nil {
+			return 0, fmt.Errorf("could not rotate '%s': %w", f.filename, err)
+		}
+	}
+	n, err := f.file.Write(p)
+	f.size += int64(n)
+	return n, err
+}
+
+func (f *rotatingFile) rotate() error {
+	if err := f.file.Close(); err != nil {
+		return err
+	}
+	f.file = nil
+	segment := fmt.Sprintf("%s.%d", f.filename, f.next)
+	if err := os.Rename(f.filename, segment); err != nil {
+		return err
+	}
+	f.next += 1
+
+	var err error
+	f.file, err = os.Create(f.filename)
+	if err != nil {
+		return err
+	}
+	f.size = 0
+	f.opened = time.Now()
+	if err := f.writeHeader(); err != nil {
+		return err
+	}
+
+	if err := compressSegment(segment); err != nil {
+		return err
+	}
+	return f.prune()
+}
+
+func compressSegment(filename string) error {
+	in, err := os.Open(filename)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	tmpname := filename + ".gz.tmp"
+	out, err := os.Create(tmpname)
+	if err != nil {
+		return err
+	}
+	w := gzip.NewWriter(out)
+	if _, err := io.Copy(w, in); err != nil {
+		out.Close()
+		return err
+	}
+	if err := w.Close(); err != nil {
+		out.Close()
+		return err
+	}
+	if err := out.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(tmpname, filename+".gz"); err != nil {
+		return err
+	}
+	return os.Remove(filename)
+}
+
+// prune removes rotated segments older than the retention.
+func (f *rotatingFile) prune() error {
+	if f.policy.Retention <= 0 {
+		return nil
+	}
+	segments, err := zeus.ListLogSegments(f.filename)
+	if err != nil {
+		return err
+	}
+	for _, s := range segments {
+		info, err := os.Stat(s.Filename)
+		if err != nil {
+			return err
+		}
+		if time.Since(info.ModTime()) < f.policy.Retention {
+			continue
+		}
+		if err := os.Remove(s.Filename); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// pruneZoneLogs removes the climate and alarm logs of zone in dir,
+// from all previous climate starts, and their rotated segments once
+// older than retention. The open logs are kept.
+func pruneZoneLogs(dir, zone string, retention time.Duration, open ...string) error {
+	if retention <= 0 {
+		return nil
+	}
+	keep := make(map[string]bool)
+	for _, filename := range open {
+		keep[filename] = true
+	}
+	for _, ftype := range []string{"climate", "alarms"} {
+		matches, err := filepath.Glob(filepath.Join(dir, zeus.GlobEscape(zone)+".*."+ftype+".*"))
+		if err != nil {
+			return err
+		}
+		for _, m := range matches {
+			if keep[m] == true {
+				continue
+			}
+			info, err := os.Stat(m)
+			if err != nil {
+				return err
+			}
+			if time.Since(info.ModTime()) < retention {
+				continue
+			}
+			if err := os.Remove(m); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (f *rotatingFile) Close() error {
+	if f.file == nil {
+		return nil
+	}
+	err := f.file.Close()
+	f.file = nil
+	return err
+}
@@ -0,0 +1,127 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/formicidae-tracker/zeus/internal/zeus"
+	. "gopkg.in/check.v1"
+)
+
+type RotatingFileSuite struct {
+	TmpDir string
+}
+
+var _ = Suite(&RotatingFileSuite{})
+
+func (s *RotatingFileSuite) SetUpTest(c *C) {
+	var err error
+	s.TmpDir, err = ioutil.TempDir("", "zeus-rotation")
+	c.Assert(err, IsNil)
+}
+
+func (s *RotatingFileSuite) TearDownTest(c *C) {
+	c.Check(os.RemoveAll(s.TmpDir), IsNil)
+}
+
+func (s *RotatingFileSuite) TestClimateLogRotation(c *C) {
+	cr, filename, err := NewFileClimateReporter(filepath.Join(s.TmpDir, "box.climate.txt"), 1,
+		LogRotationDefinition{MaxSize: 200})
+	c.Assert(err, IsNil)
+	ready := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		cr.Report(ready)
+		close(done)
+	}()
+	<-ready
+
+	start := cr.(*fileClimateReporter).Start
+	expected := []zeus.ClimateReport{}
+	for i := 0; i < 20; i++ {
+		r := zeus.ClimateReport{
+			Time:         start.Add(time.Duration(i) * time.Second),
+			Humidity:     zeus.Humidity(40 + i),
+			Temperatures: []zeus.Temperature{21, 22},
+		}
+		expected = append(expected, r)
+		cr.ReportChannel() <- r
+	}
+	close(cr.ReportChannel())
+	close(cr.(TargetReporter).TargetChannel())
+	<-done
+
+	segments, err := zeus.ListLogSegments(filename)
+	c.Assert(err, IsNil)
+	c.Check(len(segments) > 1, Equals, true)
+	for i, seg := range segments {
+		c.Check(seg.Index, Equals, i+1)
+		c.Check(seg.Compressed, Equals, true)
+	}
+
+	reports, err := zeus.ReadClimateFile(filename)
+	c.Assert(err, IsNil)
+	c.Assert(len(reports), Equals, len(expected))
+	for i, r := range reports {
+		c.Check(r.Time.Equal(expected[i].Time), Equals, true)
+		c.Check(r.Humidity, Equals, expected[i].Humidity)
+		c.Check(r.Temperatures, DeepEquals, expected[i].Temperatures)
+	}
+}
+
+func (s *RotatingFileSuite) TestAlarmLogReadsInterruptedRotation(c *C) {
+	filename := filepath.Join(s.TmpDir, "box.alarms.txt")
+	ar, err := NewFileAlarmReporter(filename, LogRotationDefinition{MaxSize: 10})
+	c.Assert(err, IsNil)
+	ready := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		ar.Report(ready)
+		close(done)
+	}()
+	<-ready
+	start := time.Now().Round(0)
+	for i := 0; i < 4; i++ {
+		ar.AlarmChannel() <- zeus.AlarmEvent{
+			Identifier: "foo",
+			Status:     zeus.AlarmStatus(i % 2),
+			Time:       start.Add(time.Duration(i) * time.Second),
+		}
+	}
+	close(ar.AlarmChannel())
+	<-done
+
+	// simulates a rotation interrupted before compression
+	c.Assert(os.Rename(filename, filename+".4"), IsNil)
+
+	events, err := zeus.ReadAlarmLogFile(filename)
+	c.Assert(err, IsNil)
+	c.Assert(len(events), Equals, 4)
+	for i, e := range events {
+		c.Check(e.Time.Equal(start.Add(time.Duration(i)*time.Second)), Equals, true)
+	}
+}
+
+func (s *RotatingFileSuite) TestRetention(c *C) {
+	filename := filepath.Join(s.TmpDir, "box.alarms.txt")
+	old := time.Now().Add(-48 * time.Hour)
+	for _, seg := range []string{".1.gz", ".2.gz"} {
+		c.Assert(ioutil.WriteFile(filename+seg, nil, 0644), IsNil)
+		c.Assert(os.Chtimes(filename+seg, old, old), IsNil)
+	}
+	c.Assert(ioutil.WriteFile(filename+".3.gz", nil, 0644), IsNil)
+
+	file, err := os.Create(filename)
+	c.Assert(err, IsNil)
+	f, err := NewRotatingFile(file, "", LogRotationDefinition{Retention: 24 * time.Hour})
+	c.Assert(err, IsNil)
+	defer f.Close()
+	c.Check(f.next, Equals, 4)
+
+	segments, err := zeus.ListLogSegments(filename)
+	c.Assert(err, IsNil)
+	c.Assert(len(segments), Equals, 1)
+	c.Check(segments[0].Index, Equals, 3)
+}
@@ -2,16 +2,19 @@ package main
 
 import (
 	"context"
+	"fmt"
+	"math"
 	"os"
 	"path"
 	"sync"
-	"time"
 
 	olympuspb "github.com/formicidae-tracker/olympus/pkg/api"
 	"github.com/formicidae-tracker/olympus/pkg/tm"
 	"github.com/formicidae-tracker/zeus/internal/zeus"
 	"github.com/mitchellh/copystructure"
 	"github.com/sirupsen/logrus"
+	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/reflect/protoreflect"
 	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
@@ -22,10 +25,12 @@ type RPCReporter struct {
 	lastTarget  *olympuspb.ClimateTarget
 
 	runner ZoneClimateRunner
+	outbox *uplinkOutbox
 
 	climateReports chan zeus.ClimateReport
 	alarmReports   chan zeus.AlarmEvent
 	climateTargets chan zeus.ClimateTarget
+	hosts          chan string
 	connected      chan bool
 
 	log *logrus.Entry
@@ -43,6 +48,17 @@ func (r *RPCReporter) TargetChannel() chan<- zeus.ClimateTarget {
 	return r.climateTargets
 }
 
+// SetOlympusHost reconnects the reporter to addr. Unacknowledged
+// reports and alarms are sent to the new host. It never blocks, only
+// the last host is kept until the reporter reconnects.
+func (r *RPCReporter) SetOlympusHost(addr string) {
+	select {
+	case <-r.hosts:
+	default:
+	}
+	r.hosts <- addr
+}
+
 func buildBackLog(reports []zeus.ClimateReport, events []zeus.AlarmEvent) *olympuspb.ClimateUpStream {
 	res := &olympuspb.ClimateUpStream{
 		Reports: make([]*olympuspb.ClimateReport, len(reports)),
@@ -64,11 +80,47 @@ func buildOlympusClimateReport(report zeus.ClimateReport) *olympuspb.ClimateRepo
 		temperatures[i] = float32(t)
 	}
 
-	return &olympuspb.ClimateReport{
+	res := &olympuspb.ClimateReport{
 		Time:         timestamppb.New(report.Time),
 		Humidity:     zeus.AsFloat32Pointer(report.Humidity),
 		Temperatures: temperatures,
 	}
+	// the olympus API does not define the derived quantities yet, they
+	// are appended as fields it ignores until it does.
+	if m, ok := interface{}(res).(protoreflect.ProtoMessage); ok == true {
+		reflected := m.ProtoReflect()
+		reflected.SetUnknown(appendDerivedFields(reflected.GetUnknown(), report))
+	}
+	return res
+}
+
+// Field numbers of the derived quantities in olympus climate reports,
+// following its time, humidity and temperatures fields.
+const (
+	olympusDewPointField         protowire.Number = 4
+	olympusAbsoluteHumidityField protowire.Number = 5
+	olympusVPDField              protowire.Number = 6
+)
+
+// appendDerivedFields appends the defined derived quantities of report
+// to b as protobuf float fields.
+func appendDerivedFields(b []byte, report zeus.ClimateReport) []byte {
+	fields := []struct {
+		number protowire.Number
+		value  *float32
+	}{
+		{olympusDewPointField, zeus.AsFloat32Pointer(report.DewPoint())},
+		{olympusAbsoluteHumidityField, zeus.Float32Pointer(report.AbsoluteHumidity())},
+		{olympusVPDField, zeus.Float32Pointer(report.VaporPressureDeficit())},
+	}
+	for _, f := range fields {
+		if f.value == nil {
+			continue
+		}
+		b = protowire.AppendTag(b, f.number, protowire.Fixed32Type)
+		b = protowire.AppendFixed32(b, math.Float32bits(*f.value))
+	}
+	return b
 }
 
 func buildOlympusAlarmUpdate(event zeus.AlarmEvent) *olympuspb.AlarmUpdate {
@@ -122,26 +174,27 @@ func buildOlympusClimateTarget(target zeus.ClimateTarget) *olympuspb.ClimateTarg
 	return res
 }
 
+// paginateAsync sends the backlog one page at a time, waiting for
+// olympus to receive each page, and calls done once all pages were
+// received. It stops at the first page that could not be sent.
 func paginateAsync(c context.Context,
-	ch chan<- *olympuspb.ClimateUpStream,
+	send func(context.Context, *olympuspb.ClimateUpStream) error,
 	pageSize int,
 	reports []zeus.ClimateReport,
-	events []zeus.AlarmEvent) {
-
-	defer close(ch)
+	events []zeus.AlarmEvent,
+	done func()) {
 
 	push := func(m *olympuspb.ClimateUpStream) bool {
-		select {
-		case <-c.Done():
+		if c.Err() != nil {
 			return true
-		case ch <- m:
-			time.Sleep(50 * time.Millisecond)
 		}
-		return false
+		return send(c, m) != nil
 	}
 
 	if pageSize <= 0 {
-		push(buildBackLog(reports, events))
+		if push(buildBackLog(reports, events)) == false {
+			done()
+		}
 		return
 	}
 
@@ -165,18 +218,25 @@ func paginateAsync(c context.Context,
 		}
 
 	}
+	done()
 }
 
+// paginateBacklogs sends the full logs when olympus asks for them.
 func (r *RPCReporter) paginateBacklogs(c context.Context,
-	confirmation *olympuspb.ClimateRegistrationConfirmation) <-chan *olympuspb.ClimateUpStream {
+	confirmation *olympuspb.ClimateRegistrationConfirmation,
+	send func(context.Context, *olympuspb.ClimateUpStream) error) {
 	if confirmation == nil || r.runner == nil {
-		return nil
+		return
 	}
 
-	if confirmation.PageSize <= 0 && confirmation.SendBacklogs == false {
-		return nil
+	// Unacknowledged data is replayed from the outbox, we only need
+	// to send the full logs when olympus explicitly asks for them.
+	if confirmation.SendBacklogs == false {
+		return
 	}
 
+	sent := r.outbox.LastSeq()
+
 	climateLog, err := r.runner.ClimateLog(0, 0)
 	if err != nil {
 		r.log.WithError(err).Warn("could not get climate log")
@@ -197,16 +257,35 @@ func (r *RPCReporter) paginateBacklogs(c context.Context,
 	}
 
 	if eventLog == nil && climateLog == nil {
-		return nil
+		return
 	}
 
-	res := make(chan *olympuspb.ClimateUpStream)
-
-	go paginateAsync(c, res, int(confirmation.PageSize), climateLog, eventLog)
+	paginateAsync(c, send, int(confirmation.PageSize), climateLog, eventLog, func() {
+		// logs contained everything pushed to the outbox before
+		// they were read.
+		if err := r.outbox.Ack(sent); err != nil {
+			r.log.WithError(err).Warn("could not acknowledge outbox")
+		}
+	})
+}
 
+func buildOutboxUpStream(entries []outboxEntry, backlog bool) *olympuspb.ClimateUpStream {
+	res := &olympuspb.ClimateUpStream{
+		Backlog: backlog,
+	}
+	for _, e := range entries {
+		if e.Report != nil {
+			res.Reports = append(res.Reports, buildOlympusClimateReport(*e.Report))
+		}
+		if e.Alarm != nil {
+			res.Alarms = append(res.Alarms, buildOlympusAlarmUpdate(*e.Alarm))
+		}
+	}
 	return res
 }
 
+// buidUpStreamFromInputChannels pushes climate reports and alarm
+// events to the outbox, and returns a channel of the targets to send.
 func (r *RPCReporter) buidUpStreamFromInputChannels() <-chan *olympuspb.ClimateUpStream {
 	res := make(chan *olympuspb.ClimateUpStream, 10)
 
@@ -217,6 +296,12 @@ func (r *RPCReporter) buidUpStreamFromInputChannels() <-chan *olympuspb.ClimateU
 		}
 	}
 
+	pushOutbox := func(e outboxEntry) {
+		if err := r.outbox.Push(e); err != nil {
+			r.log.WithError(err).Error("could not write to outbox")
+		}
+	}
+
 	go func() {
 		defer close(res)
 		for {
@@ -240,10 +325,7 @@ func (r *RPCReporter) buidUpStreamFromInputChannels() <-chan *olympuspb.ClimateU
 					r.climateReports = nil
 				} else {
 					r.log.WithField("report", report).Debug("sending report")
-					mayPush(&olympuspb.ClimateUpStream{
-						Reports: []*olympuspb.ClimateReport{
-							buildOlympusClimateReport(report),
-						}})
+					pushOutbox(outboxEntry{Report: &report})
 				}
 			case event, ok := <-r.alarmReports:
 				if ok == false {
@@ -254,10 +336,7 @@ func (r *RPCReporter) buidUpStreamFromInputChannels() <-chan *olympuspb.ClimateU
 					continue
 				} else {
 					r.log.WithField("event", event).Debug("sending event")
-					mayPush(&olympuspb.ClimateUpStream{
-						Alarms: []*olympuspb.AlarmUpdate{
-							buildOlympusAlarmUpdate(event),
-						}})
+					pushOutbox(outboxEntry{Alarm: &event})
 				}
 			}
 		}
@@ -265,30 +344,45 @@ func (r *RPCReporter) buidUpStreamFromInputChannels() <-chan *olympuspb.ClimateU
 	return res
 }
 
+type outboxResult struct {
+	seq uint64
+	err error
+}
+
 func (r *RPCReporter) Report(ready chan<- struct{}) {
 	ctx, cancelTask := context.WithCancel(context.Background())
 	wg := sync.WaitGroup{}
 	defer func() {
 		cancelTask()
 		wg.Wait()
+		if err := r.outbox.Close(); err != nil {
+			r.log.WithError(err).Error("could not close outbox")
+		}
 	}()
 
 	var cancelBacklog context.CancelFunc = func() {}
 
 	upstream := r.buidUpStreamFromInputChannels()
 
-	var backlogs <-chan *olympuspb.ClimateUpStream
-	task := olympuspb.NewClimateTask(ctx, r.addr, r.declaration)
-
-	wg.Add(1)
-	go func() {
-		defer wg.Done()
-		if err := task.Run(); err != nil {
-			r.log.WithError(err).Error("gRPC task error")
-		}
-	}()
+	// the task is restarted when the olympus host changes, so
+	// goroutines are given the task they use.
+	var task *olympuspb.ClimateTask
+	var cancelRun context.CancelFunc = func() {}
+	startTask := func() {
+		var runContext context.Context
+		runContext, cancelRun = context.WithCancel(ctx)
+		task = olympuspb.NewClimateTask(runContext, r.addr, r.declaration)
+		wg.Add(1)
+		go func(task *olympuspb.ClimateTask) {
+			defer wg.Done()
+			if err := task.Run(); err != nil {
+				r.log.WithError(err).Error("gRPC task error")
+			}
+		}(task)
+	}
+	startTask()
 
-	pushAndLogError := func(m *olympuspb.ClimateUpStream) {
+	pushAndLogError := func(task *olympuspb.ClimateTask, m *olympuspb.ClimateUpStream) {
 
 		var res olympuspb.RequestResult[*olympuspb.ClimateDownStream]
 
@@ -312,6 +406,47 @@ func (r *RPCReporter) Report(ready chan<- struct{}) {
 
 	}
 
+	sendBacklog := func(task *olympuspb.ClimateTask) func(context.Context, *olympuspb.ClimateUpStream) error {
+		return func(c context.Context, m *olympuspb.ClimateUpStream) error {
+			select {
+			case <-c.Done():
+				return c.Err()
+			case res := <-task.Request(m):
+				if res.Error != nil {
+					r.log.WithError(res.Error).Warn("could not send backlog")
+				}
+				return res.Error
+			}
+		}
+	}
+
+	// The outbox is flushed one page at a time, in order, and
+	// entries are only dropped once olympus acknowledged them. It
+	// waits for the backlog, which contains its entries.
+	connected := false
+	catchingUp := false
+	pageSize := outboxPageSize
+	var flushing chan outboxResult
+	var backlogDone chan struct{}
+	flush := func() {
+		if connected == false || flushing != nil || backlogDone != nil {
+			return
+		}
+		entries := r.outbox.Pending(pageSize)
+		if len(entries) == 0 {
+			catchingUp = false
+			return
+		}
+		up := buildOutboxUpStream(entries, catchingUp)
+		if len(up.Reports) > 0 {
+			r.lastReport = up.Reports[len(up.Reports)-1]
+		}
+		flushing = make(chan outboxResult, 1)
+		go func(task *olympuspb.ClimateTask, res chan<- outboxResult, seq uint64) {
+			res <- outboxResult{seq: seq, err: (<-task.Request(up)).Error}
+		}(task, flushing, entries[len(entries)-1].Seq)
+	}
+
 	close(ready)
 
 	r.log.Debug("started")
@@ -326,32 +461,66 @@ func (r *RPCReporter) Report(ready chan<- struct{}) {
 			if up.Target != nil {
 				r.lastTarget = up.Target
 			}
-			if len(up.Reports) != 0 {
-				r.lastReport = up.Reports[len(up.Reports)-1]
+			go pushAndLogError(task, up)
+		case addr := <-r.hosts:
+			if addr == r.addr {
+				continue
 			}
-			go pushAndLogError(up)
-		case up, ok := <-backlogs:
-			if ok == false {
-				backlogs = nil
-			} else {
-				go pushAndLogError(up)
+			r.log.WithField("olympus", addr).Info("changing olympus host")
+			r.addr = addr
+			cancelBacklog()
+			cancelRun()
+			// a page being flushed is sent again to the new host.
+			connected = false
+			flushing = nil
+			backlogDone = nil
+			startTask()
+		case <-r.outbox.Signal():
+			flush()
+		case <-backlogDone:
+			backlogDone = nil
+			flush()
+		case res := <-flushing:
+			flushing = nil
+			if res.err != nil {
+				r.log.WithError(res.err).WithField("pending", r.outbox.Len()).
+					Warn("could not flush outbox")
+				continue
+			}
+			if err := r.outbox.Ack(res.seq); err != nil {
+				r.log.WithError(err).Error("could not acknowledge outbox")
 			}
+			flush()
 		case down, ok := <-task.Confirmations():
 			if ok == false {
 				cancelBacklog()
 				return
 			}
+			connected = down.Error == nil
 			if down.Error == nil {
-				r.log.Info("connected")
+				r.log.WithField("pending", r.outbox.Len()).Info("connected")
 				cancelBacklog()
 				var backlogContext context.Context
 				backlogContext, cancelBacklog = context.WithCancel(ctx)
-				backlogs = r.paginateBacklogs(backlogContext,
-					down.Confirmation.RegistrationConfirmation)
+				confirmation := down.Confirmation.RegistrationConfirmation
+				send := sendBacklog(task)
+				backlogDone = make(chan struct{})
+				wg.Add(1)
+				go func(done chan<- struct{}) {
+					defer wg.Done()
+					defer close(done)
+					r.paginateBacklogs(backlogContext, confirmation, send)
+				}(backlogDone)
+				pageSize = outboxPageSize
+				if confirmation != nil && confirmation.PageSize > 0 {
+					pageSize = int(confirmation.PageSize)
+				}
 				if lastState := r.lastState(); lastState != nil {
 					r.log.WithField("upstream", lastState).Debug("sending last state")
-					go pushAndLogError(lastState)
+					go pushAndLogError(task, lastState)
 				}
+				catchingUp = r.outbox.Len() > 0
+				flush()
 			} else {
 				r.log.WithError(down.Error).Warn("connection failure")
 			}
@@ -379,10 +548,13 @@ func (r *RPCReporter) lastState() *olympuspb.ClimateUpStream {
 		cpy, _ := copystructure.Copy(r.lastTarget)
 		lastTarget = cpy.(*olympuspb.ClimateTarget)
 	}
-	return &olympuspb.ClimateUpStream{
-		Reports: []*olympuspb.ClimateReport{lastReport},
-		Target:  lastTarget,
+	res := &olympuspb.ClimateUpStream{
+		Target: lastTarget,
+	}
+	if lastReport != nil {
+		res.Reports = []*olympuspb.ClimateReport{lastReport}
 	}
+	return res
 }
 
 type RPCReporterOptions struct {
@@ -391,6 +563,7 @@ type RPCReporterOptions struct {
 	climate        zeus.ZoneClimate
 	host           string
 	runner         ZoneClimateRunner
+	outboxFile     string
 }
 
 func (o *RPCReporterOptions) sanitize(hostname string) {
@@ -408,6 +581,14 @@ func NewRPCReporter(o RPCReporterOptions) (*RPCReporter, error) {
 
 	logger := tm.NewLogger(path.Join("zone", o.zone, "rpc"))
 
+	outbox, err := OpenUplinkOutbox(o.outboxFile)
+	if err != nil {
+		return nil, fmt.Errorf("could not open outbox: %w", err)
+	}
+	if outbox.skipped > 0 {
+		logger.WithField("entries", outbox.skipped).Warn("skipped corrupted outbox entries")
+	}
+
 	declaration := &olympuspb.ClimateDeclaration{
 		Host:           o.host,
 		Name:           o.zone,
@@ -422,7 +603,9 @@ func NewRPCReporter(o RPCReporterOptions) (*RPCReporter, error) {
 		climateReports: make(chan zeus.ClimateReport, 20),
 		alarmReports:   make(chan zeus.AlarmEvent, 20),
 		climateTargets: make(chan zeus.ClimateTarget, 20),
+		hosts:          make(chan string, 1),
 		log:            logger,
 		runner:         o.runner,
+		outbox:         outbox,
 	}, nil
 }
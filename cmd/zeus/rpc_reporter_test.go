@@ -1,7 +1,10 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"io"
+	"math"
 	"net"
 	"time"
 
@@ -9,6 +12,7 @@ import (
 	"github.com/formicidae-tracker/zeus/internal/zeus"
 	"github.com/sirupsen/logrus"
 	"google.golang.org/grpc"
+	"google.golang.org/protobuf/encoding/protowire"
 	. "gopkg.in/check.v1"
 )
 
@@ -126,3 +130,148 @@ func (s *RPCClimateReporterSuite) TestEnd2End(c *C) {
 	c.Assert(ok, Equals, false)
 
 }
+
+// backlogRunner only provides the logs sent as backlog.
+type backlogRunner struct {
+	ZoneClimateRunner
+	reports []zeus.ClimateReport
+	events  []zeus.AlarmEvent
+}
+
+func (r backlogRunner) ClimateLog(start, end int) ([]zeus.ClimateReport, error) {
+	return r.reports, nil
+}
+
+func (r backlogRunner) AlarmLog(start, end int) ([]zeus.AlarmEvent, error) {
+	return r.events, nil
+}
+
+func (s *RPCClimateReporterSuite) TestBacklogAcknowledgesOutboxOnceSent(c *C) {
+	start := time.Now()
+	reports := []zeus.ClimateReport{
+		{Time: start, Humidity: 50},
+		{Time: start.Add(time.Second), Humidity: 51},
+		{Time: start.Add(2 * time.Second), Humidity: 52},
+	}
+	r, err := NewRPCReporter(RPCReporterOptions{
+		zone:   "box",
+		host:   "myself",
+		runner: backlogRunner{reports: reports},
+	})
+	c.Assert(err, IsNil)
+	defer r.outbox.Close()
+	for i := range reports {
+		c.Assert(r.outbox.Push(outboxEntry{Report: &reports[i]}), IsNil)
+	}
+	confirmation := &olympuspb.ClimateRegistrationConfirmation{SendBacklogs: true, PageSize: 1}
+
+	var received []*olympuspb.ClimateUpStream
+	disconnected := func(_ context.Context, m *olympuspb.ClimateUpStream) error {
+		if len(received) == 2 {
+			return errors.New("stream closed")
+		}
+		received = append(received, m)
+		return nil
+	}
+	r.paginateBacklogs(context.Background(), confirmation, disconnected)
+	c.Check(received, HasLen, 2)
+	c.Check(r.outbox.Len(), Equals, 3)
+
+	received = nil
+	r.paginateBacklogs(context.Background(), confirmation,
+		func(_ context.Context, m *olympuspb.ClimateUpStream) error {
+			received = append(received, m)
+			return nil
+		})
+	c.Assert(received, HasLen, 3)
+	for i, m := range received {
+		c.Check(m.Backlog, Equals, true)
+		c.Assert(m.Reports, HasLen, 1)
+		c.Check(*m.Reports[0].Humidity, Equals, float32(50+i))
+	}
+	c.Check(r.outbox.Len(), Equals, 0)
+}
+
+func (s *RPCClimateReporterSuite) TestChangesOlympusHost(c *C) {
+	server := grpc.NewServer(olympuspb.DefaultServerOptions...)
+	other := &olympusStub{received: make(chan *olympuspb.ClimateUpStream, 10)}
+	olympuspb.RegisterOlympusServer(server, other)
+	l, err := net.Listen("tcp", "localhost:12346")
+	c.Assert(err, IsNil)
+	go server.Serve(l)
+	defer server.Stop()
+
+	r, err := NewRPCReporter(RPCReporterOptions{
+		zone:           "box",
+		olympusAddress: "localhost:12345",
+		host:           "myself",
+		runner:         nil,
+	})
+	c.Assert(err, IsNil)
+	r.connected = make(chan bool)
+	ready := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		r.Report(ready)
+		close(done)
+	}()
+	<-ready
+	defer func() {
+		close(r.ReportChannel())
+		close(r.AlarmChannel())
+		close(r.TargetChannel())
+		<-done
+	}()
+
+	receive := func(received <-chan *olympuspb.ClimateUpStream) *olympuspb.ClimateUpStream {
+		select {
+		case m := <-received:
+			return m
+		case <-time.After(5 * time.Second):
+			return nil
+		}
+	}
+
+	m := receive(s.olympus.received)
+	c.Assert(m, NotNil)
+	c.Check(m.Declaration, NotNil)
+	<-r.connected
+
+	r.SetOlympusHost("localhost:12346")
+	m = receive(other.received)
+	c.Assert(m, NotNil, Commentf("the new host was not declared to"))
+	c.Check(m.Declaration, NotNil)
+	<-r.connected
+
+	r.ReportChannel() <- zeus.ClimateReport{}
+	m = receive(other.received)
+	c.Assert(m, NotNil, Commentf("reports are not sent to the new host"))
+	c.Check(m.Reports, NotNil)
+}
+
+func (s *RPCClimateReporterSuite) TestAppendDerivedFields(c *C) {
+	b := appendDerivedFields(nil, zeus.ClimateReport{
+		Humidity:     50,
+		Temperatures: []zeus.Temperature{25},
+	})
+	values := make(map[protowire.Number]float64)
+	for len(b) > 0 {
+		number, kind, n := protowire.ConsumeTag(b)
+		c.Assert(n > 0, Equals, true)
+		c.Assert(kind, Equals, protowire.Fixed32Type)
+		b = b[n:]
+		v, n := protowire.ConsumeFixed32(b)
+		c.Assert(n > 0, Equals, true)
+		b = b[n:]
+		values[number] = float64(math.Float32frombits(v))
+	}
+	c.Assert(values, HasLen, 3)
+	c.Check(math.Abs(values[olympusDewPointField]-13.86) < 0.01, Equals, true)
+	c.Check(math.Abs(values[olympusAbsoluteHumidityField]-11.49) < 0.01, Equals, true)
+	c.Check(math.Abs(values[olympusVPDField]-1.581) < 0.001, Equals, true)
+
+	c.Check(appendDerivedFields(nil, zeus.ClimateReport{
+		Humidity:     zeus.UndefinedHumidity,
+		Temperatures: []zeus.Temperature{25},
+	}), HasLen, 0)
+}
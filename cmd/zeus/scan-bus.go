@@ -0,0 +1,88 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/atuleu/go-tablifier"
+	socketcan "github.com/atuleu/golang-socketcan"
+	flags "github.com/jessevdk/go-flags"
+)
+
+type ScanBusCommand struct {
+	Config  flags.Filename `short:"c" long:"config" description:"zeus configuration file, defaults to /etc/default/zeus.yml"`
+	Window  time.Duration  `short:"w" long:"window" description:"time to wait for answers to each ping" default:"500ms"`
+	Emulate bool           `long:"emulate" description:"scans emulated Arke devices instead of the CAN interface"`
+
+	Args struct {
+		Interface string
+	} `positional-args:"yes" required:"yes"`
+}
+
+type scanTableLine struct {
+	Device   string
+	Firmware string
+	Zone     string
+	Status   string
+}
+
+func (c *ScanBusCommand) Execute(args []string) error {
+	config, err := OpenConfigFromArg(c.Config)
+	if err != nil {
+		return err
+	}
+	factory := socketcan.NewRawInterface
+	if c.Emulate == true || config.Emulate == true {
+		factory = emulatorFactory(config.Zones, config.plantParameters(), nil, 1.0)
+	}
+	intf, err := factory(c.Args.Interface)
+	if err != nil {
+		return err
+	}
+	defer intf.Close()
+
+	scanner := NewBusScanner(intf)
+	scanner.Window = c.Window
+	devices, err := scanner.Scan()
+	if err != nil {
+		return err
+	}
+	AssignZones(devices, c.Args.Interface, config.Zones)
+
+	lines := make([]scanTableLine, 0, len(devices))
+	for _, d := range devices {
+		line := scanTableLine{
+			Device:   fmt.Sprintf("%s.%d", Name(d.Class), d.ID),
+			Firmware: "n.a.",
+			Zone:     d.Zone,
+			Status:   "OK",
+		}
+		if d.Version.Known() == true {
+			line.Firmware = d.Version.String()
+		}
+		if len(d.Zone) == 0 {
+			line.Zone = "-"
+			line.Status = "unconfigured"
+		}
+		if d.Conflict == true {
+			line.Status = "ID conflict"
+		}
+		lines = append(lines, line)
+	}
+	if len(lines) == 0 {
+		fmt.Printf("no device found on %s\n", c.Args.Interface)
+		return nil
+	}
+	tablifier.Tablify(lines)
+	return nil
+}
+
+func init() {
+	_, err := parser.AddCommand("scan-bus",
+		"lists devices on a CAN interface",
+		"Pings every Arke node class on a CAN interface, and lists the answering devices with their firmware version. It reports devices that are not assigned to any zone, and IDs used by several devices",
+		&ScanBusCommand{})
+	if err != nil {
+		panic(err.Error())
+	}
+}
@@ -0,0 +1,201 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/formicidae-tracker/libarke/src-go/arke"
+	yaml "gopkg.in/yaml.v2"
+)
+
+// FaultKind is the kind of a fault injected by a Scenario.
+type FaultKind string
+
+const (
+	// DeviceMissing stops the device from sending or answering any
+	// frame.
+	DeviceMissing FaultKind = "device-missing"
+	// FanStalled and FanAging report a failing fan of a Zeus or a
+	// Celaeno.
+	FanStalled FaultKind = "fan-stalled"
+	FanAging   FaultKind = "fan-aging"
+	// WaterEmpty empties the water tank. It is refilled when the
+	// fault ends.
+	WaterEmpty FaultKind = "water-empty"
+	// SensorNaN makes the Zeus sensor readout fail.
+	SensorNaN FaultKind = "sensor-nan"
+	// WatchdogReset reboots the device once, as its watchdog would.
+	WatchdogReset FaultKind = "watchdog-reset"
+	// BusDown brings the CAN bus down.
+	BusDown FaultKind = "bus-down"
+)
+
+var faultDevices = map[string]arke.NodeClass{
+	"zeus":    arke.ZeusClass,
+	"celaeno": arke.CelaenoClass,
+	"helios":  arke.HeliosClass,
+	"notus":   arke.NotusClass,
+}
+
+// Fault is a fault injected at a given time of a Scenario.
+type Fault struct {
+	Kind FaultKind `yaml:"fault"`
+	// At is the time of the fault since the start of the scenario.
+	At time.Duration `yaml:"at"`
+	// Duration of the fault, zero means it lasts until the end of the
+	// scenario.
+	Duration time.Duration `yaml:"duration"`
+	// Zone targeted by the fault, all zones if empty.
+	Zone string `yaml:"zone"`
+	// Device targeted by the fault: zeus, celaeno, helios or notus.
+	Device string `yaml:"device"`
+	// Fan is the index of the faulty Zeus fan.
+	Fan int `yaml:"fan"`
+}
+
+// Scenario is a list of faults scheduled over the emulated time of a
+// simulation.
+type Scenario struct {
+	Faults []Fault `yaml:"faults"`
+}
+
+// ReadScenarioFile reads a Scenario from a YAML file.
+func ReadScenarioFile(filename string) (*Scenario, error) {
+	content, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	res := &Scenario{}
+	if err := yaml.Unmarshal(content, res); err != nil {
+		return nil, err
+	}
+	return res, res.check()
+}
+
+func (f Fault) needsDevice() bool {
+	switch f.Kind {
+	case DeviceMissing, FanStalled, FanAging, WatchdogReset:
+		return true
+	default:
+		return false
+	}
+}
+
+func (f Fault) check() error {
+	switch f.Kind {
+	case DeviceMissing, FanStalled, FanAging, WaterEmpty, SensorNaN, WatchdogReset, BusDown:
+	default:
+		return fmt.Errorf("unknown fault '%s'", f.Kind)
+	}
+	if f.At < 0 || f.Duration < 0 {
+		return fmt.Errorf("invalid negative time")
+	}
+	if len(f.Device) == 0 {
+		if f.needsDevice() == true {
+			return fmt.Errorf("missing device")
+		}
+		return nil
+	}
+	if f.needsDevice() == false {
+		return fmt.Errorf("does not apply to a device")
+	}
+	c, ok := faultDevices[f.Device]
+	if ok == false {
+		return fmt.Errorf("unknown device '%s'", f.Device)
+	}
+	if f.Kind != FanStalled && f.Kind != FanAging {
+		return nil
+	}
+	switch c {
+	case arke.ZeusClass:
+		if f.Fan < 0 || f.Fan >= len(zeusFanNames) {
+			return fmt.Errorf("invalid zeus fan %d", f.Fan)
+		}
+	case arke.CelaenoClass:
+		if f.Fan != 0 {
+			return fmt.Errorf("invalid celaeno fan %d", f.Fan)
+		}
+	default:
+		return fmt.Errorf("%s has no fan", f.Device)
+	}
+	return nil
+}
+
+func (s *Scenario) check() error {
+	for i, f := range s.Faults {
+		if err := f.check(); err != nil {
+			return fmt.Errorf("fault %d (%s): %w", i, f.Kind, err)
+		}
+	}
+	return nil
+}
+
+// Class returns the class of the targeted device.
+func (f Fault) Class() arke.NodeClass {
+	return faultDevices[f.Device]
+}
+
+// ActiveAt returns true if the fault is active at time t of the
+// scenario.
+func (f Fault) ActiveAt(t time.Duration) bool {
+	if t < f.At {
+		return false
+	}
+	return f.Duration == 0 || t < f.At+f.Duration
+}
+
+// Targets returns true if the fault applies to the device of class c
+// in zone. Faults without a device apply to all devices of the zone.
+func (f Fault) Targets(zone string, c arke.NodeClass) bool {
+	if len(f.Zone) > 0 && f.Zone != zone {
+		return false
+	}
+	return len(f.Device) == 0 || f.Class() == c
+}
+
+// scenarioPlayer follows the faults of a Scenario over time.
+type scenarioPlayer struct {
+	faults  []Fault
+	active  []bool
+	elapsed time.Duration
+}
+
+func newScenarioPlayer(s *Scenario) *scenarioPlayer {
+	res := &scenarioPlayer{}
+	if s != nil {
+		res.faults = s.Faults
+		res.active = make([]bool, len(s.Faults))
+	}
+	return res
+}
+
+// advance moves the player by dt, and returns the faults that started
+// or ended.
+func (p *scenarioPlayer) advance(dt time.Duration) (started, ended []Fault) {
+	p.elapsed += dt
+	for i, f := range p.faults {
+		active := f.ActiveAt(p.elapsed)
+		if active == p.active[i] {
+			continue
+		}
+		p.active[i] = active
+		if active == true {
+			started = append(started, f)
+		} else {
+			ended = append(ended, f)
+		}
+	}
+	return started, ended
+}
+
+// activeFaults returns the currently active faults.
+func (p *scenarioPlayer) activeFaults() []Fault {
+	var res []Fault
+	for i, f := range p.faults {
+		if p.active[i] == true {
+			res = append(res, f)
+		}
+	}
+	return res
+}
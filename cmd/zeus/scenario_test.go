@@ -0,0 +1,101 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/formicidae-tracker/libarke/src-go/arke"
+	. "gopkg.in/check.v1"
+)
+
+type ScenarioSuite struct{}
+
+var _ = Suite(&ScenarioSuite{})
+
+func (s *ScenarioSuite) TestRead(c *C) {
+	filename := filepath.Join(c.MkDir(), "scenario.yml")
+	c.Assert(os.WriteFile(filename, []byte(`---
+faults:
+  - fault: device-missing
+    device: celaeno
+    zone: nest
+    at: 10m
+    duration: 5m
+  - fault: fan-stalled
+    device: zeus
+    fan: 2
+    at: 1h
+  - fault: bus-down
+    at: 2h
+    duration: 30s
+`), 0644), IsNil)
+
+	scenario, err := ReadScenarioFile(filename)
+	c.Assert(err, IsNil)
+	c.Check(scenario.Faults, DeepEquals, []Fault{
+		{Kind: DeviceMissing, Device: "celaeno", Zone: "nest", At: 10 * time.Minute, Duration: 5 * time.Minute},
+		{Kind: FanStalled, Device: "zeus", Fan: 2, At: time.Hour},
+		{Kind: BusDown, At: 2 * time.Hour, Duration: 30 * time.Second},
+	})
+	c.Check(scenario.Faults[0].Targets("nest", arke.CelaenoClass), Equals, true)
+	c.Check(scenario.Faults[0].Targets("nest", arke.ZeusClass), Equals, false)
+	c.Check(scenario.Faults[0].Targets("box", arke.CelaenoClass), Equals, false)
+	c.Check(scenario.Faults[2].Targets("box", arke.HeliosClass), Equals, true)
+
+	_, err = ReadScenarioFile(filepath.Join(c.MkDir(), "does-not-exist.yml"))
+	c.Check(err, NotNil)
+}
+
+func (s *ScenarioSuite) TestErrorChecking(c *C) {
+	testdata := []struct {
+		Fault   Fault
+		Message string
+	}{
+		{Fault{Kind: WaterEmpty}, ""},
+		{Fault{Kind: "explosion"}, "fault 0 \\(explosion\\): unknown fault 'explosion'"},
+		{Fault{Kind: BusDown, At: -time.Minute}, "fault 0 \\(bus-down\\): invalid negative time"},
+		{Fault{Kind: DeviceMissing}, "fault 0 \\(device-missing\\): missing device"},
+		{Fault{Kind: DeviceMissing, Device: "hermes"}, "fault 0 \\(device-missing\\): unknown device 'hermes'"},
+		{Fault{Kind: SensorNaN, Device: "zeus"}, "fault 0 \\(sensor-nan\\): does not apply to a device"},
+		{Fault{Kind: FanAging, Device: "zeus", Fan: 3}, "fault 0 \\(fan-aging\\): invalid zeus fan 3"},
+		{Fault{Kind: FanAging, Device: "celaeno", Fan: 1}, "fault 0 \\(fan-aging\\): invalid celaeno fan 1"},
+		{Fault{Kind: FanStalled, Device: "helios"}, "fault 0 \\(fan-stalled\\): helios has no fan"},
+	}
+	for _, d := range testdata {
+		err := (&Scenario{Faults: []Fault{d.Fault}}).check()
+		if len(d.Message) == 0 {
+			c.Check(err, IsNil)
+		} else {
+			c.Check(err, ErrorMatches, d.Message)
+		}
+	}
+}
+
+func (s *ScenarioSuite) TestPlayer(c *C) {
+	missing := Fault{Kind: DeviceMissing, Device: "zeus", At: time.Minute, Duration: 2 * time.Minute}
+	reset := Fault{Kind: WatchdogReset, Device: "zeus"}
+	p := newScenarioPlayer(&Scenario{Faults: []Fault{missing, reset}})
+
+	started, ended := p.advance(0)
+	c.Check(started, DeepEquals, []Fault{reset})
+	c.Check(ended, HasLen, 0)
+
+	started, ended = p.advance(time.Minute)
+	c.Check(started, DeepEquals, []Fault{missing})
+	c.Check(ended, HasLen, 0)
+	c.Check(p.activeFaults(), DeepEquals, []Fault{missing, reset})
+
+	started, ended = p.advance(time.Minute)
+	c.Check(started, HasLen, 0)
+	c.Check(ended, HasLen, 0)
+
+	started, ended = p.advance(time.Minute)
+	c.Check(started, HasLen, 0)
+	c.Check(ended, DeepEquals, []Fault{missing})
+	c.Check(p.activeFaults(), DeepEquals, []Fault{reset})
+
+	started, ended = newScenarioPlayer(nil).advance(time.Hour)
+	c.Check(started, HasLen, 0)
+	c.Check(ended, HasLen, 0)
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// serialNumberPrefix prefixes the interface devices identified by the
+// USB serial number of their adapter, e.g. 'serial:0035002E5734'.
+const serialNumberPrefix = "serial:"
+
+// serialDeviceResolver finds the tty device of USB CAN adapters.
+type serialDeviceResolver struct {
+	sysRoot string
+	devRoot string
+}
+
+var defaultSerialDeviceResolver = serialDeviceResolver{
+	sysRoot: "/sys/class/tty",
+	devRoot: "/dev",
+}
+
+// resolve returns the tty device of an adapter, given either as a
+// path, possibly a symlink such as /dev/serial/by-id/..., or as the
+// USB serial number of the adapter.
+func (r serialDeviceResolver) resolve(device string) (string, error) {
+	if strings.HasPrefix(device, serialNumberPrefix) == true {
+		return r.bySerialNumber(strings.TrimPrefix(device, serialNumberPrefix))
+	}
+	res, err := filepath.EvalSymlinks(device)
+	if errors.Is(err, fs.ErrNotExist) == true {
+		return "", fmt.Errorf("adapter '%s' is not plugged", device)
+	}
+	return res, err
+}
+
+// serialNumber returns the USB serial number of the adapter of tty,
+// or an empty string if it is not an USB device.
+func (r serialDeviceResolver) serialNumber(tty string) string {
+	// device points to the USB interface of ttyACM adapters, or to a
+	// port below it for USB serial converters such as ttyUSB, so the
+	// USB device is one of its ancestors.
+	dir, err := filepath.EvalSymlinks(filepath.Join(r.sysRoot, tty, "device"))
+	if err != nil {
+		return ""
+	}
+	for ; filepath.Base(dir) != "devices" && filepath.Dir(dir) != dir; dir = filepath.Dir(dir) {
+		content, err := os.ReadFile(filepath.Join(dir, "serial"))
+		if err == nil {
+			return strings.TrimSpace(string(content))
+		}
+		if _, err := os.Stat(filepath.Join(dir, "idVendor")); err == nil {
+			// the USB device has no serial number.
+			return ""
+		}
+	}
+	return ""
+}
+
+func (r serialDeviceResolver) bySerialNumber(serial string) (string, error) {
+	entries, err := os.ReadDir(r.sysRoot)
+	if err != nil {
+		return "", err
+	}
+	var matches []string
+	for _, e := range entries {
+		if r.serialNumber(e.Name()) == serial {
+			matches = append(matches, filepath.Join(r.devRoot, e.Name()))
+		}
+	}
+	switch len(matches) {
+	case 0:
+		return "", fmt.Errorf("no adapter with serial number '%s' is plugged", serial)
+	case 1:
+		return matches[0], nil
+	default:
+		return "", fmt.Errorf("several devices have serial number '%s': %s", serial, strings.Join(matches, ", "))
+	}
+}
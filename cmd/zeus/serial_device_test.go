@@ -0,0 +1,91 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+
+	. "gopkg.in/check.v1"
+)
+
+type SerialDeviceSuite struct {
+	resolver serialDeviceResolver
+}
+
+var _ = Suite(&SerialDeviceSuite{})
+
+// plugAdapter emulates the sysfs entries of an USB adapter. The tty
+// device is the USB interface of usbDevice, or a port below it if not
+// empty, as for USB serial converters.
+func (s *SerialDeviceSuite) plugAdapter(c *C, tty, usbDevice, port, serial string) {
+	sysRoot := filepath.Dir(s.resolver.sysRoot)
+	device := filepath.Join(sysRoot, "devices", usbDevice, usbDevice+":1.0", port)
+	c.Assert(os.MkdirAll(device, 0755), IsNil)
+	if len(serial) > 0 {
+		c.Assert(os.WriteFile(filepath.Join(sysRoot, "devices", usbDevice, "idVendor"), []byte("1d50\n"), 0644), IsNil)
+		c.Assert(os.WriteFile(filepath.Join(sysRoot, "devices", usbDevice, "serial"), []byte(serial+"\n"), 0644), IsNil)
+	}
+	c.Assert(os.MkdirAll(filepath.Join(s.resolver.sysRoot, tty), 0755), IsNil)
+	c.Assert(os.Symlink(device, filepath.Join(s.resolver.sysRoot, tty, "device")), IsNil)
+	c.Assert(os.WriteFile(filepath.Join(s.resolver.devRoot, tty), nil, 0644), IsNil)
+}
+
+func (s *SerialDeviceSuite) SetUpTest(c *C) {
+	root := c.MkDir()
+	s.resolver = serialDeviceResolver{
+		sysRoot: filepath.Join(root, "sys", "tty"),
+		devRoot: filepath.Join(root, "dev"),
+	}
+	c.Assert(os.MkdirAll(s.resolver.sysRoot, 0755), IsNil)
+	c.Assert(os.MkdirAll(filepath.Join(s.resolver.devRoot, "serial", "by-id"), 0755), IsNil)
+	s.plugAdapter(c, "ttyACM0", "1-1", "", "0035002E5734")
+	s.plugAdapter(c, "ttyACM1", "1-2", "", "00410021594B")
+	s.plugAdapter(c, "ttyUSB0", "1-4", "ttyUSB0", "FT4ZQ8XB")
+	s.plugAdapter(c, "ttyS0", "serial8250", "", "")
+}
+
+func (s *SerialDeviceSuite) TestResolveSerialNumber(c *C) {
+	tty, err := s.resolver.resolve("serial:00410021594B")
+	c.Check(err, IsNil)
+	c.Check(tty, Equals, filepath.Join(s.resolver.devRoot, "ttyACM1"))
+
+	tty, err = s.resolver.resolve("serial:FT4ZQ8XB")
+	c.Check(err, IsNil)
+	c.Check(tty, Equals, filepath.Join(s.resolver.devRoot, "ttyUSB0"))
+
+	_, err = s.resolver.resolve("serial:DEADBEEF")
+	c.Check(err, ErrorMatches, "no adapter with serial number 'DEADBEEF' is plugged")
+
+	s.plugAdapter(c, "ttyACM2", "1-3", "", "00410021594B")
+	_, err = s.resolver.resolve("serial:00410021594B")
+	c.Check(err, ErrorMatches, "several devices have serial number '00410021594B': .*ttyACM1, .*ttyACM2")
+}
+
+func (s *SerialDeviceSuite) TestResolvePath(c *C) {
+	byID := filepath.Join(s.resolver.devRoot, "serial", "by-id", "usb-CANtact_0035002E5734-if00")
+	c.Assert(os.Symlink("../../ttyACM0", byID), IsNil)
+
+	tty, err := s.resolver.resolve(byID)
+	c.Check(err, IsNil)
+	c.Check(tty, Equals, filepath.Join(s.resolver.devRoot, "ttyACM0"))
+
+	missing := filepath.Join(s.resolver.devRoot, "serial", "by-id", "usb-CANtact_00410021594B-if00")
+	_, err = s.resolver.resolve(missing)
+	c.Check(err, ErrorMatches, "adapter '.*usb-CANtact_00410021594B-if00' is not plugged")
+}
+
+func (s *SerialDeviceSuite) TestResolveInterfaces(c *C) {
+	c.Check(resolveInterfaces(map[string]InterfaceDefinition{
+		"slcan0": {Device: "serial:0035002E5734"},
+		"slcan1": {Device: filepath.Join(s.resolver.devRoot, "ttyACM1")},
+		"can0":   {Type: NativeInterface},
+	}, s.resolver), IsNil)
+
+	c.Check(resolveInterfaces(map[string]InterfaceDefinition{
+		"slcan0": {Device: "serial:DEADBEEF"},
+	}, s.resolver), ErrorMatches, "interface slcan0: no adapter with serial number 'DEADBEEF' is plugged")
+
+	c.Check(resolveInterfaces(map[string]InterfaceDefinition{
+		"slcan0": {Device: "serial:0035002E5734"},
+		"slcan1": {Device: filepath.Join(s.resolver.devRoot, "ttyACM0")},
+	}, s.resolver), ErrorMatches, "interface slcan.: adapter .*ttyACM0 is also used by interface slcan.")
+}
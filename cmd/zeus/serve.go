@@ -3,18 +3,50 @@ package main
 import (
 	"os"
 	"os/signal"
+	"syscall"
 
 	flags "github.com/jessevdk/go-flags"
 )
 
 type ServeCommand struct {
+	Emulate  bool           `long:"emulate" description:"emulates the Arke devices instead of using the CAN interfaces"`
+	Scenario flags.Filename `long:"scenario" description:"YAML file of faults to inject in the emulated Arke devices, implies --emulate"`
+
 	Args struct {
 		Config flags.Filename
 	} `positional-args:"yes"`
 }
 
-func (c *ServeCommand) Execute(args []string) error {
+func (c *ServeCommand) openConfig() (*Config, error) {
 	config, err := OpenConfigFromArg(c.Args.Config)
+	if err != nil {
+		return nil, err
+	}
+	if c.Emulate == true {
+		config.Emulate = true
+	}
+	if len(c.Scenario) > 0 {
+		config.Emulate = true
+		config.EmulatedScenario = string(c.Scenario)
+	}
+	return config, nil
+}
+
+func (c *ServeCommand) reload(z *Zeus) {
+	z.notifier.Notify("RELOADING=1")
+	defer z.notifier.Notify("READY=1")
+
+	config, err := c.openConfig()
+	if err == nil {
+		_, err = z.reloadConfig(*config)
+	}
+	if err != nil {
+		z.logger.WithError(err).Error("could not reload configuration")
+	}
+}
+
+func (c *ServeCommand) Execute(args []string) error {
+	config, err := c.openConfig()
 	if err != nil {
 		return err
 	}
@@ -22,11 +54,17 @@ func (c *ServeCommand) Execute(args []string) error {
 	if err != nil {
 		return err
 	}
+	z.configLoader = c.openConfig
 
-	sigint := make(chan os.Signal, 1)
-	signal.Notify(sigint, os.Interrupt)
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM, syscall.SIGHUP)
 	go z.run()
-	<-sigint
+	for sig := range signals {
+		if sig != syscall.SIGHUP {
+			break
+		}
+		c.reload(z)
+	}
 
 	return z.shutdown()
 }
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"fmt"
 	"os"
 	"os/signal"
 
@@ -20,6 +21,8 @@ type SimulateCommand struct {
 	OlympusAddress string  `long:"olympus" description:"olympus address to connect to" default:"localhost:3001"`
 	RPCPort        int     `long:"rpc-port" description:"the rpc port to use" default:"5011"`
 	Otel           string  `long:"otel-collector" description:"address of an open-telemetry collector"`
+	Plant          string  `long:"plant" description:"YAML file with the parameters of the simulated tracking boxes"`
+	Scenario       string  `long:"scenario" description:"YAML file of faults to inject in the simulated devices"`
 }
 
 func (c *SimulateCommand) Execute(args []string) error {
@@ -41,12 +44,30 @@ func (c *SimulateCommand) Execute(args []string) error {
 		logrus.SetLevel(logrus.TraceLevel)
 	}
 
+	plant := DefaultPlantParameters()
+	if len(c.Plant) > 0 {
+		plant, err = OpenPlantParameters(c.Plant)
+		if err != nil {
+			return fmt.Errorf("could not read plant parameters: %w", err)
+		}
+	}
+
+	var scenario *Scenario
+	if len(c.Scenario) > 0 {
+		scenario, err = ReadScenarioFile(c.Scenario)
+		if err != nil {
+			return fmt.Errorf("could not read scenario: %w", err)
+		}
+	}
+
 	s, err := NewZeusSimulator(ZeusSimulatorArgs{
 		hostname:       c.Args.Hostname,
 		season:         *season,
 		timeRatio:      c.TimeRatio,
 		olympusAddress: c.OlympusAddress,
 		rpcPort:        c.RPCPort,
+		plant:          plant,
+		scenario:       scenario,
 	})
 	if err != nil {
 		return err
@@ -4,6 +4,7 @@ import (
 	"bufio"
 	"fmt"
 	"io"
+	"net"
 	"os/exec"
 	"path"
 	"syscall"
@@ -13,16 +14,55 @@ import (
 	"github.com/sirupsen/logrus"
 )
 
+const slcandCheckPeriod = 5 * time.Second
+
+// SlcandManager runs slcand for a CAN interface. It restarts it with
+// an increasing delay when it exits or when its interface
+// disappears, e.g. when the USB adapter is unplugged.
 type SlcandManager struct {
-	ifname   string
+	ifname string
+	// devname is resolved to the tty of the adapter each time slcand
+	// starts, as it may change when the adapter is plugged back.
 	devname  string
+	bitrate  int
 	logger   *logrus.Entry
 	cmd      *exec.Cmd
 	cmdError chan error
+
+	backoff     *backoff
+	checkPeriod time.Duration
+	startDelay  time.Duration
+	stopTimeout time.Duration
+	quit        chan struct{}
+	done        chan error
+
+	// resolve, command, setLink and linkAlive are replaced in tests.
+	resolve   func(devname string) (string, error)
+	command   func(tty string) *exec.Cmd
+	setLink   func(up bool) error
+	linkAlive func() bool
+}
+
+func (m *SlcandManager) slcandCommand(tty string) *exec.Cmd {
+	return exec.Command("slcand", "-ofs", slcanBitrates[m.bitrate], "-S", "115200", "-F", tty, m.ifname)
+}
+
+func (m *SlcandManager) ipSetLink(up bool) error {
+	return setLink(m.logger, m.ifname, up)
+}
+
+func (m *SlcandManager) interfaceIsUp() bool {
+	intf, err := net.InterfaceByName(m.ifname)
+	return err == nil && intf.Flags&net.FlagUp != 0
 }
 
 func (m *SlcandManager) open() (err error) {
-	m.cmd = exec.Command("slcand", "-ofs", "5", "-S", "115200", "-F", m.devname, m.ifname)
+	tty, err := m.resolve(m.devname)
+	if err != nil {
+		return err
+	}
+	m.logger.WithField("device", tty).Info("starting slcand")
+	m.cmd = m.command(tty)
 	//avoids the daemon to get the signal from terminal, we take care to do it ourselves
 	m.cmd.SysProcAttr = &syscall.SysProcAttr{
 		Setpgid: true,
@@ -40,11 +80,12 @@ func (m *SlcandManager) open() (err error) {
 
 	scanner := bufio.NewScanner(io.MultiReader(stdout, stderr))
 
-	go func() {
-		err := m.cmd.Start()
+	m.cmdError = make(chan error, 1)
+	go func(cmd *exec.Cmd, cmdError chan<- error) {
+		err := cmd.Start()
 		if err != nil {
-			m.cmdError <- err
-			close(m.cmdError)
+			cmdError <- err
+			close(cmdError)
 			return
 		}
 
@@ -52,45 +93,121 @@ func (m *SlcandManager) open() (err error) {
 			m.logger.WithField("output", scanner.Text()).Info("slcand output")
 		}
 
-		m.cmdError <- m.cmd.Wait()
-		close(m.cmdError)
-	}()
+		cmdError <- cmd.Wait()
+		close(cmdError)
+	}(m.cmd, m.cmdError)
 
 	select {
 	case err := <-m.cmdError:
-		return fmt.Errorf("Could not open slcand: %s", err)
-	case <-time.After(500 * time.Millisecond):
+		m.cmdError = nil
+		return fmt.Errorf("Could not open slcand: %v", err)
+	case <-time.After(m.startDelay):
 	}
-	m.logger.WithField("interface", m.ifname).Info("set interface link up")
-	ipCmd := exec.Command("ip", "link", "set", m.ifname, "up")
-	out, err := ipCmd.CombinedOutput()
-	if err != nil {
-		return fmt.Errorf("Could not set %s up: %s", m.ifname, string(out))
+	if err := m.setLink(true); err != nil {
+		m.stop()
+		return err
 	}
 
 	return nil
 }
 
-func OpenSlcand(ifname, devname string) (*SlcandManager, error) {
+// stop terminates slcand if it is running. It is killed if it does
+// not exit on SIGINT.
+func (m *SlcandManager) stop() error {
+	if m.cmdError == nil {
+		return nil
+	}
+	defer func() { m.cmdError = nil }()
+	// slcand runs in its own process group.
+	syscall.Kill(-m.cmd.Process.Pid, syscall.SIGINT)
+	select {
+	case err := <-m.cmdError:
+		return err
+	case <-time.After(m.stopTimeout):
+		m.logger.Warn("slcand did not exit, killing it")
+		syscall.Kill(-m.cmd.Process.Pid, syscall.SIGKILL)
+		return <-m.cmdError
+	}
+}
+
+// restart reopens slcand until it succeeds or the manager is closed.
+func (m *SlcandManager) restart() {
+	for {
+		select {
+		case <-m.quit:
+			return
+		case <-time.After(m.backoff.next()):
+		}
+		err := m.open()
+		if err == nil {
+			m.logger.Info("slcand restarted")
+			m.backoff.reset()
+			return
+		}
+		m.logger.WithError(err).Warn("could not restart slcand")
+	}
+}
+
+func (m *SlcandManager) supervise() {
+	ticker := time.NewTicker(m.checkPeriod)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-m.quit:
+			err := m.setLink(false)
+			if serr := m.stop(); err == nil {
+				err = serr
+			}
+			m.done <- err
+			return
+		case err := <-m.cmdError:
+			m.cmdError = nil
+			m.logger.WithError(err).Warn("slcand exited")
+			m.restart()
+		case <-ticker.C:
+			if m.cmdError == nil || m.linkAlive() == true {
+				continue
+			}
+			m.logger.Warn("interface is down")
+			m.stop()
+			m.restart()
+		}
+	}
+}
+
+func newSlcandManager(ifname, devname string, bitrate int) *SlcandManager {
 	m := &SlcandManager{
-		ifname:   ifname,
-		devname:  devname,
-		logger:   tm.NewLogger(path.Join("slcand", ifname)),
-		cmdError: make(chan error),
+		ifname:      ifname,
+		devname:     devname,
+		bitrate:     bitrate,
+		logger:      tm.NewLogger(path.Join("slcand", ifname)),
+		backoff:     newBackoff(LinkMinBackoff, LinkMaxBackoff),
+		checkPeriod: slcandCheckPeriod,
+		startDelay:  500 * time.Millisecond,
+		stopTimeout: 2 * time.Second,
+		quit:        make(chan struct{}),
+		done:        make(chan error),
 	}
+	m.resolve = defaultSerialDeviceResolver.resolve
+	m.command = m.slcandCommand
+	m.setLink = m.ipSetLink
+	m.linkAlive = m.interfaceIsUp
+	return m
+}
+
+// OpenSlcand starts slcand for ifname on the adapter devname, a
+// device path or 'serial:' followed by its USB serial number, at one
+// of the slcanBitrates, and supervises it until Close is called.
+func OpenSlcand(ifname, devname string, bitrate int) (*SlcandManager, error) {
+	m := newSlcandManager(ifname, devname, bitrate)
 	if err := m.open(); err != nil {
 		return nil, err
 	}
+	go m.supervise()
 	return m, nil
 }
 
 func (m *SlcandManager) Close() error {
-	m.logger.WithField("interface", m.ifname).Info("set interface link down")
-	ipCmd := exec.Command("ip", "link", "set", m.ifname, "down")
-	out, err := ipCmd.CombinedOutput()
-	if err != nil {
-		return fmt.Errorf("Could not set %s down: %s", m.ifname, string(out))
-	}
-	m.cmd.Process.Signal(syscall.SIGINT)
-	return <-m.cmdError
+	close(m.quit)
+	return <-m.done
 }
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"os/exec"
+	"sync"
+	"time"
+
+	. "gopkg.in/check.v1"
+)
+
+type SlcandManagerSuite struct {
+	mx          sync.Mutex
+	starts      int
+	links       []bool
+	alive       bool
+	manager     *SlcandManager
+	slcandShell string
+}
+
+var _ = Suite(&SlcandManagerSuite{})
+
+func (s *SlcandManagerSuite) SetUpTest(c *C) {
+	s.starts = 0
+	s.links = nil
+	s.alive = true
+	s.slcandShell = "exec sleep 10"
+	s.manager = newSlcandManager("slcan-test", "/dev/null", DefaultCANBitrate)
+	s.manager.backoff = newBackoff(time.Millisecond, 10*time.Millisecond)
+	s.manager.checkPeriod = 10 * time.Millisecond
+	s.manager.startDelay = 10 * time.Millisecond
+	s.manager.stopTimeout = 50 * time.Millisecond
+	s.manager.resolve = func(devname string) (string, error) {
+		return devname, nil
+	}
+	s.manager.command = func(tty string) *exec.Cmd {
+		s.mx.Lock()
+		defer s.mx.Unlock()
+		s.starts += 1
+		return exec.Command("sh", "-c", s.slcandShell)
+	}
+	s.manager.setLink = func(up bool) error {
+		s.mx.Lock()
+		defer s.mx.Unlock()
+		s.links = append(s.links, up)
+		return nil
+	}
+	s.manager.linkAlive = func() bool {
+		s.mx.Lock()
+		defer s.mx.Unlock()
+		return s.alive
+	}
+}
+
+func (s *SlcandManagerSuite) startCount() int {
+	s.mx.Lock()
+	defer s.mx.Unlock()
+	return s.starts
+}
+
+func (s *SlcandManagerSuite) waitStarts(c *C, n int) {
+	for i := 0; i < 100 && s.startCount() < n; i++ {
+		time.Sleep(10 * time.Millisecond)
+	}
+	c.Check(s.startCount() >= n, Equals, true)
+}
+
+func (s *SlcandManagerSuite) TestRestartsExitedSlcand(c *C) {
+	c.Assert(s.manager.open(), IsNil)
+	go s.manager.supervise()
+
+	s.mx.Lock()
+	s.slcandShell = "exec sleep 0.05"
+	s.mx.Unlock()
+	s.manager.cmd.Process.Kill()
+	s.waitStarts(c, 3)
+
+	s.mx.Lock()
+	s.slcandShell = "exec sleep 10"
+	s.mx.Unlock()
+	c.Check(s.manager.Close(), NotNil)
+	c.Check(s.links[0], Equals, true)
+	c.Check(s.links[len(s.links)-1], Equals, false)
+}
+
+func (s *SlcandManagerSuite) TestRestartsOnDeadInterface(c *C) {
+	c.Assert(s.manager.open(), IsNil)
+	go s.manager.supervise()
+
+	s.mx.Lock()
+	s.alive = false
+	s.mx.Unlock()
+	s.waitStarts(c, 2)
+	s.mx.Lock()
+	s.alive = true
+	s.mx.Unlock()
+
+	c.Check(s.manager.Close(), NotNil)
+}
+
+func (s *SlcandManagerSuite) TestDoesNotStartFailingSlcand(c *C) {
+	s.slcandShell = "exit 1"
+	c.Check(s.manager.open(), ErrorMatches, "Could not open slcand: exit status 1")
+	c.Check(s.links, HasLen, 0)
+}
@@ -8,7 +8,8 @@ import (
 )
 
 type StubRawInterface struct {
-	queue chan socketcan.CanFrame
+	queue  chan socketcan.CanFrame
+	errors chan error
 }
 
 func (i *StubRawInterface) Send(f socketcan.CanFrame) error {
@@ -30,11 +31,20 @@ func (i *StubRawInterface) Receive() (socketcan.CanFrame, error) {
 	if i.isClosed() == true {
 		return socketcan.CanFrame{}, i.closedError()
 	}
-	f, ok := <-i.queue
-	if ok == false {
-		return socketcan.CanFrame{}, i.closedError()
+	select {
+	case f, ok := <-i.queue:
+		if ok == false {
+			return socketcan.CanFrame{}, i.closedError()
+		}
+		return f, nil
+	case err := <-i.errors:
+		return socketcan.CanFrame{}, err
 	}
-	return f, nil
+}
+
+// fail makes a pending Receive return err.
+func (i *StubRawInterface) fail(err error) {
+	i.errors <- err
 }
 
 func makeCANIDT(t arke.MessageType, c arke.MessageClass, n arke.NodeID) uint32 {
@@ -69,6 +79,7 @@ func (i *StubRawInterface) Close() error {
 
 func NewStubRawInterface() *StubRawInterface {
 	return &StubRawInterface{
-		queue: make(chan socketcan.CanFrame),
+		queue:  make(chan socketcan.CanFrame),
+		errors: make(chan error),
 	}
 }
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"time"
+)
+
+// sdNotifier implements the systemd notification protocol
+// (sd_notify(3)) over the NOTIFY_SOCKET datagram socket.
+type sdNotifier struct {
+	addr *net.UnixAddr
+}
+
+// newSDNotifier returns nil when zeus is not started by systemd.
+func newSDNotifier() *sdNotifier {
+	name := os.Getenv("NOTIFY_SOCKET")
+	if len(name) == 0 {
+		return nil
+	}
+	if name[0] == '@' {
+		// abstract socket
+		name = "\x00" + name[1:]
+	}
+	return &sdNotifier{addr: &net.UnixAddr{Name: name, Net: "unixgram"}}
+}
+
+// Notify sends states such as "READY=1". It is a no-op on a nil
+// notifier.
+func (n *sdNotifier) Notify(state string) error {
+	if n == nil {
+		return nil
+	}
+	conn, err := net.DialUnix("unixgram", nil, n.addr)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	_, err = conn.Write([]byte(state))
+	return err
+}
+
+// sdWatchdogInterval returns the watchdog timeout systemd expects for
+// this process, or 0 if the watchdog is disabled.
+func sdWatchdogInterval() (time.Duration, error) {
+	usec := os.Getenv("WATCHDOG_USEC")
+	if len(usec) == 0 {
+		return 0, nil
+	}
+	if pid := os.Getenv("WATCHDOG_PID"); len(pid) > 0 && pid != strconv.Itoa(os.Getpid()) {
+		return 0, nil
+	}
+	value, err := strconv.ParseUint(usec, 10, 64)
+	if err != nil || value == 0 {
+		return 0, fmt.Errorf("invalid WATCHDOG_USEC '%s'", usec)
+	}
+	return time.Duration(value) * time.Microsecond, nil
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	. "gopkg.in/check.v1"
+)
+
+type SystemdSuite struct {
+	env map[string]string
+}
+
+var _ = Suite(&SystemdSuite{})
+
+func (s *SystemdSuite) SetUpTest(c *C) {
+	s.env = make(map[string]string)
+	for _, name := range []string{"NOTIFY_SOCKET", "WATCHDOG_USEC", "WATCHDOG_PID"} {
+		s.env[name] = os.Getenv(name)
+		os.Unsetenv(name)
+	}
+}
+
+func (s *SystemdSuite) TearDownTest(c *C) {
+	for name, value := range s.env {
+		os.Setenv(name, value)
+	}
+}
+
+func (s *SystemdSuite) TestNotify(c *C) {
+	var n *sdNotifier = newSDNotifier()
+	c.Check(n, IsNil)
+	c.Check(n.Notify("READY=1"), IsNil)
+
+	path := filepath.Join(c.MkDir(), "notify.sock")
+	conn, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: path, Net: "unixgram"})
+	c.Assert(err, IsNil)
+	defer conn.Close()
+
+	os.Setenv("NOTIFY_SOCKET", path)
+	n = newSDNotifier()
+	c.Assert(n, NotNil)
+	c.Check(n.Notify("READY=1"), IsNil)
+
+	buf := make([]byte, 64)
+	conn.SetReadDeadline(time.Now().Add(time.Second))
+	size, err := conn.Read(buf)
+	c.Assert(err, IsNil)
+	c.Check(string(buf[:size]), Equals, "READY=1")
+
+	os.Setenv("NOTIFY_SOCKET", "@zeus-test")
+	c.Check(newSDNotifier().addr.Name, Equals, "\x00zeus-test")
+}
+
+func (s *SystemdSuite) TestWatchdogInterval(c *C) {
+	timeout, err := sdWatchdogInterval()
+	c.Check(err, IsNil)
+	c.Check(timeout, Equals, time.Duration(0))
+
+	os.Setenv("WATCHDOG_USEC", "30000000")
+	timeout, err = sdWatchdogInterval()
+	c.Check(err, IsNil)
+	c.Check(timeout, Equals, 30*time.Second)
+
+	os.Setenv("WATCHDOG_PID", strconv.Itoa(os.Getpid()+1))
+	timeout, err = sdWatchdogInterval()
+	c.Check(err, IsNil)
+	c.Check(timeout, Equals, time.Duration(0))
+
+	os.Setenv("WATCHDOG_PID", strconv.Itoa(os.Getpid()))
+	os.Setenv("WATCHDOG_USEC", "foo")
+	_, err = sdWatchdogInterval()
+	c.Check(err, ErrorMatches, "invalid WATCHDOG_USEC 'foo'")
+}
@@ -0,0 +1,258 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+
+	"github.com/formicidae-tracker/zeus/internal/zeus"
+)
+
+// outboxEntry is a single upstream item waiting for olympus
+// acknowledgement. Exactly one of Report or Alarm is set. When Ack is
+// non-zero, the line is an acknowledgement marker in the journal.
+type outboxEntry struct {
+	Seq    uint64              `json:",omitempty"`
+	Ack    uint64              `json:",omitempty"`
+	Report *zeus.ClimateReport `json:",omitempty"`
+	Alarm  *zeus.AlarmEvent    `json:",omitempty"`
+}
+
+func (e outboxEntry) sameAs(o outboxEntry) bool {
+	if e.Report != nil && o.Report != nil {
+		return e.Report.Time.Equal(o.Report.Time)
+	}
+	if e.Alarm != nil && o.Alarm != nil {
+		return e.Alarm.Identifier == o.Alarm.Identifier &&
+			e.Alarm.Status == o.Alarm.Status &&
+			e.Alarm.Time.Equal(o.Alarm.Time)
+	}
+	return false
+}
+
+// uplinkOutbox stores climate reports and alarm events that must be
+// sent to olympus. Entries are journaled to disk as they are pushed
+// and only dropped once acknowledged, so they survive both
+// disconnections and daemon restarts. An empty filename keeps the
+// outbox in memory only.
+type uplinkOutbox struct {
+	mx       sync.Mutex
+	filename string
+	file     *os.File
+
+	pending []outboxEntry
+	last    outboxEntry
+	lastSeq uint64
+	acked   int
+	// skipped is the number of corrupted entries skipped on load.
+	skipped int
+
+	signal chan struct{}
+}
+
+const (
+	// number of acknowledged entries after which the journal is rewritten
+	outboxCompactThreshold = 1000
+	// maximal number of entries sent at once if olympus does not
+	// specify a page size
+	outboxPageSize = 200
+)
+
+func OpenUplinkOutbox(filename string) (*uplinkOutbox, error) {
+	res := &uplinkOutbox{
+		filename: filename,
+		signal:   make(chan struct{}, 1),
+	}
+	if len(filename) == 0 {
+		return res, nil
+	}
+	if err := res.load(); err != nil {
+		return nil, err
+	}
+	if err := res.compact(); err != nil {
+		return nil, err
+	}
+	if len(res.pending) > 0 {
+		res.notify()
+	}
+	return res, nil
+}
+
+func (o *uplinkOutbox) load() error {
+	f, err := os.Open(o.filename)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	reader := bufio.NewReader(f)
+	for {
+		l, err := reader.ReadBytes('\n')
+		if err == io.EOF {
+			// a partial last line is the mark of an interrupted write
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		e := outboxEntry{}
+		if err := json.Unmarshal(l, &e); err != nil {
+			// dropped by the following compaction.
+			o.skipped += 1
+			continue
+		}
+		if e.Ack != 0 {
+			o.drop(e.Ack)
+			continue
+		}
+		if e.Seq <= o.lastSeq {
+			// duplicated entry
+			continue
+		}
+		o.lastSeq = e.Seq
+		o.pending = append(o.pending, e)
+		o.last = e
+	}
+}
+
+func (o *uplinkOutbox) compact() error {
+	tmpname := o.filename + ".tmp"
+	f, err := os.Create(tmpname)
+	if err != nil {
+		return err
+	}
+	for _, e := range o.pending {
+		if err := writeOutboxEntry(f, e); err != nil {
+			f.Close()
+			return err
+		}
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(tmpname, o.filename); err != nil {
+		return err
+	}
+	if o.file != nil {
+		o.file.Close()
+	}
+	o.file, err = os.OpenFile(o.filename, os.O_WRONLY|os.O_APPEND, 0644)
+	o.acked = 0
+	return err
+}
+
+func writeOutboxEntry(w io.Writer, e outboxEntry) error {
+	data, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "%s\n", data)
+	return err
+}
+
+func (o *uplinkOutbox) notify() {
+	select {
+	case o.signal <- struct{}{}:
+	default:
+	}
+}
+
+func (o *uplinkOutbox) drop(seq uint64) int {
+	i := 0
+	for ; i < len(o.pending); i++ {
+		if o.pending[i].Seq > seq {
+			break
+		}
+	}
+	o.pending = o.pending[i:]
+	return i
+}
+
+// Push appends a new entry to the outbox. Entries identical to the
+// last pushed one are silently discarded.
+func (o *uplinkOutbox) Push(e outboxEntry) error {
+	o.mx.Lock()
+	defer o.mx.Unlock()
+
+	if o.last.sameAs(e) {
+		return nil
+	}
+
+	o.lastSeq += 1
+	e.Seq = o.lastSeq
+	e.Ack = 0
+	o.pending = append(o.pending, e)
+	o.last = e
+	defer o.notify()
+	if o.file == nil {
+		return nil
+	}
+	return writeOutboxEntry(o.file, e)
+}
+
+// Pending returns up to max of the oldest unacknowledged entries,
+// in order. A non-positive max returns all of them.
+func (o *uplinkOutbox) Pending(max int) []outboxEntry {
+	o.mx.Lock()
+	defer o.mx.Unlock()
+	if max <= 0 || max > len(o.pending) {
+		max = len(o.pending)
+	}
+	res := make([]outboxEntry, max)
+	copy(res, o.pending[:max])
+	return res
+}
+
+// Len returns the number of unacknowledged entries.
+func (o *uplinkOutbox) Len() int {
+	o.mx.Lock()
+	defer o.mx.Unlock()
+	return len(o.pending)
+}
+
+// LastSeq returns the sequence number of the last pushed entry.
+func (o *uplinkOutbox) LastSeq() uint64 {
+	o.mx.Lock()
+	defer o.mx.Unlock()
+	return o.lastSeq
+}
+
+// Ack drops all entries up to and including seq.
+func (o *uplinkOutbox) Ack(seq uint64) error {
+	o.mx.Lock()
+	defer o.mx.Unlock()
+	n := o.drop(seq)
+	if n == 0 || o.file == nil {
+		return nil
+	}
+	o.acked += n
+	if len(o.pending) == 0 {
+		o.acked = 0
+		return o.file.Truncate(0)
+	}
+	if o.acked >= outboxCompactThreshold {
+		return o.compact()
+	}
+	return writeOutboxEntry(o.file, outboxEntry{Ack: seq})
+}
+
+// Signal is notified every time new entries are available.
+func (o *uplinkOutbox) Signal() <-chan struct{} {
+	return o.signal
+}
+
+func (o *uplinkOutbox) Close() error {
+	o.mx.Lock()
+	defer o.mx.Unlock()
+	if o.file == nil {
+		return nil
+	}
+	err := o.file.Close()
+	o.file = nil
+	return err
+}
@@ -0,0 +1,130 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/formicidae-tracker/zeus/internal/zeus"
+	. "gopkg.in/check.v1"
+)
+
+type UplinkOutboxSuite struct {
+	TmpDir   string
+	Filename string
+}
+
+var _ = Suite(&UplinkOutboxSuite{})
+
+func (s *UplinkOutboxSuite) SetUpTest(c *C) {
+	var err error
+	s.TmpDir, err = ioutil.TempDir("", "zeus-outbox")
+	c.Assert(err, IsNil)
+	s.Filename = filepath.Join(s.TmpDir, "box.outbox.txt")
+}
+
+func (s *UplinkOutboxSuite) TearDownTest(c *C) {
+	c.Check(os.RemoveAll(s.TmpDir), IsNil)
+}
+
+func reportEntry(t time.Time) outboxEntry {
+	return outboxEntry{Report: &zeus.ClimateReport{
+		Time:         t,
+		Humidity:     50,
+		Temperatures: []zeus.Temperature{21},
+	}}
+}
+
+func (s *UplinkOutboxSuite) TestDeduplicatesAndAcknowledges(c *C) {
+	o, err := OpenUplinkOutbox("")
+	c.Assert(err, IsNil)
+	start := time.Now().Round(0)
+	c.Check(o.Push(reportEntry(start)), IsNil)
+	c.Check(o.Push(reportEntry(start)), IsNil)
+	c.Check(o.Push(reportEntry(start.Add(time.Second))), IsNil)
+	c.Check(o.Push(outboxEntry{Alarm: &zeus.AlarmEvent{Identifier: "foo", Time: start}}), IsNil)
+	c.Check(o.Len(), Equals, 3)
+
+	select {
+	case <-o.Signal():
+	default:
+		c.Errorf("outbox did not signal new entries")
+	}
+
+	pending := o.Pending(2)
+	c.Assert(len(pending), Equals, 2)
+	c.Check(pending[0].Seq, Equals, uint64(1))
+	c.Check(pending[1].Seq, Equals, uint64(2))
+	c.Check(o.Ack(pending[1].Seq), IsNil)
+	pending = o.Pending(0)
+	c.Assert(len(pending), Equals, 1)
+	c.Check(pending[0].Alarm, Not(IsNil))
+	c.Check(o.Ack(o.LastSeq()), IsNil)
+	c.Check(o.Len(), Equals, 0)
+}
+
+func (s *UplinkOutboxSuite) TestSurvivesRestart(c *C) {
+	o, err := OpenUplinkOutbox(s.Filename)
+	c.Assert(err, IsNil)
+	start := time.Now().Round(0)
+	for i := 0; i < 5; i++ {
+		c.Check(o.Push(reportEntry(start.Add(time.Duration(i)*time.Second))), IsNil)
+	}
+	c.Check(o.Ack(2), IsNil)
+	c.Check(o.Close(), IsNil)
+
+	o, err = OpenUplinkOutbox(s.Filename)
+	c.Assert(err, IsNil)
+	pending := o.Pending(0)
+	c.Assert(len(pending), Equals, 3)
+	for i, e := range pending {
+		c.Check(e.Seq, Equals, uint64(i+3))
+		c.Check(e.Report.Time.Equal(start.Add(time.Duration(i+2)*time.Second)), Equals, true)
+	}
+	c.Check(o.Push(reportEntry(start.Add(10*time.Second))), IsNil)
+	c.Check(o.LastSeq(), Equals, uint64(6))
+
+	c.Check(o.Ack(6), IsNil)
+	c.Check(o.Close(), IsNil)
+
+	o, err = OpenUplinkOutbox(s.Filename)
+	c.Assert(err, IsNil)
+	c.Check(o.Len(), Equals, 0)
+	c.Check(o.Close(), IsNil)
+}
+
+func (s *UplinkOutboxSuite) TestIgnoresInterruptedWrite(c *C) {
+	content := `{"Seq":1,"Alarm":{"ZoneIdentifier":"","Identifier":"foo","Description":"","Flags":0,"Status":0,"Time":"2023-01-01T00:00:00Z"}}
+{"Seq":2,"Alarm":{"ZoneIde`
+	c.Assert(ioutil.WriteFile(s.Filename, []byte(content), 0644), IsNil)
+	o, err := OpenUplinkOutbox(s.Filename)
+	c.Assert(err, IsNil)
+	defer o.Close()
+	pending := o.Pending(0)
+	c.Assert(len(pending), Equals, 1)
+	c.Check(pending[0].Alarm.Identifier, Equals, "foo")
+}
+
+func (s *UplinkOutboxSuite) TestSkipsCorruptedEntries(c *C) {
+	content := `{"Seq":1,"Alarm":{"ZoneIdentifier":"","Identifier":"foo","Description":"","Flags":0,"Status":0,"Time":"2023-01-01T00:00:00Z"}}
+{"Seq":2,"Alarm":{"ZoneIde
+{"Seq":3,"Alarm":{"ZoneIdentifier":"","Identifier":"bar","Description":"","Flags":0,"Status":0,"Time":"2023-01-01T00:00:01Z"}}
+`
+	c.Assert(ioutil.WriteFile(s.Filename, []byte(content), 0644), IsNil)
+	o, err := OpenUplinkOutbox(s.Filename)
+	c.Assert(err, IsNil)
+	c.Check(o.skipped, Equals, 1)
+	pending := o.Pending(0)
+	c.Assert(len(pending), Equals, 2)
+	c.Check(pending[0].Alarm.Identifier, Equals, "foo")
+	c.Check(pending[1].Alarm.Identifier, Equals, "bar")
+	c.Check(o.Close(), IsNil)
+
+	// the corrupted entry is dropped from the journal.
+	o, err = OpenUplinkOutbox(s.Filename)
+	c.Assert(err, IsNil)
+	c.Check(o.skipped, Equals, 0)
+	c.Check(o.Len(), Equals, 2)
+	c.Check(o.Close(), IsNil)
+}
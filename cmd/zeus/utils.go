@@ -1,6 +1,9 @@
 package main
 
-import "fmt"
+import (
+	"fmt"
+	"time"
+)
 
 func checkRange(start, end int) error {
 	if end > 0 && start > end || start < 0 {
@@ -21,3 +24,27 @@ func clampRange(start, end, len int) (int, int, error) {
 	}
 	return start, len, nil
 }
+
+// backoff computes exponentially increasing delays between retries.
+type backoff struct {
+	min, max, current time.Duration
+}
+
+func newBackoff(min, max time.Duration) *backoff {
+	return &backoff{min: min, max: max, current: min}
+}
+
+// next returns the delay before the next retry, and doubles it for
+// the following one.
+func (b *backoff) next() time.Duration {
+	res := b.current
+	b.current *= 2
+	if b.current > b.max {
+		b.current = b.max
+	}
+	return res
+}
+
+func (b *backoff) reset() {
+	b.current = b.min
+}
@@ -7,6 +7,7 @@ import (
 	"net"
 	"os"
 	"path/filepath"
+	"sort"
 	"sync"
 	"time"
 
@@ -20,24 +21,35 @@ import (
 	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/codes"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
 	"go.opentelemetry.io/otel/trace"
 	"google.golang.org/grpc"
 	"google.golang.org/protobuf/types/known/timestamppb"
+	yaml "gopkg.in/yaml.v2"
 )
 
 type Zeus struct {
 	zeuspb.UnimplementedZeusServer
 	intfFactory func(ifname string) (socketcan.RawInterface, error)
 
-	logger *logrus.Entry
+	logger   *logrus.Entry
+	notifier *sdNotifier
 
-	olympusHost string
-	definitions map[string]ZoneDefinition
+	// config is the applied configuration, re-read by configLoader
+	// on reloads.
+	config         Config
+	configLoader   func() (*Config, error)
+	olympusHost    string
+	metricsAddress string
+	definitions    map[string]ZoneDefinition
+	canLogDir      string
 
 	dispatchers map[string]ArkeDispatcher
 	runners     map[string]ZoneClimateRunner
+	inventories map[string]*deviceInventory
 	since       time.Time
 	tracer      trace.Tracer
+	meters      *sdkmetric.MeterProvider
 
 	mx               sync.RWMutex
 	quit, done, idle chan struct{}
@@ -58,18 +70,51 @@ func OpenZeus(c Config) (*Zeus, error) {
 			Level:          tm.VerboseLevel(c.Verbosity),
 		})
 	}
+	var meters *sdkmetric.MeterProvider
+	if c.OTELEndpoint != "" {
+		var err error
+		meters, err = setUpMetricsExport(c.OTELEndpoint, "zeus")
+		if err != nil {
+			return nil, fmt.Errorf("could not set up metrics export: %w", err)
+		}
+	}
 	err := os.MkdirAll(filepath.Join(xdg.DataHome, "fort-experiments/climate"), 0755)
 	if err != nil {
 		return nil, err
 	}
+	if c.Zones == nil {
+		// zones may be added on reloads.
+		c.Zones = make(map[string]ZoneDefinition)
+	}
 	z := &Zeus{
-		intfFactory: socketcan.NewRawInterface,
-		logger:      tm.NewLogger("zeus"),
-		olympusHost: c.Olympus,
-		definitions: c.Zones,
-		runners:     make(map[string]ZoneClimateRunner),
-		dispatchers: make(map[string]ArkeDispatcher),
-		tracer:      otel.Tracer(instrumentationName),
+		intfFactory:    socketcan.NewRawInterface,
+		logger:         tm.NewLogger("zeus"),
+		notifier:       newSDNotifier(),
+		config:         c,
+		olympusHost:    c.Olympus,
+		metricsAddress: c.MetricsAddress,
+		definitions:    c.Zones,
+		canLogDir:      c.CANLogDirectory,
+		runners:        make(map[string]ZoneClimateRunner),
+		dispatchers:    make(map[string]ArkeDispatcher),
+		inventories:    make(map[string]*deviceInventory),
+		tracer:         otel.Tracer(instrumentationName),
+		meters:         meters,
+	}
+	minimum := c.minimumFirmware()
+	for name := range c.Zones {
+		z.inventories[name] = newDeviceInventory(minimum)
+	}
+	if c.Emulate == true {
+		z.logger.Warn("using emulated Arke devices")
+		var scenario *Scenario
+		if len(c.EmulatedScenario) > 0 {
+			scenario, err = ReadScenarioFile(c.EmulatedScenario)
+			if err != nil {
+				return nil, fmt.Errorf("could not read scenario '%s': %w", c.EmulatedScenario, err)
+			}
+		}
+		z.intfFactory = emulatorFactory(c.Zones, c.plantParameters(), scenario, 1.0)
 	}
 
 	z.restoreStaticState()
@@ -94,6 +139,23 @@ func (z *Zeus) spawnZeroconf() {
 	}()
 }
 
+func (z *Zeus) spawnMetrics() {
+	if len(z.metricsAddress) == 0 {
+		return
+	}
+	errs, err := serveMetrics(z.metricsAddress, z.quit)
+	if err != nil {
+		z.logger.WithError(err).Error("could not serve metrics")
+		return
+	}
+	z.logger.WithField("address", z.metricsAddress).Info("serving metrics")
+	go func() {
+		for err := range errs {
+			z.logger.WithError(err).Error("metrics server error")
+		}
+	}()
+}
+
 func (z *Zeus) runRPC() error {
 	lis, err := net.Listen("tcp", fmt.Sprintf(":%d", zeus.ZEUS_PORT))
 	if err != nil {
@@ -111,6 +173,10 @@ func (z *Zeus) runRPC() error {
 	server := grpc.NewServer(options...)
 	zeuspb.RegisterZeusServer(server, z)
 
+	if err := z.notifier.Notify("READY=1"); err != nil {
+		z.logger.WithError(err).Warn("could not notify systemd")
+	}
+
 	go func() {
 		<-z.quit
 		server.GracefulStop()
@@ -141,22 +207,72 @@ func (z *Zeus) run() error {
 	}
 
 	z.spawnZeroconf()
+	z.spawnMetrics()
+	z.spawnWatchdog()
 
 	return z.runRPC()
 }
 
+// spawnWatchdog pings the systemd watchdog as long as zeus is
+// responsive, i.e. no climate operation hangs with its state locked.
+func (z *Zeus) spawnWatchdog() {
+	if z.notifier == nil {
+		return
+	}
+	timeout, err := sdWatchdogInterval()
+	if err != nil {
+		z.logger.WithError(err).Error("could not set up systemd watchdog")
+		return
+	}
+	if timeout == 0 {
+		return
+	}
+	z.logger.WithField("timeout", timeout).Info("systemd watchdog enabled")
+	quit := z.quit
+	go func() {
+		// a few pings may be skipped while zeus is busy.
+		ticker := time.NewTicker(timeout / 4)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-quit:
+				return
+			case <-ticker.C:
+			}
+			if z.mx.TryRLock() == false {
+				z.logger.Warn("zeus is busy, skipping watchdog ping")
+				continue
+			}
+			z.mx.RUnlock()
+			if err := z.notifier.Notify("WATCHDOG=1"); err != nil {
+				z.logger.WithError(err).Warn("could not ping systemd watchdog")
+			}
+		}
+	}()
+}
+
 func (z *Zeus) shutdown() error {
 	if z.quit == nil {
 		return fmt.Errorf("zeus: not started")
 	}
 
+	if err := z.notifier.Notify("STOPPING=1"); err != nil {
+		z.logger.WithError(err).Warn("could not notify systemd")
+	}
+
+	z.mx.Lock()
 	if z.isRunning() == true {
-		z.stopClimate()
+		// keeps the saved state, so the climate resumes on restart.
+		z.closeClimate()
 	}
+	z.mx.Unlock()
 
 	close(z.quit)
 	<-z.done
 	z.done = nil
+	if z.meters != nil {
+		return z.meters.Shutdown(context.Background())
+	}
 	return nil
 }
 
@@ -170,16 +286,33 @@ func (z *Zeus) dispatcherForInterface(ifname string) (ArkeDispatcher, error) {
 	if ok == true {
 		return d, nil
 	}
-	z.logger.WithField("interface", ifname).Info("opening interface")
-	intf, err := z.intfFactory(ifname)
+	d, err := NewSupervisedArkeDispatcher(ifname, func() (socketcan.RawInterface, error) {
+		return z.openInterface(ifname)
+	})
 	if err != nil {
 		return nil, err
 	}
-	d = NewArkeDispatcher(ifname, intf)
 	z.dispatchers[ifname] = d
 	return d, nil
 }
 
+func (z *Zeus) openInterface(ifname string) (socketcan.RawInterface, error) {
+	z.logger.WithField("interface", ifname).Info("opening interface")
+	intf, err := z.intfFactory(ifname)
+	if err != nil {
+		return nil, err
+	}
+	if len(z.canLogDir) == 0 {
+		return intf, nil
+	}
+	recording, err := NewRecordingInterface(intf, ifname, z.canLogDir)
+	if err != nil {
+		intf.Close()
+		return nil, fmt.Errorf("could not record CAN traffic: %w", err)
+	}
+	return recording, nil
+}
+
 func (z *Zeus) checkSeason(season zeus.SeasonFile) error {
 	for zoneName, _ := range season.Zones {
 		if z.hasZone(zoneName) == false {
@@ -189,18 +322,23 @@ func (z *Zeus) checkSeason(season zeus.SeasonFile) error {
 	return nil
 }
 
-func (z *Zeus) setupZoneClimate(name, suffix string, definition ZoneDefinition, climate zeus.ZoneClimate, userID string) error {
+func (z *Zeus) setupZoneClimate(name string, experiment experimentState, resume bool, seasonFileHash string, definition ZoneDefinition, climate zeus.ZoneClimate, userID string) error {
 	d, err := z.dispatcherForInterface(definition.CANInterface)
 	if err != nil {
 		return err
 	}
 	r, err := NewZoneClimateRunner(ZoneClimateRunnerOptions{
-		Name:        name,
-		FileSuffix:  suffix,
-		Dispatcher:  d,
-		Climate:     climate,
-		OlympusHost: z.olympusHost,
-		Definition:  definition,
+		Name:           name,
+		FileSuffix:     experiment.Suffix,
+		Dispatcher:     d,
+		Climate:        climate,
+		OlympusHost:    z.olympusHost,
+		Definition:     definition,
+		SeasonFileHash: seasonFileHash,
+		Since:          experiment.Since,
+		Reference:      experiment.Reference,
+		Resume:         resume,
+		Inventory:      z.inventories[name],
 	})
 	if err != nil {
 		return err
@@ -209,7 +347,31 @@ func (z *Zeus) setupZoneClimate(name, suffix string, definition ZoneDefinition,
 	return nil
 }
 
-func (z *Zeus) startClimate(season zeus.SeasonFile) (rerr error) {
+// experimentState is the timeline of the running experiment. It is
+// saved with the season file so the experiment continues after a
+// restart.
+type experimentState struct {
+	Since     time.Time `yaml:"since"`
+	Suffix    string    `yaml:"suffix"`
+	Reference time.Time `yaml:"reference"`
+}
+
+func newExperimentState() experimentState {
+	now := time.Now()
+	return experimentState{
+		Since:     now,
+		Suffix:    now.Format("2006-01-02T150405"),
+		Reference: now.UTC(),
+	}
+}
+
+func (z *Zeus) startClimate(season zeus.SeasonFile) error {
+	return z.startExperiment(season, newExperimentState(), false)
+}
+
+// startExperiment starts the climate of season. If resume is true,
+// the logs of the experiment are appended to.
+func (z *Zeus) startExperiment(season zeus.SeasonFile, experiment experimentState, resume bool) (rerr error) {
 	if z.isRunning() == true {
 		return fmt.Errorf("Already started")
 	}
@@ -225,12 +387,15 @@ func (z *Zeus) startClimate(season zeus.SeasonFile) (rerr error) {
 	if err := z.checkSeason(season); err != nil {
 		return fmt.Errorf("invalid season file: %s", err)
 	}
-	z.since = time.Now()
-	suffix := z.since.Format("2006-01-02T150405")
+	z.since = experiment.Since
 	userID := ""
+	seasonFileHash, err := season.FileHash()
+	if err != nil {
+		return fmt.Errorf("could not hash season file: %w", err)
+	}
 
 	for name, climate := range season.Zones {
-		err := z.setupZoneClimate(name, suffix, z.definitions[name], climate, userID)
+		err := z.setupZoneClimate(name, experiment, resume, seasonFileHash, z.definitions[name], climate, userID)
 		if err != nil {
 			return fmt.Errorf("Could not setup zone '%s': %s", name, err)
 		}
@@ -248,7 +413,7 @@ func (z *Zeus) startClimate(season zeus.SeasonFile) (rerr error) {
 		go r.Run()
 	}
 
-	z.saveStaticState(season)
+	z.saveStaticState(season, experiment)
 
 	return nil
 }
@@ -284,7 +449,13 @@ func (z *Zeus) stopClimate() error {
 	}
 
 	z.clearStaticState()
+	z.closeClimate()
+	return nil
+}
 
+// closeClimate sends the safe state and closes the interfaces of the
+// running zones, but keeps the saved state.
+func (z *Zeus) closeClimate() {
 	z.logger.Debug("stopping climate")
 
 	z.closeRunners()
@@ -292,7 +463,6 @@ func (z *Zeus) stopClimate() error {
 	z.reset()
 
 	z.logger.Debug("climate stopped")
-	return nil
 }
 
 func endWithError(span trace.Span, err error) {
@@ -376,30 +546,87 @@ func (z *Zeus) GetStatus(ctx context.Context, e *zeuspb.Empty) (*zeuspb.Status,
 	return res, nil
 }
 
+// ListDevices returns the devices seen in all zones, including the
+// ones of past experiments.
+func (z *Zeus) ListDevices(ctx context.Context, e *zeuspb.Empty) (*zeuspb.DeviceList, error) {
+	var err error
+	ctx, span := z.tracer.Start(ctx, "zeus/ListDevices")
+	defer func() { endWithError(span, err) }()
+
+	z.mx.Lock()
+	defer z.mx.Unlock()
+
+	res := &zeuspb.DeviceList{}
+	for zone, inventory := range z.inventories {
+		for _, d := range inventory.Devices() {
+			_, _, outdated := inventory.Outdated(DeviceDefinition{Class: d.Class, ID: d.ID})
+			device := &zeuspb.Device{
+				Zone:     zone,
+				Class:    Name(d.Class),
+				Id:       uint32(d.ID),
+				Resets:   uint32(d.Resets),
+				Outdated: outdated,
+			}
+			if d.Version.Known() == true {
+				device.FirmwareVersion = d.Version.String()
+			}
+			if d.FirstSeen.IsZero() == false {
+				device.FirstSeen = timestamppb.New(d.FirstSeen)
+				device.LastSeen = timestamppb.New(d.LastSeen)
+			}
+			res.Devices = append(res.Devices, device)
+		}
+	}
+	sort.SliceStable(res.Devices, func(i, j int) bool {
+		return res.Devices[i].Zone < res.Devices[j].Zone
+	})
+	return res, nil
+}
+
 func (z *Zeus) stateFilePath() (string, error) {
 	return xdg.DataFile("fort-experiments/climate/current.season")
 }
 
-func (z *Zeus) saveStaticStateUnsafe(season zeus.SeasonFile) error {
+func (z *Zeus) experimentFilePath() (string, error) {
+	return xdg.DataFile("fort-experiments/climate/current.experiment")
+}
+
+func (z *Zeus) saveStaticStateUnsafe(season zeus.SeasonFile, experiment experimentState) error {
 	fpath, err := z.stateFilePath()
 	if err != nil {
 		return err
 	}
-	return season.WriteFile(fpath)
+	if err := season.WriteFile(fpath); err != nil {
+		return err
+	}
+	fpath, err = z.experimentFilePath()
+	if err != nil {
+		return err
+	}
+	data, err := yaml.Marshal(experiment)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(fpath, data, 0644)
 }
 
-func (z *Zeus) saveStaticState(season zeus.SeasonFile) {
-	if err := z.saveStaticStateUnsafe(season); err != nil {
+func (z *Zeus) saveStaticState(season zeus.SeasonFile, experiment experimentState) {
+	if err := z.saveStaticStateUnsafe(season, experiment); err != nil {
 		z.logger.WithError(err).Error("could not save state")
 	}
 }
 
 func (z *Zeus) clearStaticStateUnsafe() error {
-	filename, err := z.stateFilePath()
-	if err != nil {
-		return err
+	for _, path := range []func() (string, error){z.stateFilePath, z.experimentFilePath} {
+		filename, err := path()
+		if err != nil {
+			return err
+		}
+		if err := os.RemoveAll(filename); err != nil {
+			return err
+		}
 	}
-	return os.RemoveAll(filename)
+	return nil
 }
 
 func (z *Zeus) clearStaticState() {
@@ -408,6 +635,30 @@ func (z *Zeus) clearStaticState() {
 	}
 }
 
+// readExperimentState returns the saved experiment timeline, or false
+// if it was not saved by an older version.
+func (z *Zeus) readExperimentState() (experimentState, bool, error) {
+	res := experimentState{}
+	filename, err := z.experimentFilePath()
+	if err != nil {
+		return res, false, err
+	}
+	data, err := os.ReadFile(filename)
+	if os.IsNotExist(err) {
+		return res, false, nil
+	}
+	if err != nil {
+		return res, false, err
+	}
+	if err := yaml.Unmarshal(data, &res); err != nil {
+		return res, false, err
+	}
+	if res.Since.IsZero() || len(res.Suffix) == 0 || res.Reference.IsZero() {
+		return res, false, fmt.Errorf("incomplete experiment state in '%s'", filename)
+	}
+	return res, true, nil
+}
+
 func (z *Zeus) restoreStaticStateUnsafe() error {
 	filename, err := z.stateFilePath()
 	if err != nil {
@@ -429,7 +680,14 @@ func (z *Zeus) restoreStaticStateUnsafe() error {
 		}
 		return err
 	}
-	err = z.startClimate(*season)
+	experiment, resume, err := z.readExperimentState()
+	if err != nil {
+		return err
+	}
+	if resume == false {
+		experiment = newExperimentState()
+	}
+	err = z.startExperiment(*season, experiment, resume)
 	return err
 }
 
@@ -28,6 +28,8 @@ type ZeusSimulatorArgs struct {
 	timeRatio      float64
 	olympusAddress string
 	rpcPort        int
+	plant          PlantParameters
+	scenario       *Scenario
 }
 
 func NewZeusSimulator(a ZeusSimulatorArgs) (s *ZeusSimulator, err error) {
@@ -60,6 +62,8 @@ func NewZeusSimulator(a ZeusSimulatorArgs) (s *ZeusSimulator, err error) {
 				timeRatio:      a.timeRatio,
 				olympusAddress: a.olympusAddress,
 				rpcPort:        a.rpcPort,
+				plant:          a.plant,
+				scenario:       a.scenario,
 			})
 			if err != nil {
 				return err
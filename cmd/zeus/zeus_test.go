@@ -1,16 +1,22 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"io/ioutil"
+	"math"
 	"net/http"
 	"os"
+	"path/filepath"
 	"sync"
 	"time"
 
 	"github.com/adrg/xdg"
 	socketcan "github.com/atuleu/golang-socketcan"
+	"github.com/formicidae-tracker/libarke/src-go/arke"
 	"github.com/formicidae-tracker/zeus/internal/zeus"
+	"github.com/formicidae-tracker/zeus/pkg/zeuspb"
+	"google.golang.org/grpc"
 	. "gopkg.in/check.v1"
 )
 
@@ -24,10 +30,12 @@ type ZeusSuite struct {
 var _ = Suite(&ZeusSuite{})
 
 func (s *ZeusSuite) SetUpTest(c *C) {
-	s.oldDataDir = xdg.DataHome
+	s.oldDataDir = os.Getenv("XDG_DATA_HOME")
 	tmpdir, err := ioutil.TempDir("", "zeus-test-data-dir")
 	c.Assert(err, IsNil)
-	xdg.DataHome = tmpdir
+	// xdg only reads its base directories from the environment.
+	os.Setenv("XDG_DATA_HOME", tmpdir)
+	xdg.Reload()
 	s.dataDir = tmpdir
 	s.oldmux = http.DefaultServeMux
 	http.DefaultServeMux = http.NewServeMux()
@@ -36,9 +44,9 @@ func (s *ZeusSuite) SetUpTest(c *C) {
 		"slcan1": nil,
 	}
 	s.zeus, err = OpenZeus(Config{
-		Interfaces: map[string]string{
-			"slcan0": "foo",
-			"slcan1": "bar",
+		Interfaces: map[string]InterfaceDefinition{
+			"slcan0": {Type: SlcanInterface, Device: "foo"},
+			"slcan1": {Type: SlcanInterface, Device: "bar"},
 		},
 		Zones: map[string]ZoneDefinition{
 			"nest": ZoneDefinition{
@@ -61,7 +69,8 @@ func (s *ZeusSuite) SetUpTest(c *C) {
 
 func (s *ZeusSuite) TearDownTest(c *C) {
 	http.DefaultServeMux = s.oldmux
-	xdg.DataHome = s.oldDataDir
+	os.Setenv("XDG_DATA_HOME", s.oldDataDir)
+	xdg.Reload()
 	if len(s.dataDir) > 0 {
 		os.RemoveAll(s.dataDir)
 		s.dataDir = ""
@@ -93,6 +102,123 @@ func (s *ZeusSuite) TestWrongConfig(c *C) {
 	c.Check(err, ErrorMatches, "Invalid config:.*")
 }
 
+func (s *ZeusSuite) TestReloadConfig(c *C) {
+	res, err := s.zeus.reloadConfig(s.zeus.config)
+	c.Check(err, IsNil)
+	c.Check(res, DeepEquals, &ConfigReload{})
+
+	_, err = s.zeus.reloadConfig(Config{
+		Zones: map[string]ZoneDefinition{
+			"box": ZoneDefinition{CANInterface: "slcan0", DevicesID: 1},
+		},
+	})
+	c.Check(err, ErrorMatches, "Invalid config:.*")
+
+	c.Assert(s.zeus.startClimate(zeus.SeasonFile{
+		Zones: map[string]zeus.ZoneClimate{
+			"nest": zeus.ZoneClimate{States: []zeus.State{{Name: "always-on"}}},
+		},
+	}), IsNil)
+	defer func() {
+		// lets the runner start before closing it.
+		time.Sleep(50 * time.Millisecond)
+		c.Check(s.zeus.stopClimate(), IsNil)
+	}()
+
+	res, err = s.zeus.reloadConfig(Config{
+		Olympus:        "olympus.local",
+		MetricsAddress: ":9100",
+		Interfaces: map[string]InterfaceDefinition{
+			"slcan0": {Type: SlcanInterface, Device: "foo", Bitrate: 500000},
+			"slcan1": {Type: SlcanInterface, Device: "baz"},
+			"slcan2": {Type: SlcanInterface, Device: "bar"},
+		},
+		Zones: map[string]ZoneDefinition{
+			"nest": ZoneDefinition{
+				CANInterface:   "slcan0",
+				DevicesID:      1,
+				TemperatureAux: 1,
+			},
+			"foraging": ZoneDefinition{
+				CANInterface: "slcan0",
+				DevicesID:    3,
+			},
+			"tunnel": ZoneDefinition{
+				CANInterface: "slcan2",
+				DevicesID:    1,
+			},
+			"box": ZoneDefinition{
+				CANInterface: "slcan1",
+				DevicesID:    2,
+			},
+		},
+	})
+	c.Assert(err, IsNil)
+	c.Check(res.Applied, DeepEquals, []string{
+		"interface slcan1 changed, run zeus open-interfaces again to apply it",
+		"interface slcan2 added, run zeus open-interfaces again to open it",
+		"zone 'box' added",
+		"zone 'foraging' changed",
+		"zone 'tunnel' changed",
+	})
+	c.Check(res.Refused, DeepEquals, []string{
+		"olympus: climate is running, its reports cannot be enabled or disabled",
+		"metrics-address: requires restarting zeus",
+		"interface slcan0: used by a running zone",
+		"zone 'nest': climate is running",
+	})
+	c.Check(s.zeus.olympusHost, Equals, "")
+	c.Check(s.zeus.definitions["foraging"].DevicesID, Equals, uint(3))
+	c.Check(s.zeus.definitions["nest"].TemperatureAux, Equals, 0)
+	c.Check(s.zeus.inventories["box"], NotNil)
+}
+
+func (s *ZeusSuite) TestReloadOlympusHost(c *C) {
+	s.zeus.config.Olympus = "localhost:12345"
+	s.zeus.olympusHost = s.zeus.config.Olympus
+	c.Assert(s.zeus.startClimate(zeus.SeasonFile{
+		Zones: map[string]zeus.ZoneClimate{
+			"nest": zeus.ZoneClimate{States: []zeus.State{{Name: "always-on"}}},
+		},
+	}), IsNil)
+	defer func() {
+		c.Check(s.zeus.stopClimate(), IsNil)
+	}()
+	runner, ok := s.zeus.runners["nest"].(*zoneClimateRunner)
+	c.Assert(ok, Equals, true)
+	c.Assert(runner.rpc, NotNil)
+
+	config := s.zeus.config
+	config.Olympus = "localhost:12346"
+	res, err := s.zeus.reloadConfig(config)
+	c.Assert(err, IsNil)
+	c.Check(res.Applied, DeepEquals, []string{"olympus: 'localhost:12346'"})
+	c.Check(res.Refused, HasLen, 0)
+	c.Check(s.zeus.olympusHost, Equals, "localhost:12346")
+	// the reporter reconnects once it received the new host.
+	for i := 0; i < 40 && len(runner.rpc.hosts) > 0; i++ {
+		time.Sleep(5 * time.Millisecond)
+	}
+	c.Check(runner.rpc.hosts, HasLen, 0)
+
+	config.Olympus = ""
+	res, err = s.zeus.reloadConfig(config)
+	c.Assert(err, IsNil)
+	c.Check(res.Applied, HasLen, 0)
+	c.Check(res.Refused, DeepEquals, []string{"olympus: climate is running, its reports cannot be enabled or disabled"})
+	c.Check(s.zeus.olympusHost, Equals, "localhost:12346")
+}
+
+func (s *ZeusSuite) TestReloadVerbosityRequiresTelemetry(c *C) {
+	config := s.zeus.config
+	config.Verbosity = 2
+	res, err := s.zeus.reloadConfig(config)
+	c.Assert(err, IsNil)
+	c.Check(res.Applied, HasLen, 0)
+	c.Check(res.Refused, DeepEquals, []string{"verbosity: requires otel_collector_endpoint"})
+	c.Check(s.zeus.config.Verbosity, Equals, 0)
+}
+
 func (s *ZeusSuite) TestShutdown(c *C) {
 	wg := sync.WaitGroup{}
 	wg.Add(1)
@@ -107,6 +233,35 @@ func (s *ZeusSuite) TestShutdown(c *C) {
 
 }
 
+func (s *ZeusSuite) TestShutdownKeepsSavedState(c *C) {
+	wg := sync.WaitGroup{}
+	wg.Add(1)
+	go func() {
+		s.zeus.run()
+		wg.Done()
+	}()
+	time.Sleep(100 * time.Millisecond)
+
+	c.Assert(s.zeus.startClimate(zeus.SeasonFile{
+		Zones: map[string]zeus.ZoneClimate{
+			"nest": zeus.ZoneClimate{States: []zeus.State{{Name: "day", Temperature: 26.0}}},
+		},
+	}), IsNil)
+	// lets runners start
+	time.Sleep(100 * time.Millisecond)
+
+	c.Check(s.zeus.shutdown(), IsNil)
+	wg.Wait()
+	c.Check(s.zeus.isRunning(), Equals, false)
+
+	for _, path := range []func() (string, error){s.zeus.stateFilePath, s.zeus.experimentFilePath} {
+		filename, err := path()
+		c.Assert(err, IsNil)
+		_, err = os.Stat(filename)
+		c.Check(err, IsNil)
+	}
+}
+
 func (s *ZeusSuite) TestStartStop(c *C) {
 	c.Check(s.zeus.isRunning(), Equals, false)
 	c.Check(s.zeus.stopClimate(), ErrorMatches, "Not running")
@@ -130,3 +285,508 @@ func (s *ZeusSuite) TestStartStop(c *C) {
 	c.Check(s.zeus.startClimate(zeus.SeasonFile{}), ErrorMatches, "Already started")
 	c.Check(s.zeus.stopClimate(), IsNil)
 }
+
+func (s *ZeusSuite) TestLogsOfStoppedZone(c *C) {
+	c.Assert(s.zeus.startClimate(zeus.SeasonFile{
+		Zones: map[string]zeus.ZoneClimate{
+			"nest": zeus.ZoneClimate{States: []zeus.State{{Name: "always-on"}}},
+		},
+	}), IsNil)
+	runner := s.zeus.runners["nest"]
+	// lets the runner start before closing it.
+	time.Sleep(50 * time.Millisecond)
+	c.Check(s.zeus.stopClimate(), IsNil)
+
+	_, err := runner.ClimateLog(0, 0)
+	c.Check(err, ErrorMatches, "zone 'nest' stopped")
+	_, err = runner.AlarmLog(0, 0)
+	c.Check(err, ErrorMatches, "zone 'nest' stopped")
+}
+
+// climateLogStream collects the pages sent by GetClimateLog.
+type climateLogStream struct {
+	grpc.ServerStream
+	pages []*zeuspb.ClimateLogPage
+}
+
+func (s *climateLogStream) Context() context.Context { return context.Background() }
+
+func (s *climateLogStream) Send(p *zeuspb.ClimateLogPage) error {
+	s.pages = append(s.pages, p)
+	return nil
+}
+
+// alarmLogStream collects the pages sent by GetAlarmLog.
+type alarmLogStream struct {
+	grpc.ServerStream
+	pages []*zeuspb.AlarmLogPage
+}
+
+func (s *alarmLogStream) Context() context.Context { return context.Background() }
+
+func (s *alarmLogStream) Send(p *zeuspb.AlarmLogPage) error {
+	s.pages = append(s.pages, p)
+	return nil
+}
+
+func (s *ZeusSuite) TestStreamsZoneLogsInPages(c *C) {
+	start := time.Now().Round(0)
+	runner := backlogRunner{
+		events: []zeus.AlarmEvent{
+			{Identifier: "climate.temperature", Status: zeus.AlarmOn, Flags: zeus.Emergency, Time: start},
+		},
+	}
+	for i := 0; i < 5; i++ {
+		runner.reports = append(runner.reports, zeus.ClimateReport{
+			Time:         start.Add(time.Duration(i) * time.Second),
+			Humidity:     zeus.Humidity(50 + i),
+			Temperatures: []zeus.Temperature{20, zeus.UndefinedTemperature},
+		})
+	}
+	s.zeus.runners = map[string]ZoneClimateRunner{"nest": runner}
+	defer func() { s.zeus.runners = map[string]ZoneClimateRunner{} }()
+
+	climate := &climateLogStream{}
+	c.Check(s.zeus.GetClimateLog(&zeuspb.LogRequest{Zone: "box"}, climate), ErrorMatches, "zone 'box' is not running")
+	c.Assert(s.zeus.GetClimateLog(&zeuspb.LogRequest{Zone: "nest", PageSize: 2}, climate), IsNil)
+	c.Assert(climate.pages, HasLen, 3)
+	c.Check(climate.pages[2].Entries, HasLen, 1)
+	last := climate.pages[2].Entries[0]
+	c.Check(last.Time.AsTime().Equal(runner.reports[4].Time), Equals, true)
+	c.Check(last.Humidity, Equals, float32(54))
+	c.Check(last.Temperatures, HasLen, 2)
+	c.Check(math.IsInf(float64(last.Temperatures[1]), -1), Equals, true)
+
+	alarms := &alarmLogStream{}
+	c.Assert(s.zeus.GetAlarmLog(&zeuspb.LogRequest{Zone: "nest"}, alarms), IsNil)
+	c.Assert(alarms.pages, HasLen, 1)
+	c.Assert(alarms.pages[0].Events, HasLen, 1)
+	event := alarms.pages[0].Events[0]
+	c.Check(event.Identifier, Equals, "climate.temperature")
+	c.Check(event.On, Equals, true)
+	c.Check(event.Flags, Equals, int32(zeus.Emergency))
+}
+
+func (s *ZeusSuite) TestRestoreResumesExperiment(c *C) {
+	season := zeus.SeasonFile{
+		Zones: map[string]zeus.ZoneClimate{
+			"nest": zeus.ZoneClimate{
+				States: []zeus.State{
+					zeus.State{
+						Name:         "day",
+						Temperature:  26.0,
+						Humidity:     50,
+						Wind:         100,
+						VisibleLight: 100,
+						UVLight:      100,
+					},
+				},
+			},
+		},
+	}
+	c.Assert(s.zeus.startClimate(season), IsNil)
+	since := s.zeus.since
+	// lets runners start
+	time.Sleep(100 * time.Millisecond)
+
+	// simulates a restart of the daemon
+	s.zeus.closeRunners()
+	s.zeus.closeDispatchers()
+	s.zeus.reset()
+	time.Sleep(1100 * time.Millisecond)
+
+	s.zeus.restoreStaticState()
+	c.Assert(s.zeus.isRunning(), Equals, true)
+	c.Check(s.zeus.since.Equal(since), Equals, true)
+
+	logs, err := filepath.Glob(filepath.Join(s.dataDir, "fort-experiments/climate/nest.*.climate.*"))
+	c.Assert(err, IsNil)
+	c.Check(logs, DeepEquals, []string{
+		filepath.Join(s.dataDir, "fort-experiments/climate/nest."+since.Format("2006-01-02T150405")+".climate.txt"),
+	})
+	time.Sleep(100 * time.Millisecond)
+
+	c.Assert(s.zeus.stopClimate(), IsNil)
+	c.Assert(s.zeus.startClimate(season), IsNil)
+	c.Check(s.zeus.since.After(since), Equals, true)
+	time.Sleep(100 * time.Millisecond)
+	c.Check(s.zeus.stopClimate(), IsNil)
+}
+
+func (s *ZeusSuite) TestRetentionPrunesLogsOfPreviousStarts(c *C) {
+	definition := s.zeus.definitions["nest"]
+	definition.LogRotation.Retention = time.Hour
+	s.zeus.definitions["nest"] = definition
+	season := zeus.SeasonFile{
+		Zones: map[string]zeus.ZoneClimate{
+			"nest": zeus.ZoneClimate{States: []zeus.State{{Name: "day", Temperature: 26.0}}},
+		},
+	}
+	dir := filepath.Join(s.dataDir, "fort-experiments/climate")
+	logsOf := func(since time.Time) []string {
+		suffix := since.Format("2006-01-02T150405")
+		return []string{
+			filepath.Join(dir, "nest."+suffix+".alarms.txt"),
+			filepath.Join(dir, "nest."+suffix+".climate.txt"),
+		}
+	}
+
+	c.Assert(s.zeus.startClimate(season), IsNil)
+	first := logsOf(s.zeus.since)
+	time.Sleep(100 * time.Millisecond)
+	c.Assert(s.zeus.stopClimate(), IsNil)
+	old := time.Now().Add(-2 * time.Hour)
+	for _, filename := range first {
+		c.Assert(os.Chtimes(filename, old, old), IsNil)
+	}
+	// a file of the previous start within the retention is kept.
+	recent := filepath.Join(dir, "nest.2023-03-01T120000.climate.txt.1.gz")
+	c.Assert(ioutil.WriteFile(recent, nil, 0644), IsNil)
+	// the next start has a different suffix.
+	time.Sleep(1100 * time.Millisecond)
+
+	c.Assert(s.zeus.startClimate(season), IsNil)
+	second := logsOf(s.zeus.since)
+	time.Sleep(100 * time.Millisecond)
+	c.Check(s.zeus.stopClimate(), IsNil)
+
+	logs, err := filepath.Glob(filepath.Join(dir, "nest.*"))
+	c.Assert(err, IsNil)
+	c.Check(logs, DeepEquals, append(append([]string{recent}, second...), filepath.Join(dir, "nest.db")))
+}
+
+func (s *ZeusSuite) TestEmulatedClimate(c *C) {
+	var emulators []*ArkeEmulator
+	factory := emulatorFactory(s.zeus.definitions, DefaultPlantParameters(), nil, 600)
+	s.zeus.intfFactory = func(ifname string) (socketcan.RawInterface, error) {
+		intf, err := factory(ifname)
+		if err == nil {
+			emulators = append(emulators, intf.(*ArkeEmulator))
+		}
+		return intf, err
+	}
+
+	c.Assert(s.zeus.startClimate(zeus.SeasonFile{
+		Zones: map[string]zeus.ZoneClimate{
+			"nest": zeus.ZoneClimate{
+				States: []zeus.State{
+					zeus.State{
+						Name:         "day",
+						Temperature:  26.0,
+						Humidity:     60,
+						Wind:         100,
+						VisibleLight: 100,
+						UVLight:      zeus.UndefinedLight,
+					},
+				},
+			},
+		},
+	}), IsNil)
+	defer func() {
+		c.Check(s.zeus.stopClimate(), IsNil)
+	}()
+	c.Assert(emulators, HasLen, 1)
+
+	var temperature float32
+	for i := 0; i < 40; i++ {
+		time.Sleep(100 * time.Millisecond)
+		last := s.zeus.runners["nest"].Last()
+		if last.Temperature != nil {
+			temperature = *last.Temperature
+			if temperature > 25.95 {
+				break
+			}
+		}
+	}
+	c.Check(math.Abs(float64(temperature)-26.0) < 0.1, Equals, true)
+	last := s.zeus.runners["nest"].Last()
+	c.Check(last.DewPoint, Not(IsNil))
+	c.Check(last.VaporPressureDeficit, Not(IsNil))
+
+	sp, ok := emulators[0].LastSetPoint(arke.ZeusClass, 1).(*arke.ZeusSetPoint)
+	c.Assert(ok, Equals, true)
+	c.Check(math.Abs(float64(sp.Humidity)-60.0) < 0.05, Equals, true)
+	light, ok := emulators[0].LastSetPoint(arke.HeliosClass, 1).(*arke.HeliosSetPoint)
+	c.Assert(ok, Equals, true)
+	c.Check(light.Visible, Equals, uint8(255))
+	c.Check(emulators[0].LastSetPoint(arke.ZeusClass, 2), IsNil)
+}
+
+func (s *ZeusSuite) TestEmulatedSensorFailure(c *C) {
+	var emulators []*ArkeEmulator
+	factory := emulatorFactory(s.zeus.definitions, DefaultPlantParameters(), &Scenario{
+		Faults: []Fault{{Kind: SensorNaN, Zone: "nest"}},
+	}, 600)
+	s.zeus.intfFactory = func(ifname string) (socketcan.RawInterface, error) {
+		intf, err := factory(ifname)
+		if err == nil {
+			emulators = append(emulators, intf.(*ArkeEmulator))
+		}
+		return intf, err
+	}
+
+	c.Assert(s.zeus.startClimate(zeus.SeasonFile{
+		Zones: map[string]zeus.ZoneClimate{
+			"nest": zeus.ZoneClimate{
+				States: []zeus.State{
+					zeus.State{
+						Name:         "day",
+						Temperature:  26.0,
+						Humidity:     60,
+						Wind:         100,
+						VisibleLight: 100,
+						UVLight:      zeus.UndefinedLight,
+					},
+				},
+			},
+		},
+	}), IsNil)
+	defer func() {
+		c.Check(s.zeus.stopClimate(), IsNil)
+	}()
+	c.Assert(emulators, HasLen, 1)
+
+	// the climate control resets the Zeus once its sensor fails.
+	resets := 0
+	for i := 0; i < 40 && resets == 0; i++ {
+		time.Sleep(100 * time.Millisecond)
+		resets = emulators[0].Resets(arke.ZeusClass, 1)
+	}
+	c.Check(resets, Equals, 1)
+	c.Check(emulators[0].Resets(arke.ZeusClass, 2), Equals, 0)
+
+	list, err := s.zeus.ListDevices(context.Background(), &zeuspb.Empty{})
+	c.Assert(err, IsNil)
+	var device *zeuspb.Device
+	for _, d := range list.Devices {
+		if d.Zone == "nest" && d.Class == "Zeus" {
+			device = d
+		}
+	}
+	c.Assert(device, NotNil)
+	c.Check(device.Id, Equals, uint32(1))
+	c.Check(device.FirmwareVersion, Equals, "1.0.0")
+	c.Check(device.Resets, Equals, uint32(1))
+	c.Check(device.Outdated, Equals, false)
+	c.Check(device.FirstSeen, NotNil)
+}
+
+func (s *ZeusSuite) TestEmulatedSafeState(c *C) {
+	nest := s.zeus.definitions["nest"]
+	nest.SafeState = SafeStateDefinition{Temperature: SafeTemperatureOff, VisibleLight: 10}
+	s.zeus.definitions["nest"] = nest
+
+	var emulators []*ArkeEmulator
+	factory := emulatorFactory(s.zeus.definitions, DefaultPlantParameters(), nil, 600)
+	s.zeus.intfFactory = func(ifname string) (socketcan.RawInterface, error) {
+		intf, err := factory(ifname)
+		if err == nil {
+			emulators = append(emulators, intf.(*ArkeEmulator))
+		}
+		return intf, err
+	}
+
+	c.Assert(s.zeus.startClimate(zeus.SeasonFile{
+		Zones: map[string]zeus.ZoneClimate{
+			"nest": zeus.ZoneClimate{
+				States: []zeus.State{
+					zeus.State{
+						Name:         "day",
+						Temperature:  26.0,
+						Humidity:     60,
+						Wind:         100,
+						VisibleLight: 100,
+						UVLight:      zeus.UndefinedLight,
+					},
+				},
+			},
+		},
+	}), IsNil)
+	c.Assert(emulators, HasLen, 1)
+
+	for i := 0; i < 40; i++ {
+		time.Sleep(50 * time.Millisecond)
+		if emulators[0].LastSetPoint(arke.HeliosClass, 1) != nil {
+			break
+		}
+	}
+	c.Check(emulators[0].Resets(arke.ZeusClass, 1), Equals, 0)
+	c.Check(s.zeus.stopClimate(), IsNil)
+
+	light, ok := emulators[0].LastSetPoint(arke.HeliosClass, 1).(*arke.HeliosSetPoint)
+	c.Assert(ok, Equals, true)
+	c.Check(light.Visible, Equals, uint8(25))
+	c.Check(light.UV, Equals, uint8(0))
+	c.Check(emulators[0].Resets(arke.ZeusClass, 1), Equals, 1)
+}
+
+func (s *ZeusSuite) TestEmulatedBusDownIdlesZone(c *C) {
+	var emulators []*ArkeEmulator
+	factory := emulatorFactory(s.zeus.definitions, DefaultPlantParameters(), &Scenario{
+		Faults: []Fault{{Kind: BusDown, At: 5 * time.Minute, Duration: 2 * time.Minute}},
+	}, 600)
+	s.zeus.intfFactory = func(ifname string) (socketcan.RawInterface, error) {
+		intf, err := factory(ifname)
+		if err == nil {
+			emulators = append(emulators, intf.(*ArkeEmulator))
+		}
+		return intf, err
+	}
+
+	c.Assert(s.zeus.startClimate(zeus.SeasonFile{
+		Zones: map[string]zeus.ZoneClimate{
+			"nest": zeus.ZoneClimate{
+				States: []zeus.State{
+					zeus.State{
+						Name:         "day",
+						Temperature:  26.0,
+						Humidity:     60,
+						Wind:         100,
+						VisibleLight: 100,
+						UVLight:      zeus.UndefinedLight,
+					},
+				},
+			},
+		},
+	}), IsNil)
+	defer func() {
+		c.Check(s.zeus.stopClimate(), IsNil)
+	}()
+	c.Assert(emulators, HasLen, 1)
+	runner, ok := s.zeus.runners["nest"].(*zoneClimateRunner)
+	c.Assert(ok, Equals, true)
+	idled := func() bool {
+		runner.idleMx.Lock()
+		defer runner.idleMx.Unlock()
+		return runner.idled
+	}
+	// the dispatcher retries receiving after LinkMinBackoff.
+	waitIdled := func(expected bool) bool {
+		for i := 0; i < 80; i++ {
+			if idled() == expected {
+				return true
+			}
+			time.Sleep(50 * time.Millisecond)
+		}
+		return false
+	}
+
+	c.Check(waitIdled(true), Equals, true, Commentf("the zone was not idled on bus down"))
+	c.Check(waitIdled(false), Equals, true, Commentf("the climate did not resume with the bus"))
+
+	light, ok := emulators[0].LastSetPoint(arke.HeliosClass, 1).(*arke.HeliosSetPoint)
+	c.Assert(ok, Equals, true)
+	c.Check(light.Visible, Equals, uint8(255))
+}
+
+func (s *ZeusSuite) TestEmulatedNotus(c *C) {
+	nest := s.zeus.definitions["nest"]
+	nest.HasNotusDevice = true
+	nest.NotusWindShare = 40
+	s.zeus.definitions["nest"] = nest
+
+	var emulators []*ArkeEmulator
+	factory := emulatorFactory(s.zeus.definitions, DefaultPlantParameters(), nil, 600)
+	s.zeus.intfFactory = func(ifname string) (socketcan.RawInterface, error) {
+		intf, err := factory(ifname)
+		if err == nil {
+			emulators = append(emulators, intf.(*ArkeEmulator))
+		}
+		return intf, err
+	}
+
+	c.Assert(s.zeus.startClimate(zeus.SeasonFile{
+		Zones: map[string]zeus.ZoneClimate{
+			"nest": zeus.ZoneClimate{
+				States: []zeus.State{
+					zeus.State{
+						Name:         "day",
+						Temperature:  26.0,
+						Humidity:     zeus.UndefinedHumidity,
+						Wind:         100,
+						VisibleLight: zeus.UndefinedLight,
+						UVLight:      zeus.UndefinedLight,
+					},
+				},
+			},
+		},
+	}), IsNil)
+	defer func() {
+		c.Check(s.zeus.stopClimate(), IsNil)
+	}()
+	c.Assert(emulators, HasLen, 1)
+
+	var notus *arke.NotusSetPoint
+	for i := 0; i < 40 && notus == nil; i++ {
+		time.Sleep(50 * time.Millisecond)
+		notus, _ = emulators[0].LastSetPoint(arke.NotusClass, 1).(*arke.NotusSetPoint)
+	}
+	c.Assert(notus, NotNil)
+	c.Check(notus.Power, Equals, uint8(102))
+	sp, ok := emulators[0].LastSetPoint(arke.ZeusClass, 1).(*arke.ZeusSetPoint)
+	c.Assert(ok, Equals, true)
+	c.Check(sp.Wind, Equals, uint8(153))
+
+	// the Notus heartbeats are monitored.
+	seen := false
+	for i := 0; i < 40 && seen == false; i++ {
+		list, err := s.zeus.ListDevices(context.Background(), &zeuspb.Empty{})
+		c.Assert(err, IsNil)
+		for _, d := range list.Devices {
+			if d.Zone == "nest" && d.Class == "Notus" && d.LastSeen != nil {
+				seen = true
+			}
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+	c.Check(seen, Equals, true)
+}
+
+func (s *ZeusSuite) TestEmulatedHumidityOnly(c *C) {
+	nest := s.zeus.definitions["nest"]
+	nest.Channels = ChannelsDefinition{Temperature: MonitoredChannel}
+	s.zeus.definitions["nest"] = nest
+
+	var emulators []*ArkeEmulator
+	factory := emulatorFactory(s.zeus.definitions, DefaultPlantParameters(), nil, 600)
+	s.zeus.intfFactory = func(ifname string) (socketcan.RawInterface, error) {
+		intf, err := factory(ifname)
+		if err == nil {
+			emulators = append(emulators, intf.(*ArkeEmulator))
+		}
+		return intf, err
+	}
+
+	c.Assert(s.zeus.startClimate(zeus.SeasonFile{
+		Zones: map[string]zeus.ZoneClimate{
+			"nest": zeus.ZoneClimate{
+				States: []zeus.State{
+					zeus.State{
+						Name:         "day",
+						Temperature:  30.0,
+						Humidity:     60,
+						Wind:         zeus.UndefinedWind,
+						VisibleLight: zeus.UndefinedLight,
+						UVLight:      zeus.UndefinedLight,
+					},
+				},
+			},
+		},
+	}), IsNil)
+	defer func() {
+		c.Check(s.zeus.stopClimate(), IsNil)
+	}()
+	c.Assert(emulators, HasLen, 1)
+
+	// the set point is sent once the temperature is measured.
+	var sp *arke.ZeusSetPoint
+	for i := 0; i < 40 && sp == nil; i++ {
+		time.Sleep(50 * time.Millisecond)
+		sp, _ = emulators[0].LastSetPoint(arke.ZeusClass, 1).(*arke.ZeusSetPoint)
+	}
+	c.Assert(sp, NotNil)
+	c.Check(math.Abs(float64(sp.Humidity)-60.0) < 0.05, Equals, true)
+	// follows the ambient temperature of the plant.
+	c.Check(math.Abs(float64(sp.Temperature)-22.0) < 0.5, Equals, true, Commentf("temperature: %f", sp.Temperature))
+	c.Check(sp.Wind, Equals, uint8(0))
+}
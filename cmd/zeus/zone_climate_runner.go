@@ -2,9 +2,11 @@ package main
 
 import (
 	"fmt"
+	"os"
 	"path"
 	"path/filepath"
 	"sync"
+	"time"
 
 	"github.com/adrg/xdg"
 	"github.com/formicidae-tracker/libarke/src-go/arke"
@@ -23,21 +25,43 @@ type ZoneClimateRunner interface {
 }
 
 type ZoneClimateRunnerOptions struct {
-	Name        string
-	Definition  ZoneDefinition
-	FileSuffix  string
-	Dispatcher  ArkeDispatcher
-	Climate     zeus.ZoneClimate
-	OlympusHost string
+	Name           string
+	Definition     ZoneDefinition
+	FileSuffix     string
+	Dispatcher     ArkeDispatcher
+	Climate        zeus.ZoneClimate
+	OlympusHost    string
+	SeasonFileHash string
+	// Since is the start of the experiment, and Reference the date
+	// day-relative transitions are computed from. Resume is set when
+	// the experiment continues after a restart, so logs are appended
+	// to.
+	Since     time.Time
+	Reference time.Time
+	Resume    bool
+	// Inventory records the devices of the zone. A new one is used if
+	// nil.
+	Inventory *deviceInventory
 }
 
 type zoneClimateRunner struct {
+	zone       string
 	logger     *logrus.Entry
 	dispatcher ArkeDispatcher
 
 	quit, done chan struct{}
 
 	messages <-chan *StampedMessage
+	links    <-chan bool
+
+	// protects idled and current from the interpoler.
+	idleMx sync.Mutex
+	// idled is set while the devices are left in the safe state.
+	idled   bool
+	current *zeus.State
+	// applied is closed once the states of the interpoler are
+	// applied and the devices idled.
+	applied chan struct{}
 
 	interpoler      Interpoler
 	capabilities    []capability
@@ -45,17 +69,22 @@ type zoneClimateRunner struct {
 	alarmMonitor    AlarmMonitor
 
 	reporters        []Reporter
+	rpc              *RPCReporter
 	climateReporters []ClimateReporter
 	targetReporters  []TargetReporter
 	alarmReporters   []AlarmReporter
 	last             *lastStateReporter
 
 	devices   map[arke.NodeClass]*Device
+	inventory *deviceInventory
+	safeState SafeStateDefinition
 	callbacks map[arke.MessageClass][]callback
 
 	climateLog, alarmLog string
-	climateLogData       []zeus.ClimateReport
-	alarmLogData         []zeus.AlarmEvent
+	// protects store, which is closed once the zone stopped.
+	storeMx sync.RWMutex
+	store   *climateStore
+	since   time.Time
 }
 
 func (r *zoneClimateRunner) spawnAlarmMonitor(wg *sync.WaitGroup) {
@@ -99,13 +128,15 @@ func (r *zoneClimateRunner) spawnInterpoler(wg *sync.WaitGroup) {
 	}()
 	<-ready
 
+	r.applied = make(chan struct{})
 	wg.Add(1)
 	go func() {
+		defer close(r.applied)
 		for newState := range r.interpoler.States() {
-			for _, c := range r.capabilities {
-				c.Action(newState)
-			}
+			r.apply(newState)
 		}
+		// the climate stopped, no further state will be sent.
+		r.idle()
 		wg.Done()
 	}()
 
@@ -141,6 +172,56 @@ func (r *zoneClimateRunner) spawnPresenceMonitor(wg *sync.WaitGroup) {
 	<-ready
 }
 
+// apply sends s to the devices, unless they are idled.
+func (r *zoneClimateRunner) apply(s zeus.State) {
+	r.idleMx.Lock()
+	defer r.idleMx.Unlock()
+	r.current = &s
+	if r.idled == true {
+		return
+	}
+	r.action(s)
+}
+
+func (r *zoneClimateRunner) action(s zeus.State) {
+	for _, c := range r.capabilities {
+		if err := c.Action(s); err != nil {
+			continue
+		}
+		if name, ok := setPointCapabilityName(c); ok == true {
+			instruments.setPointSent(r.zone, name)
+		}
+	}
+}
+
+// resume sends the current state again after the devices were idled.
+func (r *zoneClimateRunner) resume() {
+	r.idleMx.Lock()
+	defer r.idleMx.Unlock()
+	if r.idled == false {
+		return
+	}
+	r.idled = false
+	r.logger.Info("resuming climate")
+	if r.current != nil {
+		r.action(*r.current)
+	}
+}
+
+// idle leaves all devices in the safe state of the zone, until resume
+// is called.
+func (r *zoneClimateRunner) idle() {
+	r.idleMx.Lock()
+	defer r.idleMx.Unlock()
+	r.idled = true
+	r.logger.Info("sending safe state")
+	for _, c := range r.capabilities {
+		if err := c.Idle(r.safeState); err != nil {
+			r.logger.WithError(err).Error("could not send safe state")
+		}
+	}
+}
+
 func (r *zoneClimateRunner) spawnTasks(wg *sync.WaitGroup) {
 	r.spawnAlarmMonitor(wg)
 	r.spawnReporters(wg)
@@ -153,6 +234,8 @@ func (r *zoneClimateRunner) stopTasks() {
 	if err != nil {
 		r.logger.WithError(err).Error("interpoler did not close gracefully")
 	}
+	// the capabilities are idled before being closed.
+	<-r.applied
 	err = r.presenceMonitor.Close()
 	if err != nil {
 		r.logger.WithError(err).Error("presenceMonitorer did not close gracefully")
@@ -168,7 +251,8 @@ func (r *zoneClimateRunner) stopTasks() {
 func (r *zoneClimateRunner) handleMessage(m *StampedMessage, wg *sync.WaitGroup) {
 	switch m.M.MessageClassID() {
 	case arke.HeartBeatMessage:
-		r.presenceMonitor.Ping(m.M.(*arke.HeartBeatData).Class, m.ID)
+		h := m.M.(*arke.HeartBeatData)
+		r.presenceMonitor.Ping(h.Class, m.ID, heartbeatVersion(h))
 	case arke.ErrorReportMessage:
 		e := m.M.(*arke.ErrorReportData)
 		r.alarmMonitor.Inbound() <- zeus.NewDeviceInternalError(r.dispatcher.Name(), e.Class, e.ID, e.ErrorCode)
@@ -179,6 +263,7 @@ func (r *zoneClimateRunner) handleMessage(m *StampedMessage, wg *sync.WaitGroup)
 		}
 		wg.Add(1)
 		go func(m *StampedMessage, alarms chan<- zeus.Alarm) {
+			defer instruments.callbackDone(r.zone, m.M.MessageClassID(), time.Now())
 			for _, callback := range callbacks {
 				err := callback(alarms, m)
 				if err != nil {
@@ -202,6 +287,7 @@ func (r *zoneClimateRunner) Run() {
 
 	defer func() {
 		wg.Wait()
+		r.closeStore()
 		close(r.done)
 	}()
 
@@ -213,8 +299,25 @@ func (r *zoneClimateRunner) Run() {
 			wgCallback.Wait()
 			r.stopTasks()
 			return
-		case m := <-r.messages:
+		case m, ok := <-r.messages:
+			if ok == false {
+				r.logger.Error("dispatcher stopped")
+				r.idle()
+				// blocks until closed.
+				r.messages = nil
+				continue
+			}
 			r.handleMessage(m, &wgCallback)
+		case up, ok := <-r.links:
+			if ok == false {
+				r.links = nil
+			} else if up == true {
+				r.resume()
+			} else {
+				// the safe state may not reach the devices until
+				// the link is back, where the climate resumes.
+				r.idle()
+			}
 		}
 	}
 
@@ -232,7 +335,7 @@ func (r *zoneClimateRunner) Close() error {
 }
 
 func (r *zoneClimateRunner) setUpInterpoler(o ZoneClimateRunnerOptions) error {
-	interpoler, err := NewInterpoler(o.Name, o.Climate.States, o.Climate.Transitions)
+	interpoler, err := NewInterpoler(o.Name, o.Climate.States, o.Climate.Transitions, o.Reference)
 	if err != nil {
 		return err
 	}
@@ -245,15 +348,22 @@ func (r *zoneClimateRunner) setUpRPC(o ZoneClimateRunnerOptions) error {
 		return nil
 	}
 
+	outboxFile, err := xdg.DataFile(filepath.Join("fort-experiments/climate", o.Name+".outbox.txt"))
+	if err != nil {
+		return err
+	}
+
 	rpc, err := NewRPCReporter(RPCReporterOptions{
 		zone:           o.Name,
 		olympusAddress: o.OlympusHost,
 		climate:        o.Climate,
 		runner:         r,
+		outboxFile:     outboxFile,
 	})
 	if err != nil {
 		return err
 	}
+	r.rpc = rpc
 	r.reporters = append(r.reporters, rpc)
 	r.targetReporters = append(r.targetReporters, rpc)
 	r.climateReporters = append(r.climateReporters, rpc)
@@ -261,24 +371,133 @@ func (r *zoneClimateRunner) setUpRPC(o ZoneClimateRunnerOptions) error {
 	return nil
 }
 
-func (r *zoneClimateRunner) fileName(name, suffix, ftype string) (string, error) {
-	return xdg.DataFile(filepath.Join("fort-experiments/climate", fmt.Sprintf("%s.%s.%s.txt", name, suffix, ftype)))
+// closeOutbox closes the outbox of the olympus reports, when they
+// will not be started.
+func (r *zoneClimateRunner) closeOutbox() {
+	if r.rpc == nil {
+		return
+	}
+	if err := r.rpc.outbox.Close(); err != nil {
+		r.logger.WithError(err).Error("could not close outbox")
+	}
+}
+
+// SetOlympusHost restarts the reports to olympus on host, if the zone
+// reports to olympus.
+func (r *zoneClimateRunner) SetOlympusHost(host string) {
+	if r.rpc == nil {
+		return
+	}
+	r.rpc.SetOlympusHost(host)
+}
+
+func (r *zoneClimateRunner) fileName(name, suffix, ftype, ext string) (string, error) {
+	return xdg.DataFile(filepath.Join("fort-experiments/climate", fmt.Sprintf("%s.%s.%s.%s", name, suffix, ftype, ext)))
+}
+
+func (r *zoneClimateRunner) setUpStore(o ZoneClimateRunnerOptions) error {
+	filename, err := xdg.DataFile(filepath.Join("fort-experiments/climate", o.Name+".db"))
+	if err != nil {
+		return err
+	}
+	r.store, err = OpenClimateStore(filename)
+	if err != nil {
+		return err
+	}
+	sr := NewStoreReporter(o.Name, r.store, o.Definition.Retention)
+	r.reporters = append(r.reporters, sr)
+	r.climateReporters = append(r.climateReporters, sr)
+	r.targetReporters = append(r.targetReporters, sr)
+	r.alarmReporters = append(r.alarmReporters, sr)
+	return nil
+}
+
+func (r *zoneClimateRunner) closeStore() {
+	r.storeMx.Lock()
+	defer r.storeMx.Unlock()
+	if r.store == nil {
+		return
+	}
+	if err := r.store.Close(); err != nil {
+		r.logger.WithError(err).Error("could not close store")
+	}
+	r.store = nil
+}
+
+func (r *zoneClimateRunner) newClimateLogReporter(o ZoneClimateRunnerOptions) (ClimateReporter, string, error) {
+	header := zeus.ClimateLogHeader{
+		Format:   o.Definition.ClimateLogFormat,
+		AuxCount: o.Definition.TemperatureAux,
+		AuxNames: o.Definition.TemperatureAuxNames,
+	}
+	if len(header.Format) == 0 {
+		header.Format = zeus.TextClimateLog
+	}
+	if header.Format != zeus.TextClimateLog {
+		hostname, err := os.Hostname()
+		if err != nil {
+			return nil, "", err
+		}
+		header.Zone = o.Name
+		header.Host = hostname
+		header.ZeusVersion = zeus.ZEUS_VERSION
+		header.SeasonFileHash = o.SeasonFileHash
+	}
+	cr, fname, err := openClimateLog(r.climateLog, header, o.Definition.LogRotation, o.Resume)
+	if err != nil {
+		return nil, "", err
+	}
+	return cr, fname, nil
 }
 
 func (r *zoneClimateRunner) setUpFileReporters(o ZoneClimateRunnerOptions) error {
-	cr, _, err := NewFileClimateReporter(r.climateLog, o.Definition.TemperatureAux)
+	if o.Definition.DisableTextLogs == true {
+		return nil
+	}
+	cr, climateLog, err := r.newClimateLogReporter(o)
 	if err != nil {
 		return err
 	}
 	r.reporters = append(r.reporters, cr)
 	r.climateReporters = append(r.climateReporters, cr)
+	r.targetReporters = append(r.targetReporters, cr.(TargetReporter))
 
-	ar, err := NewFileAlarmReporter(r.alarmLog)
+	ar, err := NewFileAlarmReporter(r.alarmLog, o.Definition.LogRotation)
 	if err != nil {
 		return err
 	}
 	r.reporters = append(r.reporters, ar)
 	r.alarmReporters = append(r.alarmReporters, ar)
+
+	err = pruneZoneLogs(filepath.Dir(r.alarmLog), o.Name, o.Definition.LogRotation.Retention, climateLog, r.alarmLog)
+	if err != nil {
+		r.logger.WithError(err).Warn("could not prune old logs")
+	}
+	return nil
+}
+
+func (r *zoneClimateRunner) setUpStreamReporters(o ZoneClimateRunnerOptions) error {
+	if o.Definition.MQTT != nil {
+		mr, err := NewMQTTReporter(o.Name, *o.Definition.MQTT)
+		if err != nil {
+			return err
+		}
+		r.reporters = append(r.reporters, mr)
+		r.climateReporters = append(r.climateReporters, mr)
+		r.targetReporters = append(r.targetReporters, mr)
+		r.alarmReporters = append(r.alarmReporters, mr)
+	}
+
+	if o.Definition.InfluxDB != nil {
+		ir, err := NewInfluxDBReporter(o.Name, *o.Definition.InfluxDB)
+		if err != nil {
+			return err
+		}
+		r.reporters = append(r.reporters, ir)
+		r.climateReporters = append(r.climateReporters, ir)
+		r.targetReporters = append(r.targetReporters, ir)
+		r.alarmReporters = append(r.alarmReporters, ir)
+	}
 	return nil
 }
 
@@ -302,9 +521,10 @@ func (r *zoneClimateRunner) getDevice(d DeviceDefinition) *Device {
 		return dev
 	}
 	dev = &Device{
-		intf:  r.dispatcher.Interface(),
-		Class: d.Class,
-		ID:    d.ID,
+		intf:      r.dispatcher.Interface(),
+		Class:     d.Class,
+		ID:        d.ID,
+		inventory: r.inventory,
 	}
 	r.devices[d.Class] = dev
 	return dev
@@ -328,7 +548,7 @@ func (r *zoneClimateRunner) setUpDevices(o ZoneClimateRunnerOptions) error {
 }
 
 func (r *zoneClimateRunner) setUpLastReporter(o ZoneClimateRunnerOptions) error {
-	r.last = NewLastStateReporter()
+	r.last = NewLastStateReporter(o.Name)
 	r.reporters = append(r.reporters, r.last)
 	r.targetReporters = append(r.targetReporters, r.last)
 	r.climateReporters = append(r.climateReporters, r.last)
@@ -336,60 +556,21 @@ func (r *zoneClimateRunner) setUpLastReporter(o ZoneClimateRunnerOptions) error
 }
 
 func (r *zoneClimateRunner) ClimateLog(start, end int) ([]zeus.ClimateReport, error) {
-	err := checkRange(start, end)
-	if err != nil {
-		return nil, err
-	}
-
-	if end > 0 && end <= len(r.climateLogData) {
-		res := make([]zeus.ClimateReport, end-start)
-		copy(res, r.climateLogData[start:end])
-
-		return res, nil
+	r.storeMx.RLock()
+	defer r.storeMx.RUnlock()
+	if r.store == nil {
+		return nil, fmt.Errorf("zone '%s' stopped", r.zone)
 	}
-
-	r.climateLogData, err = ReadClimateFile(r.climateLog)
-	if err != nil {
-		return nil, err
-	}
-	start, end, err = clampRange(start, end, len(r.climateLogData))
-	if err != nil {
-		return nil, err
-	}
-	res := make([]zeus.ClimateReport, end-start)
-	copy(res, r.climateLogData[start:end])
-	return res, nil
+	return r.store.ReportRange(r.since, start, end)
 }
 
 func (r *zoneClimateRunner) AlarmLog(start, end int) ([]zeus.AlarmEvent, error) {
-	err := checkRange(start, end)
-	if err != nil {
-		return nil, err
+	r.storeMx.RLock()
+	defer r.storeMx.RUnlock()
+	if r.store == nil {
+		return nil, fmt.Errorf("zone '%s' stopped", r.zone)
 	}
-
-	if end > 0 && end <= len(r.alarmLogData) {
-		res := make([]zeus.AlarmEvent, end-start)
-		copy(res, r.alarmLogData[start:end])
-		return res, nil
-	}
-
-	r.alarmLogData, err = ReadAlarmLogFile(r.alarmLog)
-	if err != nil {
-		return nil, err
-	}
-	for _, ae := range r.alarmLogData {
-		ae.ZoneIdentifier = ""
-	}
-
-	start, end, err = clampRange(start, end, len(r.alarmLogData))
-	if err != nil {
-		return nil, err
-	}
-
-	res := make([]zeus.AlarmEvent, end-start)
-	copy(res, r.alarmLogData[start:end])
-
-	return res, nil
+	return r.store.AlarmRange(r.since, start, end)
 }
 
 func (r *zoneClimateRunner) Last() *zeuspb.ZoneStatus {
@@ -397,20 +578,28 @@ func (r *zoneClimateRunner) Last() *zeuspb.ZoneStatus {
 }
 
 func NewZoneClimateRunner(o ZoneClimateRunnerOptions) (r ZoneClimateRunner, err error) {
+	if o.Inventory == nil {
+		o.Inventory = newDeviceInventory(nil)
+	}
 	res := &zoneClimateRunner{
+		zone:            o.Name,
+		since:           o.Since,
 		logger:          tm.NewLogger(path.Join("zone", o.Name)),
 		dispatcher:      o.Dispatcher,
 		messages:        o.Dispatcher.Register(arke.NodeID(o.Definition.DevicesID)),
-		presenceMonitor: NewPresenceMonitorer(o.Dispatcher.Name(), o.Dispatcher.Interface()),
+		links:           o.Dispatcher.RegisterLink(),
+		presenceMonitor: NewPresenceMonitorer(o.Dispatcher.Name(), o.Dispatcher.Interface(), o.Inventory),
 		devices:         make(map[arke.NodeClass]*Device),
+		inventory:       o.Inventory,
+		safeState:       o.Definition.SafeState,
 		callbacks:       make(map[arke.MessageClass][]callback),
 	}
 
-	res.climateLog, err = res.fileName(o.Name, o.FileSuffix, "climate")
+	res.climateLog, err = res.fileName(o.Name, o.FileSuffix, "climate", o.Definition.climateLogExtension())
 	if err != nil {
 		return nil, err
 	}
-	res.alarmLog, err = res.fileName(o.Name, o.FileSuffix, "alarms")
+	res.alarmLog, err = res.fileName(o.Name, o.FileSuffix, "alarms", "txt")
 	if err != nil {
 		return nil, err
 	}
@@ -418,8 +607,10 @@ func NewZoneClimateRunner(o ZoneClimateRunnerOptions) (r ZoneClimateRunner, err
 	setups := []func(ZoneClimateRunnerOptions) error{
 		func(o ZoneClimateRunnerOptions) error { return res.setUpInterpoler(o) },
 		func(o ZoneClimateRunnerOptions) error { return res.setUpAlarmMonitor(o) },
+		func(o ZoneClimateRunnerOptions) error { return res.setUpStore(o) },
 		func(o ZoneClimateRunnerOptions) error { return res.setUpRPC(o) },
 		func(o ZoneClimateRunnerOptions) error { return res.setUpFileReporters(o) },
+		func(o ZoneClimateRunnerOptions) error { return res.setUpStreamReporters(o) },
 		func(o ZoneClimateRunnerOptions) error { return res.setUpLastReporter(o) },
 		func(o ZoneClimateRunnerOptions) error { return res.setUpCapabilities(o) },
 		func(o ZoneClimateRunnerOptions) error { return res.setUpDevices(o) },
@@ -427,6 +618,8 @@ func NewZoneClimateRunner(o ZoneClimateRunnerOptions) (r ZoneClimateRunner, err
 
 	for _, s := range setups {
 		if err := s(o); err != nil {
+			res.closeStore()
+			res.closeOutbox()
 			return nil, err
 		}
 	}
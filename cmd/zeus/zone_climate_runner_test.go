@@ -0,0 +1,66 @@
+package main
+
+import (
+	"sync"
+	"time"
+
+	"github.com/formicidae-tracker/libarke/src-go/arke"
+	"github.com/formicidae-tracker/zeus/internal/zeus"
+	"github.com/sirupsen/logrus"
+	. "gopkg.in/check.v1"
+)
+
+type ZoneClimateRunnerSuite struct{}
+
+var _ = Suite(&ZoneClimateRunnerSuite{})
+
+// orderedCapability records the order of the calls to Idle and Close.
+type orderedCapability struct {
+	mx    sync.Mutex
+	calls []string
+}
+
+func (c *orderedCapability) Requirements() []arke.NodeClass            { return nil }
+func (c *orderedCapability) SetDevices(map[arke.NodeClass]*Device)     {}
+func (c *orderedCapability) Action(zeus.State) error                   { return nil }
+func (c *orderedCapability) Callbacks() map[arke.MessageClass]callback { return nil }
+
+func (c *orderedCapability) record(call string) {
+	c.mx.Lock()
+	defer c.mx.Unlock()
+	c.calls = append(c.calls, call)
+}
+
+func (c *orderedCapability) Idle(SafeStateDefinition) error {
+	// leaves time to a concurrent Close.
+	time.Sleep(20 * time.Millisecond)
+	c.record("idle")
+	return nil
+}
+
+func (c *orderedCapability) Close() error {
+	c.record("close")
+	return nil
+}
+
+func (s *ZoneClimateRunnerSuite) TestIdlesCapabilitiesBeforeClosingThem(c *C) {
+	interpoler, err := NewInterpoler("box", []zeus.State{{Name: "always-on"}}, nil, time.Now())
+	c.Assert(err, IsNil)
+	alarmMonitor, err := NewAlarmMonitor("box")
+	c.Assert(err, IsNil)
+	recorder := &orderedCapability{}
+	r := &zoneClimateRunner{
+		zone:            "box",
+		logger:          logrus.WithField("zone", "box"),
+		interpoler:      interpoler,
+		capabilities:    []capability{recorder},
+		presenceMonitor: NewPresenceMonitorer("stub", NewStubRawInterface(), nil),
+		alarmMonitor:    alarmMonitor,
+	}
+	go r.Run()
+	// lets the runner start before closing it.
+	time.Sleep(50 * time.Millisecond)
+	c.Assert(r.Close(), IsNil)
+
+	c.Check(recorder.calls, DeepEquals, []string{"idle", "close"})
+}
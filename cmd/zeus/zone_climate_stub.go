@@ -7,16 +7,21 @@ import (
 	"sync"
 	"time"
 
+	"github.com/formicidae-tracker/libarke/src-go/arke"
 	"github.com/formicidae-tracker/olympus/pkg/tm"
 	"github.com/formicidae-tracker/zeus/internal/zeus"
 	"github.com/formicidae-tracker/zeus/pkg/zeuspb"
 	"github.com/sirupsen/logrus"
 )
 
+// stubInterface is the CAN interface name reported in the alarms of
+// the simulated devices.
+const stubInterface = "simulated"
+
+// stubAlarm is raised while Active returns true.
 type stubAlarm struct {
 	Alarm  zeus.Alarm
-	Period time.Duration
-	Next   time.Time
+	Active func() bool
 	On     bool
 }
 
@@ -27,6 +32,9 @@ type zoneClimateStub struct {
 
 	interpoler    zeus.ClimateInterpoler
 	current, next zeus.Interpolation
+	plant         *PlantModel
+	lastStep      time.Time
+	scenario      *scenarioPlayer
 
 	stubAlarms []*stubAlarm
 	alarms     []zeus.AlarmEvent
@@ -45,6 +53,8 @@ type ZoneClimateStubArgs struct {
 	timeRatio      float64
 	olympusAddress string
 	rpcPort        int
+	plant          PlantParameters
+	scenario       *Scenario
 }
 
 func NewZoneClimateStub(args ZoneClimateStubArgs) (ZoneClimateRunner, error) {
@@ -52,6 +62,8 @@ func NewZoneClimateStub(args ZoneClimateStubArgs) (ZoneClimateRunner, error) {
 		host:      args.hostname,
 		zone:      args.zoneName,
 		timeRatio: args.timeRatio,
+		plant:     NewPlantModel(args.plant),
+		scenario:  newScenarioPlayer(args.scenario),
 		logger:    tm.NewLogger(path.Join("zone", args.zoneName, "climate-stub")),
 	}
 	var err error
@@ -93,18 +105,27 @@ func (s *zoneClimateStub) Run() {
 
 	s.stubAlarms = []*stubAlarm{
 		{
-			Alarm:  zeus.WaterLevelCritical,
-			Period: 20 * time.Minute,
-			Next:   now.Add(3 * time.Minute),
-			On:     false,
+			Alarm:  zeus.TemperatureUnreachable,
+			Active: s.plant.TemperatureUnreachable,
 		},
 		{
 			Alarm:  zeus.HumidityUnreachable,
-			Period: 30 * time.Minute,
-			Next:   now.Add(1 * time.Minute),
-			On:     false,
+			Active: s.plant.HumidityUnreachable,
+		},
+		{
+			Alarm: zeus.WaterLevelWarning,
+			Active: func() bool {
+				return s.plant.WaterLevelStatus() == arke.CelaenoWaterWarning
+			},
+		},
+		{
+			Alarm: zeus.WaterLevelCritical,
+			Active: func() bool {
+				return s.plant.WaterLevelStatus() == arke.CelaenoWaterCritical
+			},
 		},
 	}
+	s.stubAlarms = append(s.stubAlarms, s.faultAlarms()...)
 
 	s.step(now)
 
@@ -173,7 +194,110 @@ func (s *zoneClimateStub) Last() *zeuspb.ZoneStatus {
 	return &zeuspb.ZoneStatus{}
 }
 
+// faultAlarm returns the alarms the climate control raises while f
+// is active.
+func faultAlarms(f Fault) []zeus.Alarm {
+	switch f.Kind {
+	case DeviceMissing:
+		return []zeus.Alarm{zeus.NewMissingDeviceAlarm(stubInterface, f.Class(), 1)}
+	case FanStalled, FanAging:
+		if f.Class() == arke.CelaenoClass {
+			return []zeus.Alarm{zeus.NewFanAlarm("Celaeno Fan", faultyFan(f), zeus.Failure)}
+		}
+		return []zeus.Alarm{zeus.NewFanAlarm(zeusFanNames[f.Fan], faultyFan(f), zeus.Warning)}
+	case SensorNaN:
+		return []zeus.Alarm{zeus.SensorReadoutIssue}
+	case BusDown:
+		return []zeus.Alarm{
+			zeus.NewMissingDeviceAlarm(stubInterface, arke.ZeusClass, 1),
+			zeus.NewMissingDeviceAlarm(stubInterface, arke.CelaenoClass, 1),
+			zeus.NewMissingDeviceAlarm(stubInterface, arke.HeliosClass, 1),
+		}
+	default:
+		// water-empty is reported through the plant, and the set point
+		// is restored after a watchdog-reset.
+		return nil
+	}
+}
+
+// faultAlarms returns the alarms raised by the scenario faults of the
+// zone. Faults raising the same alarm are merged.
+func (s *zoneClimateStub) faultAlarms() []*stubAlarm {
+	var res []*stubAlarm
+	byIdentifier := make(map[string][]int)
+	for i, f := range s.scenario.faults {
+		if f.Targets(s.zone, f.Class()) == false {
+			continue
+		}
+		for _, a := range faultAlarms(f) {
+			indexes, ok := byIdentifier[a.Identifier()]
+			byIdentifier[a.Identifier()] = append(indexes, i)
+			if ok == true {
+				continue
+			}
+			identifier := a.Identifier()
+			res = append(res, &stubAlarm{
+				Alarm: a,
+				Active: func() bool {
+					for _, i := range byIdentifier[identifier] {
+						if s.scenario.active[i] == true {
+							return true
+						}
+					}
+					return false
+				},
+			})
+		}
+	}
+	return res
+}
+
+// controlLost returns true if an active fault prevents the Zeus of
+// the zone from regulating and reporting the climate.
+func (s *zoneClimateStub) controlLost() bool {
+	for _, f := range s.scenario.activeFaults() {
+		switch f.Kind {
+		case DeviceMissing:
+			if f.Targets(s.zone, arke.ZeusClass) == true && f.Class() == arke.ZeusClass {
+				return true
+			}
+		case SensorNaN, BusDown:
+			if f.Targets(s.zone, arke.ZeusClass) == true {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (s *zoneClimateStub) injectFaults(dt time.Duration) {
+	started, ended := s.scenario.advance(dt)
+	for _, f := range started {
+		if f.Targets(s.zone, arke.ZeusClass) == false {
+			continue
+		}
+		switch f.Kind {
+		case WaterEmpty:
+			s.plant.WaterLevel = 0
+		case WatchdogReset:
+			s.logger.WithField("device", f.Device).Info("simulated watchdog reset")
+		}
+	}
+	for _, f := range ended {
+		if f.Kind == WaterEmpty && f.Targets(s.zone, arke.ZeusClass) == true {
+			s.plant.Refill()
+		}
+	}
+}
+
 func (s *zoneClimateStub) step(now time.Time) {
+	var dt time.Duration
+	if s.lastStep.IsZero() == false {
+		dt = now.Sub(s.lastStep)
+	}
+	s.lastStep = now
+	s.injectFaults(dt)
+	s.plant.Step(dt)
 	s.simulateClimate(now)
 	s.simulateAlarms(now)
 }
@@ -205,7 +329,7 @@ func (s *zoneClimateStub) sendAlarm(ae zeus.AlarmEvent) {
 
 func (s *zoneClimateStub) simulateAlarms(now time.Time) {
 	for _, a := range s.stubAlarms {
-		if now.Before(a.Next) {
+		if a.Active() == a.On {
 			continue
 		}
 		ae := zeus.AlarmEvent{
@@ -215,8 +339,6 @@ func (s *zoneClimateStub) simulateAlarms(now time.Time) {
 			ZoneIdentifier: zeus.ZoneIdentifier(s.host, s.zone),
 			Time:           now,
 		}
-		a.Next = a.Next.Add(a.Period + time.Duration(rand.NormFloat64()*a.Period.Seconds())*time.Second)
-
 		if a.On == true {
 			a.On = false
 			ae.Status = zeus.AlarmOff
@@ -228,7 +350,14 @@ func (s *zoneClimateStub) simulateAlarms(now time.Time) {
 	}
 }
 
+// sendState regulates the plant toward state and reports its climate
+// with some sensor noise.
 func (s *zoneClimateStub) sendState(state zeus.State, now time.Time) {
+	if s.controlLost() == true {
+		s.plant.SetTarget(zeus.UndefinedTemperature, zeus.UndefinedHumidity)
+		return
+	}
+	s.plant.SetTarget(state.Temperature, state.Humidity)
 	noData := true
 	cr := zeus.ClimateReport{
 		Humidity:     zeus.UndefinedHumidity,
@@ -236,11 +365,11 @@ func (s *zoneClimateStub) sendState(state zeus.State, now time.Time) {
 		Time:         now,
 	}
 	if zeus.IsUndefined(state.Humidity) == false {
-		cr.Humidity = zeus.Humidity(state.Humidity.Value() + rand.NormFloat64()*1.0)
+		cr.Humidity = zeus.Humidity(s.plant.Humidity + rand.NormFloat64()*1.0)
 		noData = false
 	}
 	if zeus.IsUndefined(state.Temperature) == false {
-		cr.Temperatures[0] = zeus.Temperature(state.Temperature.Value() + rand.NormFloat64()*0.01)
+		cr.Temperatures[0] = zeus.Temperature(s.plant.Temperature + rand.NormFloat64()*0.01)
 		noData = false
 	}
 
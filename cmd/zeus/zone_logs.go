@@ -0,0 +1,116 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/formicidae-tracker/zeus/internal/zeus"
+	"github.com/formicidae-tracker/zeus/pkg/zeuspb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// defaultLogPageSize is the number of log entries per page when the
+// request does not set it.
+const defaultLogPageSize = 1000
+
+func (z *Zeus) zoneRunner(zone string) (ZoneClimateRunner, error) {
+	z.mx.RLock()
+	defer z.mx.RUnlock()
+	runner, ok := z.runners[zone]
+	if ok == false {
+		return nil, fmt.Errorf("zone '%s' is not running", zone)
+	}
+	return runner, nil
+}
+
+func logPageSize(request *zeuspb.LogRequest) int {
+	if request.PageSize == 0 {
+		return defaultLogPageSize
+	}
+	return int(request.PageSize)
+}
+
+func buildClimateLogEntry(r zeus.ClimateReport) *zeuspb.ClimateLogEntry {
+	res := &zeuspb.ClimateLogEntry{
+		Time:         timestamppb.New(r.Time),
+		Humidity:     float32(r.Humidity),
+		Temperatures: make([]float32, len(r.Temperatures)),
+	}
+	for i, t := range r.Temperatures {
+		res.Temperatures[i] = float32(t)
+	}
+	return res
+}
+
+func buildAlarmLogEvent(e zeus.AlarmEvent) *zeuspb.AlarmLogEvent {
+	return &zeuspb.AlarmLogEvent{
+		Time:        timestamppb.New(e.Time),
+		Identifier:  e.Identifier,
+		Description: e.Description,
+		Flags:       int32(e.Flags),
+		On:          e.Status == zeus.AlarmOn,
+	}
+}
+
+// GetClimateLog streams the climate reports of a running zone since
+// the start of the experiment, in pages.
+func (z *Zeus) GetClimateLog(request *zeuspb.LogRequest, stream zeuspb.Zeus_GetClimateLogServer) error {
+	var err error
+	_, span := z.tracer.Start(stream.Context(), "zeus/GetClimateLog")
+	defer func() { endWithError(span, err) }()
+
+	runner, err := z.zoneRunner(request.Zone)
+	if err != nil {
+		return err
+	}
+	reports, err := runner.ClimateLog(0, 0)
+	if err != nil {
+		return err
+	}
+	pageSize := logPageSize(request)
+	for i := 0; i < len(reports); i += pageSize {
+		end := i + pageSize
+		if end > len(reports) {
+			end = len(reports)
+		}
+		page := &zeuspb.ClimateLogPage{}
+		for _, r := range reports[i:end] {
+			page.Entries = append(page.Entries, buildClimateLogEntry(r))
+		}
+		if err = stream.Send(page); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetAlarmLog streams the alarm events of a running zone since the
+// start of the experiment, in pages.
+func (z *Zeus) GetAlarmLog(request *zeuspb.LogRequest, stream zeuspb.Zeus_GetAlarmLogServer) error {
+	var err error
+	_, span := z.tracer.Start(stream.Context(), "zeus/GetAlarmLog")
+	defer func() { endWithError(span, err) }()
+
+	runner, err := z.zoneRunner(request.Zone)
+	if err != nil {
+		return err
+	}
+	events, err := runner.AlarmLog(0, 0)
+	if err != nil {
+		return err
+	}
+	pageSize := logPageSize(request)
+	for i := 0; i < len(events); i += pageSize {
+		end := i + pageSize
+		if end > len(events) {
+			end = len(events)
+		}
+		page := &zeuspb.AlarmLogPage{}
+		for _, e := range events[i:end] {
+			page.Events = append(page.Events, buildAlarmLogEvent(e))
+		}
+		if err = stream.Send(page); err != nil {
+			return err
+		}
+	}
+	return nil
+}
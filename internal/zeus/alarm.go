@@ -16,6 +16,19 @@ const (
 	AdminOnly            = 0x04
 )
 
+// Level returns the most severe level of the flags: "failure",
+// "emergency" or "warning".
+func (f AlarmFlags) Level() string {
+	switch {
+	case f&Failure != 0:
+		return "failure"
+	case f&Emergency != 0:
+		return "emergency"
+	default:
+		return "warning"
+	}
+}
+
 type Alarm interface {
 	Flags() AlarmFlags
 	Identifier() string
@@ -135,6 +148,78 @@ func NewMissingDeviceAlarm(intf string, c arke.NodeClass, id arke.NodeID) Missin
 	return MissingDeviceAlarm{intf, c, id}
 }
 
+// LinkDownAlarm is raised while the CAN link to the devices of a
+// zone is down.
+type LinkDownAlarm struct {
+	canInterface string
+}
+
+func (a LinkDownAlarm) Flags() AlarmFlags {
+	return Emergency
+}
+
+func (a LinkDownAlarm) Identifier() string {
+	return "climate.link_down." + a.canInterface
+}
+
+func (a LinkDownAlarm) Description() string {
+	return fmt.Sprintf("CAN interface %s is down", a.canInterface)
+}
+
+func (a LinkDownAlarm) MinUpTime() time.Duration {
+	return HeartBeatPeriod
+}
+
+func (a LinkDownAlarm) MinDownTime() time.Duration {
+	return 5 * HeartBeatPeriod
+}
+
+func (a LinkDownAlarm) Interface() string {
+	return a.canInterface
+}
+
+func NewLinkDownAlarm(intf string) LinkDownAlarm {
+	return LinkDownAlarm{intf}
+}
+
+// OutdatedFirmwareAlarm is raised while a device runs a firmware older
+// than the configured minimum.
+type OutdatedFirmwareAlarm struct {
+	canInterface     string
+	class            arke.NodeClass
+	id               arke.NodeID
+	version, minimum string
+}
+
+func (a OutdatedFirmwareAlarm) Flags() AlarmFlags {
+	return Warning | AdminOnly
+}
+
+func (a OutdatedFirmwareAlarm) Identifier() string {
+	return fmt.Sprintf("climate.firmware_outdated.%s.%s.%d", a.canInterface, arke.ClassName(a.class), a.id)
+}
+
+func (a OutdatedFirmwareAlarm) Description() string {
+	return fmt.Sprintf("Device %s.%s.%d firmware %s is older than %s",
+		a.canInterface, arke.ClassName(a.class), a.id, a.version, a.minimum)
+}
+
+func (a OutdatedFirmwareAlarm) MinUpTime() time.Duration {
+	return 10 * HeartBeatPeriod
+}
+
+func (a OutdatedFirmwareAlarm) MinDownTime() time.Duration {
+	return 5 * HeartBeatPeriod
+}
+
+func (a OutdatedFirmwareAlarm) Device() (string, arke.NodeClass, arke.NodeID) {
+	return a.canInterface, a.class, a.id
+}
+
+func NewOutdatedFirmwareAlarm(intf string, c arke.NodeClass, id arke.NodeID, version, minimum string) OutdatedFirmwareAlarm {
+	return OutdatedFirmwareAlarm{intf, c, id, version, minimum}
+}
+
 type FanAlarm struct {
 	fan    string
 	status arke.FanStatus
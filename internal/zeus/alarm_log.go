@@ -0,0 +1,31 @@
+package zeus
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+)
+
+// ReadAlarmLogFile reads an alarm log file and all its rotated
+// segments.
+func ReadAlarmLogFile(filename string) ([]AlarmEvent, error) {
+	var res []AlarmEvent
+	err := ReadLogSegments(filename, func(reader *bufio.Reader) error {
+		for {
+			l, err := reader.ReadString('\n')
+			if err == io.EOF {
+				return nil
+			}
+			if err != nil {
+				return err
+			}
+			event := AlarmEvent{}
+			err = json.Unmarshal([]byte(l), &event)
+			if err != nil {
+				return err
+			}
+			res = append(res, event)
+		}
+	})
+	return res, err
+}
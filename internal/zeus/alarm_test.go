@@ -19,6 +19,8 @@ func (s *AlarmSuite) TestRepeatPeriod(c *C) {
 		{AlarmString{}, 0},
 		{NewFanAlarm("foo", arke.FanAging, Warning), 1 * time.Minute},
 		{NewMissingDeviceAlarm("foo", arke.ZeusClass, 1), 5 * HeartBeatPeriod},
+		{NewLinkDownAlarm("foo"), 5 * HeartBeatPeriod},
+		{NewOutdatedFirmwareAlarm("foo", arke.ZeusClass, 1, "1.0.0", "1.1.0"), 5 * HeartBeatPeriod},
 		{NewDeviceInternalError("foo", arke.ZeusClass, 1, 43), 2 * time.Second},
 	}
 
@@ -113,6 +115,18 @@ func (s *AlarmSuite) TestData(c *C) {
 			ExpectedDescription: "Device vcan0.Celaeno.1 is missing",
 			ExpectedFlags:       Warning | AdminOnly,
 		},
+		{
+			Alarm:               NewLinkDownAlarm("slcan0"),
+			ExpectedIdentifier:  "climate.link_down.slcan0",
+			ExpectedDescription: "CAN interface slcan0 is down",
+			ExpectedFlags:       Emergency,
+		},
+		{
+			Alarm:               NewOutdatedFirmwareAlarm("slcan0", arke.CelaenoClass, 2, "1.0.3", "1.1.0"),
+			ExpectedIdentifier:  "climate.firmware_outdated.slcan0.Celaeno.2",
+			ExpectedDescription: "Device slcan0.Celaeno.2 firmware 1.0.3 is older than 1.1.0",
+			ExpectedFlags:       Warning | AdminOnly,
+		},
 		{
 			Alarm:               NewDeviceInternalError("vcan0", arke.ZeusClass, 1, 0x42),
 			ExpectedIdentifier:  "climate.device_error.vcan0.Zeus.1.66",
@@ -16,11 +16,18 @@ type Interpolation interface {
 type staticClimate State
 
 func (s *staticClimate) State(time.Time) State {
-	return State(*s)
+	return State(*s).resolveHumidity()
 }
 
 func (s *staticClimate) String() string {
-	return fmt.Sprintf("static state: %+v", State(*s))
+	derived := ""
+	if s.VPD != nil {
+		derived = fmt.Sprintf(" VPD:%v", *s.VPD)
+	} else if s.DewPoint != nil {
+		derived = fmt.Sprintf(" DewPoint:%v", *s.DewPoint)
+	}
+	return fmt.Sprintf("static state: {Name:%s Temperature:%v Humidity:%v Wind:%v VisibleLight:%v UVLight:%v%s}",
+		s.Name, s.Temperature, s.Humidity, s.Wind, s.VisibleLight, s.UVLight, derived)
 }
 
 func (s *staticClimate) End() *State {
@@ -45,16 +52,70 @@ func interpolate(from, to, completion float64) float64 {
 	return from + (to-from)*completion
 }
 
+// interpolatePointer interpolates optional values, nil being
+// undefined.
+func interpolatePointer(from, to *float64, completion float64) *float64 {
+	if from == nil && to == nil {
+		return nil
+	}
+	undefined := math.Inf(-1)
+	if from == nil {
+		from = &undefined
+	}
+	if to == nil {
+		to = &undefined
+	}
+	res := interpolate(*from, *to, completion)
+	return &res
+}
+
+// humidityKind returns how s sets its humidity target, or an empty
+// string if it does not.
+func humidityKind(s State) string {
+	switch {
+	case s.VPD != nil:
+		return "vpd"
+	case s.DewPoint != nil:
+		return "dew-point"
+	case IsUndefined(s.Humidity) == false:
+		return "humidity"
+	}
+	return ""
+}
+
+// interpolateHumidity sets the humidity target of res, whose
+// temperature target is interpolated. Vapor pressure deficits and dew
+// points are interpolated, and converted at the temperature target.
+func interpolateHumidity(res *State, from, to State, completion float64) {
+	fromKind, toKind := humidityKind(from), humidityKind(to)
+	if fromKind != toKind && len(fromKind) > 0 && len(toKind) > 0 {
+		// targets of different kinds are compared at the temperature.
+		res.Humidity = Humidity(interpolate(from.HumidityAt(res.Temperature).Value(), to.HumidityAt(res.Temperature).Value(), completion))
+		return
+	}
+	res.Humidity = Humidity(interpolate(from.Humidity.Value(), to.Humidity.Value(), completion))
+	res.VPD = interpolatePointer(from.VPD, to.VPD, completion)
+	var fromDewPoint, toDewPoint *float64
+	if from.DewPoint != nil {
+		fromDewPoint = (*float64)(from.DewPoint)
+	}
+	if to.DewPoint != nil {
+		toDewPoint = (*float64)(to.DewPoint)
+	}
+	res.DewPoint = (*Temperature)(interpolatePointer(fromDewPoint, toDewPoint, completion))
+	*res = res.resolveHumidity()
+}
+
 func interpolateState(from, to State, completion float64) State {
-	return State{
+	res := State{
 		Name:         fmt.Sprintf("%s to %s", from.Name, to.Name),
 		Temperature:  Temperature(interpolate(from.Temperature.Value(), to.Temperature.Value(), completion)),
-		Humidity:     Humidity(interpolate(from.Humidity.Value(), to.Humidity.Value(), completion)),
 		Wind:         Wind(interpolate(from.Wind.Value(), to.Wind.Value(), completion)),
 		VisibleLight: Light(interpolate(from.VisibleLight.Value(), to.VisibleLight.Value(), completion)),
 		UVLight:      Light(interpolate(from.UVLight.Value(), to.UVLight.Value(), completion)),
 	}
-
+	interpolateHumidity(&res, from, to, completion)
+	return res
 }
 
 func (i *climateTransition) State(t time.Time) State {
@@ -73,7 +134,7 @@ func (i *climateTransition) String() string {
 }
 
 func (t *climateTransition) End() *State {
-	state := t.to
+	state := t.to.resolveHumidity()
 	return &state
 }
 
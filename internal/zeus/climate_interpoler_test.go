@@ -1,6 +1,7 @@
 package zeus
 
 import (
+	"math"
 	reflect "reflect"
 	"time"
 
@@ -99,6 +100,53 @@ func (s *ClimateInterpolerSuite) TestInterpolation(c *C) {
 
 }
 
+func (s *ClimateInterpolerSuite) TestInterpolatesVPDAtTemperature(c *C) {
+	vpd := 1.0
+	i := climateTransition{
+		from: State{
+			Name:        "a",
+			Temperature: 20,
+			Humidity:    UndefinedHumidity,
+			VPD:         &vpd,
+		},
+		to: State{
+			Name:        "b",
+			Temperature: 30,
+			Humidity:    UndefinedHumidity,
+			VPD:         &vpd,
+		},
+		duration: 30 * time.Minute,
+	}
+	i.start = time.Now()
+
+	for _, d := range []time.Duration{0, 15 * time.Minute, 30 * time.Minute} {
+		res := i.State(i.start.Add(d))
+		expected, err := HumidityFromVaporPressureDeficit(res.Temperature, vpd)
+		c.Assert(err, IsNil)
+		if c.Check(res.VPD, Not(IsNil)) == true {
+			c.Check(*res.VPD, Equals, vpd)
+		}
+		c.Check(res.Humidity, Equals, expected, Commentf("at %s", d))
+	}
+	c.Check(i.End().Humidity, Equals, i.State(i.start.Add(30*time.Minute)).Humidity)
+
+	// a relative humidity is interpolated to the vpd at the
+	// temperature target.
+	i.from.VPD = nil
+	i.from.Humidity = 40
+	c.Check(i.State(i.start).Humidity, Equals, Humidity(40))
+	expected, err := HumidityFromVaporPressureDeficit(25, vpd)
+	c.Assert(err, IsNil)
+	res := i.State(i.start.Add(15 * time.Minute))
+	c.Check(math.Abs(res.Humidity.Value()-(40+expected.Value())/2) < 1e-6, Equals, true, Commentf("%s", res.Humidity))
+
+	// without a temperature target, the capability converts it.
+	static := staticClimate{Name: "c", Temperature: UndefinedTemperature, Humidity: UndefinedHumidity, VPD: &vpd}
+	res = static.State(time.Now())
+	c.Check(IsUndefined(res.Humidity), Equals, true)
+	c.Check(res.HasHumidityTarget(), Equals, true)
+}
+
 func (s *ClimateInterpolerSuite) TestClimateInterpoler(c *C) {
 
 	definedDay := State{
@@ -0,0 +1,557 @@
+package zeus
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	TextClimateLog  = "text"
+	CSVClimateLog   = "csv"
+	JSONLClimateLog = "jsonl"
+)
+
+// climateLogVersionLine starts climate log files carrying the current
+// target. Files without it only have time, humidity and temperatures.
+const climateLogVersionLine = "# Zeus climate log v2"
+
+// ClimateLogEntry is a line of a climate log file. Target is nil for
+// files that do not record it.
+type ClimateLogEntry struct {
+	ClimateReport
+	Target *State
+}
+
+// ClimateLogEncoder formats the header and lines of a climate log
+// file. Target is the state targeted when the report was made.
+type ClimateLogEncoder interface {
+	Header() string
+	Encode(w io.Writer, r ClimateReport, target State) error
+}
+
+// textDewPointColumn starts the derived columns of text climate logs.
+// Older v2 files do not have them.
+const textDewPointColumn = "Dew Point (°C)"
+
+func undefinedIfNaN(v float64) float64 {
+	if math.IsNaN(v) {
+		return math.Inf(-1)
+	}
+	return v
+}
+
+type textClimateEncoder struct {
+	start  time.Time
+	numAux int
+	format string
+}
+
+// NewTextClimateLogEncoder returns an encoder for the space separated
+// text format, with times relative to start.
+func NewTextClimateLogEncoder(start time.Time, numAux int) ClimateLogEncoder {
+	return &textClimateEncoder{
+		start:  start,
+		numAux: numAux,
+		format: "%d %.2f %.2f" + strings.Repeat(" %.2f", numAux) + " %.2f %.2f %.2f %.2f %.2f %.2f %.2f %.3f %s\n",
+	}
+}
+
+func (e *textClimateEncoder) Header() string {
+	header := "# Time (ms) Relative Humidity (%) Temperature (°C)"
+	for i := 0; i < e.numAux; i++ {
+		header += fmt.Sprintf(" Aux %d (°C)", i+1)
+	}
+	header += " Target Temperature (°C) Target Humidity (%) Target Wind (%) Target Visible Light (%) Target UV Light (%)"
+	header += " " + textDewPointColumn + " Absolute Humidity (g/m³) VPD (kPa) Target State"
+	return fmt.Sprintf("%s\n# Starting date %s\n%s\n", climateLogVersionLine, e.start.Format(time.RFC3339Nano), header)
+}
+
+func (e *textClimateEncoder) Encode(w io.Writer, cr ClimateReport, target State) error {
+	asInterface := make([]interface{}, 0, e.numAux+9)
+	asInterface = append(asInterface, cr.Time.Sub(e.start).Nanoseconds()/1e6, cr.Humidity)
+	for _, t := range cr.Temperatures {
+		asInterface = append(asInterface, t)
+	}
+	asInterface = append(asInterface,
+		target.Temperature,
+		target.Humidity,
+		target.Wind,
+		target.VisibleLight,
+		target.UVLight,
+		cr.DewPoint(),
+		undefinedIfNaN(cr.AbsoluteHumidity()),
+		undefinedIfNaN(cr.VaporPressureDeficit()),
+		strconv.Quote(target.Name))
+	_, err := fmt.Fprintf(w, e.format, asInterface...)
+	return err
+}
+
+// ClimateLogHeader describes the content of a CSV or JSON Lines
+// climate log. It is the first line of each file, as a JSON object
+// prefixed with '# ' in CSV files.
+type ClimateLogHeader struct {
+	Format         string    `json:"format"`
+	Zone           string    `json:"zone"`
+	Host           string    `json:"host"`
+	ZeusVersion    string    `json:"zeus_version"`
+	Start          time.Time `json:"start"`
+	AuxCount       int       `json:"aux_count"`
+	AuxNames       []string  `json:"aux_names"`
+	SeasonFileHash string    `json:"season_file_hash,omitempty"`
+}
+
+var csvClimateColumns = []string{"target_temperature", "target_humidity", "target_wind", "target_visible_light", "target_uv_light", "target_state"}
+
+// csvDerivedColumns are quantities derived from the humidity and
+// temperature. Older files do not have them.
+var csvDerivedColumns = []string{"dew_point", "absolute_humidity", "vpd"}
+
+type csvClimateEncoder struct {
+	meta ClimateLogHeader
+}
+
+func formatCSVValue(u BoundedUnit) string {
+	return formatCSVFloat(u.Value())
+}
+
+func formatCSVFloat(v float64) string {
+	if math.IsInf(v, 0) || math.IsNaN(v) {
+		return ""
+	}
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
+
+func (e *csvClimateEncoder) Header() string {
+	data, _ := json.Marshal(e.meta)
+	columns := []string{"time", "humidity", "temperature"}
+	for i := 0; i < e.meta.AuxCount; i++ {
+		columns = append(columns, fmt.Sprintf("aux_%d", i+1))
+	}
+	columns = append(columns, csvClimateColumns...)
+	columns = append(columns, csvDerivedColumns...)
+	return fmt.Sprintf("# %s\n%s\n", data, strings.Join(columns, ","))
+}
+
+func (e *csvClimateEncoder) Encode(w io.Writer, cr ClimateReport, target State) error {
+	record := []string{cr.Time.Format(time.RFC3339Nano), formatCSVValue(cr.Humidity)}
+	for _, t := range cr.Temperatures {
+		record = append(record, formatCSVValue(t))
+	}
+	record = append(record,
+		formatCSVValue(target.Temperature),
+		formatCSVValue(target.Humidity),
+		formatCSVValue(target.Wind),
+		formatCSVValue(target.VisibleLight),
+		formatCSVValue(target.UVLight),
+		target.Name,
+		formatCSVValue(cr.DewPoint()),
+		formatCSVFloat(cr.AbsoluteHumidity()),
+		formatCSVFloat(cr.VaporPressureDeficit()))
+	cw := csv.NewWriter(w)
+	cw.Write(record)
+	cw.Flush()
+	return cw.Error()
+}
+
+// jsonlClimateState is a State where undefined values are null.
+type jsonlClimateState struct {
+	Name         string   `json:"name"`
+	Temperature  *float64 `json:"temperature"`
+	Humidity     *float64 `json:"humidity"`
+	Wind         *float64 `json:"wind"`
+	VisibleLight *float64 `json:"visible_light"`
+	UVLight      *float64 `json:"uv_light"`
+}
+
+type jsonlClimateEntry struct {
+	Time             time.Time          `json:"time"`
+	Humidity         *float64           `json:"humidity"`
+	Temperatures     []*float64         `json:"temperatures"`
+	Target           *jsonlClimateState `json:"target"`
+	DewPoint         *float64           `json:"dew_point,omitempty"`
+	AbsoluteHumidity *float64           `json:"absolute_humidity,omitempty"`
+	VPD              *float64           `json:"vpd,omitempty"`
+}
+
+type jsonlClimateEncoder struct {
+	meta ClimateLogHeader
+}
+
+func optionalValue(u BoundedUnit) *float64 {
+	return optionalFloat(u.Value())
+}
+
+func optionalFloat(v float64) *float64 {
+	if math.IsInf(v, 0) || math.IsNaN(v) {
+		return nil
+	}
+	return &v
+}
+
+func fromOptionalValue(v *float64) float64 {
+	if v == nil {
+		return math.Inf(-1)
+	}
+	return *v
+}
+
+func (e *jsonlClimateEncoder) Header() string {
+	data, _ := json.Marshal(e.meta)
+	return string(data) + "\n"
+}
+
+func (e *jsonlClimateEncoder) Encode(w io.Writer, cr ClimateReport, target State) error {
+	entry := jsonlClimateEntry{
+		Time:         cr.Time,
+		Humidity:     optionalValue(cr.Humidity),
+		Temperatures: make([]*float64, len(cr.Temperatures)),
+		Target: &jsonlClimateState{
+			Name:         target.Name,
+			Temperature:  optionalValue(target.Temperature),
+			Humidity:     optionalValue(target.Humidity),
+			Wind:         optionalValue(target.Wind),
+			VisibleLight: optionalValue(target.VisibleLight),
+			UVLight:      optionalValue(target.UVLight),
+		},
+		DewPoint:         optionalValue(cr.DewPoint()),
+		AbsoluteHumidity: optionalFloat(cr.AbsoluteHumidity()),
+		VPD:              optionalFloat(cr.VaporPressureDeficit()),
+	}
+	for i, t := range cr.Temperatures {
+		entry.Temperatures[i] = optionalValue(t)
+	}
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "%s\n", data)
+	return err
+}
+
+// NewCSVClimateLogEncoder returns an encoder for CSV files with
+// absolute times, described by header. Its format is set to
+// CSVClimateLog.
+func NewCSVClimateLogEncoder(header ClimateLogHeader) ClimateLogEncoder {
+	header.Format = CSVClimateLog
+	return &csvClimateEncoder{meta: header}
+}
+
+// NewJSONLClimateLogEncoder returns an encoder for JSON Lines files
+// described by header. Its format is set to JSONLClimateLog.
+func NewJSONLClimateLogEncoder(header ClimateLogHeader) ClimateLogEncoder {
+	header.Format = JSONLClimateLog
+	return &jsonlClimateEncoder{meta: header}
+}
+
+func parseCSVValue(v string) (float64, error) {
+	if len(v) == 0 {
+		return math.Inf(-1), nil
+	}
+	return strconv.ParseFloat(v, 64)
+}
+
+func readCSVClimateLog(r *bufio.Reader, header ClimateLogHeader) ([]ClimateLogEntry, error) {
+	cr := csv.NewReader(r)
+	// derived columns are optional.
+	cr.FieldsPerRecord = -1
+	minFields := header.AuxCount + 9
+	if _, err := cr.Read(); err != nil {
+		return nil, fmt.Errorf("invalid column header: %w", err)
+	}
+	var res []ClimateLogEntry
+	for {
+		record, err := cr.Read()
+		if err == io.EOF {
+			return res, nil
+		}
+		if err != nil {
+			return res, err
+		}
+		if len(record) != minFields && len(record) != minFields+len(csvDerivedColumns) {
+			return res, fmt.Errorf("invalid record at %s: got %d fields, expected %d", record[0], len(record), minFields)
+		}
+		entry := ClimateLogEntry{}
+		entry.Time, err = time.Parse(time.RFC3339Nano, record[0])
+		if err != nil {
+			return res, fmt.Errorf("invalid time '%s': %w", record[0], err)
+		}
+		values := make([]float64, header.AuxCount+7)
+		for i, v := range record[1 : header.AuxCount+8] {
+			values[i], err = parseCSVValue(v)
+			if err != nil {
+				return res, fmt.Errorf("invalid value '%s': %w", v, err)
+			}
+		}
+		entry.Humidity = Humidity(values[0])
+		entry.Temperatures = make([]Temperature, header.AuxCount+1)
+		for i := range entry.Temperatures {
+			entry.Temperatures[i] = Temperature(values[i+1])
+		}
+		target := values[header.AuxCount+2:]
+		entry.Target = &State{
+			Name:         record[header.AuxCount+8],
+			Temperature:  Temperature(target[0]),
+			Humidity:     Humidity(target[1]),
+			Wind:         Wind(target[2]),
+			VisibleLight: Light(target[3]),
+			UVLight:      Light(target[4]),
+		}
+		res = append(res, entry)
+	}
+}
+
+func readJSONLClimateLog(r *bufio.Reader, header ClimateLogHeader) ([]ClimateLogEntry, error) {
+	dec := json.NewDecoder(r)
+	var res []ClimateLogEntry
+	for {
+		e := jsonlClimateEntry{}
+		err := dec.Decode(&e)
+		if err == io.EOF {
+			return res, nil
+		}
+		if err != nil {
+			return res, err
+		}
+		if len(e.Temperatures) != header.AuxCount+1 {
+			return res, fmt.Errorf("invalid entry at %s: got %d temperatures, expected %d", e.Time.Format(time.RFC3339Nano), len(e.Temperatures), header.AuxCount+1)
+		}
+		entry := ClimateLogEntry{
+			ClimateReport: ClimateReport{
+				Time:         e.Time,
+				Humidity:     Humidity(fromOptionalValue(e.Humidity)),
+				Temperatures: make([]Temperature, len(e.Temperatures)),
+			},
+		}
+		for i, t := range e.Temperatures {
+			entry.Temperatures[i] = Temperature(fromOptionalValue(t))
+		}
+		if e.Target != nil {
+			entry.Target = &State{
+				Name:         e.Target.Name,
+				Temperature:  Temperature(fromOptionalValue(e.Target.Temperature)),
+				Humidity:     Humidity(fromOptionalValue(e.Target.Humidity)),
+				Wind:         Wind(fromOptionalValue(e.Target.Wind)),
+				VisibleLight: Light(fromOptionalValue(e.Target.VisibleLight)),
+				UVLight:      Light(fromOptionalValue(e.Target.UVLight)),
+			}
+		}
+		res = append(res, entry)
+	}
+}
+
+// readClimateLogHeader reads the header of a climate log in any
+// format. For text logs, it also returns the version of the format.
+func readClimateLogHeader(r *bufio.Reader) (ClimateLogHeader, int, error) {
+	first, _ := r.Peek(3)
+	isCSV := strings.HasPrefix(string(first), "# {")
+	if isCSV == false && strings.HasPrefix(string(first), "{") == false {
+		start, version, err := readStartDate(r)
+		if err != nil {
+			return ClimateLogHeader{}, 0, err
+		}
+		numAux, err := readNumAux(r, version)
+		return ClimateLogHeader{Format: TextClimateLog, Start: start, AuxCount: numAux}, version, err
+	}
+	line, err := r.ReadString('\n')
+	if err != nil {
+		return ClimateLogHeader{}, 0, err
+	}
+	header := ClimateLogHeader{}
+	if err := json.Unmarshal([]byte(strings.TrimPrefix(line, "# ")), &header); err != nil {
+		return header, 0, fmt.Errorf("invalid climate log header: %w", err)
+	}
+	if header.AuxCount < 0 {
+		return header, 0, fmt.Errorf("invalid climate log header: negative aux count")
+	}
+	header.Format = JSONLClimateLog
+	if isCSV == true {
+		header.Format = CSVClimateLog
+	}
+	return header, 0, nil
+}
+
+// ReadClimateLogHeader reads the header of a climate log file. Text
+// logs only have a format, start and aux count.
+func ReadClimateLogHeader(filename string) (ClimateLogHeader, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return ClimateLogHeader{}, err
+	}
+	defer f.Close()
+	header, _, err := readClimateLogHeader(bufio.NewReader(f))
+	return header, err
+}
+
+// readStartDate reads the start date and returns the version of the
+// file.
+func readStartDate(r *bufio.Reader) (time.Time, int, error) {
+	l, err := r.ReadString('\n')
+	if err != nil {
+		return time.Time{}, 0, err
+	}
+	version := 1
+	if strings.TrimSpace(l) == climateLogVersionLine {
+		version = 2
+		l, err = r.ReadString('\n')
+		if err != nil {
+			return time.Time{}, 0, err
+		}
+	}
+
+	l = strings.TrimPrefix(l, "# Starting date")
+	l = strings.TrimSpace(l)
+	t, err := time.Parse(time.RFC3339Nano, l)
+	return t, version, err
+}
+
+func readNumAux(r *bufio.Reader, version int) (int, error) {
+	l, err := r.ReadString('\n')
+	if err != nil {
+		return 0, err
+	}
+	// version 2 also has a target temperature, and may have a dew
+	// point.
+	res := strings.Count(l, "(°C)") - version
+	if strings.Contains(l, textDewPointColumn) == true {
+		res -= 1
+	}
+	if res < 0 {
+		return 0, fmt.Errorf("invalid header '%s'", strings.TrimSpace(l))
+	}
+	return res, nil
+}
+
+func parseFloats(values []string, name string) ([]float64, error) {
+	res := make([]float64, len(values))
+	for i, v := range values {
+		f, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s '%s': %s", name, v, err)
+		}
+		res[i] = f
+	}
+	return res, nil
+}
+
+func readClimateReport(r *bufio.Reader, start time.Time, numAux int, version int) (ClimateLogEntry, error) {
+	res := ClimateLogEntry{}
+	l, err := r.ReadString('\n')
+	if err != nil {
+		return res, err
+	}
+	l = strings.TrimSpace(l)
+	targetName := ""
+	if version >= 2 {
+		idx := strings.Index(l, `"`)
+		if idx < 0 {
+			return res, fmt.Errorf("invalid line '%s': missing target state", l)
+		}
+		targetName, err = strconv.Unquote(l[idx:])
+		if err != nil {
+			return res, fmt.Errorf("invalid target state '%s': %s", l[idx:], err)
+		}
+		l = strings.TrimSpace(l[:idx])
+	}
+
+	valuesStr := strings.Split(l, " ")
+	expected := 3 + numAux
+	if version >= 2 {
+		expected += 5
+	}
+	// the derived values that may follow are computed from the
+	// report instead.
+	if len(valuesStr) < expected {
+		return res, fmt.Errorf("invalid line '%s': too few values", l)
+	}
+	ms, err := strconv.ParseInt(valuesStr[0], 10, 64)
+	if err != nil {
+		return res, fmt.Errorf("invalid timestamp '%s': %s", valuesStr[0], err)
+	}
+	res.Time = start.Add(time.Duration(ms) * time.Millisecond)
+	h, err := strconv.ParseFloat(valuesStr[1], 64)
+	if err != nil {
+		return res, fmt.Errorf("invalid humidity '%s': %s", valuesStr[1], err)
+	}
+	res.Humidity = Humidity(h)
+	temperatures, err := parseFloats(valuesStr[2:(numAux+3)], "temperature")
+	if err != nil {
+		return res, err
+	}
+	res.Temperatures = make([]Temperature, numAux+1)
+	for i, t := range temperatures {
+		res.Temperatures[i] = Temperature(t)
+	}
+	if version < 2 {
+		return res, nil
+	}
+	target, err := parseFloats(valuesStr[(numAux+3):(numAux+8)], "target")
+	if err != nil {
+		return res, err
+	}
+	res.Target = &State{
+		Name:         targetName,
+		Temperature:  Temperature(target[0]),
+		Humidity:     Humidity(target[1]),
+		Wind:         Wind(target[2]),
+		VisibleLight: Light(target[3]),
+		UVLight:      Light(target[4]),
+	}
+	return res, nil
+}
+
+// ReadClimateLog reads a climate log file in any format and all its
+// rotated segments, with the target that was active for each report
+// if the file records it.
+func ReadClimateLog(filename string) ([]ClimateLogEntry, error) {
+	var res []ClimateLogEntry
+	err := ReadLogSegments(filename, func(reader *bufio.Reader) error {
+		header, version, err := readClimateLogHeader(reader)
+		if err != nil {
+			return err
+		}
+		switch header.Format {
+		case CSVClimateLog:
+			entries, err := readCSVClimateLog(reader, header)
+			res = append(res, entries...)
+			return err
+		case JSONLClimateLog:
+			entries, err := readJSONLClimateLog(reader, header)
+			res = append(res, entries...)
+			return err
+		}
+
+		for {
+			cr, err := readClimateReport(reader, header.Start, header.AuxCount, version)
+			if err == io.EOF {
+				return nil
+			}
+			if err != nil {
+				return err
+			}
+			res = append(res, cr)
+		}
+	})
+	return res, err
+}
+
+// ReadClimateFile reads the climate reports of a climate log file and
+// all its rotated segments.
+func ReadClimateFile(filename string) ([]ClimateReport, error) {
+	entries, err := ReadClimateLog(filename)
+	if entries == nil {
+		return nil, err
+	}
+	res := make([]ClimateReport, len(entries))
+	for i, e := range entries {
+		res[i] = e.ClimateReport
+	}
+	return res, err
+}
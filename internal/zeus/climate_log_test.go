@@ -0,0 +1,191 @@
+package zeus
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	. "gopkg.in/check.v1"
+)
+
+type ClimateLogSuite struct {
+	TmpDir string
+}
+
+var _ = Suite(&ClimateLogSuite{})
+
+func (s *ClimateLogSuite) SetUpTest(c *C) {
+	var err error
+	s.TmpDir, err = ioutil.TempDir("", "zeus-climate-log")
+	c.Assert(err, IsNil)
+}
+
+func (s *ClimateLogSuite) TearDownTest(c *C) {
+	c.Check(os.RemoveAll(s.TmpDir), IsNil)
+}
+
+func (s *ClimateLogSuite) TestReadsTargetsWithSpaces(c *C) {
+	filename := filepath.Join(s.TmpDir, "v2.txt")
+	start := time.Now().Round(0)
+	content := "# Zeus climate log v2\n# Starting date " + start.Format(time.RFC3339Nano) + `
+# Time (ms) Relative Humidity (%) Temperature (°C) Target Temperature (°C) Target Humidity (%) Target Wind (%) Target Visible Light (%) Target UV Light (%) Target State
+0 50.00 21.00 22.00 60.00 -Inf 0.00 -Inf "late night"
+`
+	c.Assert(ioutil.WriteFile(filename, []byte(content), 0644), IsNil)
+	entries, err := ReadClimateLog(filename)
+	c.Assert(err, IsNil)
+	c.Assert(len(entries), Equals, 1)
+	c.Check(entries[0].ClimateReport, DeepEquals, ClimateReport{Time: start, Humidity: 50, Temperatures: []Temperature{21}})
+	c.Assert(entries[0].Target, Not(IsNil))
+	c.Check(entries[0].Target.Name, Equals, "late night")
+	c.Check(entries[0].Target.Temperature, Equals, Temperature(22))
+	c.Check(entries[0].Target.VisibleLight, Equals, Light(0))
+	c.Check(IsUndefined(entries[0].Target.Wind), Equals, true)
+
+	reports, err := ReadClimateFile(filename)
+	c.Assert(err, IsNil)
+	c.Check(reports, DeepEquals, []ClimateReport{entries[0].ClimateReport})
+}
+
+func (s *ClimateLogSuite) TestTextFormatDerivedColumns(c *C) {
+	filename := filepath.Join(s.TmpDir, "derived.txt")
+	start := time.Now().Round(0)
+	encoder := NewTextClimateLogEncoder(start, 1)
+	buffer := bytes.NewBufferString(encoder.Header())
+	target := State{Name: "day", Temperature: 26, Humidity: 60, Wind: 100, VisibleLight: 50, UVLight: UndefinedLight}
+	report := ClimateReport{Time: start.Add(time.Second), Humidity: 50, Temperatures: []Temperature{25, 21}}
+	c.Assert(encoder.Encode(buffer, report, target), IsNil)
+	c.Assert(encoder.Encode(buffer, ClimateReport{Time: start.Add(2 * time.Second), Humidity: UndefinedHumidity, Temperatures: []Temperature{25, 21}}, target), IsNil)
+	lines := strings.Split(buffer.String(), "\n")
+	c.Check(lines[2], Matches, `.* Target UV Light \(%\) Dew Point \(°C\) Absolute Humidity \(g/m³\) VPD \(kPa\) Target State`)
+	c.Check(lines[3], Equals, `1000 50.00 25.00 21.00 26.00 60.00 100.00 50.00 -Inf 13.86 11.49 1.581 "day"`)
+	c.Check(lines[4], Equals, `2000 -Inf 25.00 21.00 26.00 60.00 100.00 50.00 -Inf -Inf -Inf -Inf "day"`)
+
+	c.Assert(ioutil.WriteFile(filename, buffer.Bytes(), 0644), IsNil)
+	entries, err := ReadClimateLog(filename)
+	c.Assert(err, IsNil)
+	c.Assert(entries, HasLen, 2)
+	c.Check(entries[0].Temperatures, DeepEquals, []Temperature{25, 21})
+	c.Check(entries[0].Target.Name, Equals, "day")
+	c.Check(entries[0].Target.VisibleLight, Equals, Light(50))
+}
+
+func (s *ClimateLogSuite) TestTextFormatReading(c *C) {
+	tmpdir, err := ioutil.TempDir("", "zeus-read-season-file")
+	c.Assert(err, IsNil)
+	defer os.RemoveAll(tmpdir)
+	start := time.Now().Round(0) // removes monotonic clocks value for deep equals
+	startString := start.Format(time.RFC3339Nano)
+	testdata := []struct {
+		Content  string
+		Expected []ClimateReport
+		Error    string
+	}{
+		{
+			Content: "# Starting date " + startString + `
+# Time (ms) Relative Humidity (%) Temperature (°C)
+0 50.0 21.23
+502 51.3 24.5
+`,
+			Expected: []ClimateReport{
+				ClimateReport{Time: start, Humidity: 50.0, Temperatures: []Temperature{21.23}},
+				ClimateReport{Time: start.Add(502 * time.Millisecond), Humidity: 51.3, Temperatures: []Temperature{24.5}},
+			},
+		},
+		{
+			Content: "# Starting date " + startString + `
+# Time (ms) Relative Humidity (%) Temperature (°C) Aux 1 (°C)
+0 50.0 21.23 13.2
+502 51.3 24.5 15.7
+`,
+			Expected: []ClimateReport{
+				ClimateReport{Time: start, Humidity: 50.0, Temperatures: []Temperature{21.23, 13.2}},
+				ClimateReport{Time: start.Add(502 * time.Millisecond), Humidity: 51.3, Temperatures: []Temperature{24.5, 15.7}},
+			},
+		},
+		{
+			Content: "# Starting date " + startString + `fo
+# Time (ms) Relative Humidity (%) Temperature (°C) Aux 1 (°C)
+0 50.0 21.23 13.2
+502 51.3 24.5 15.7
+`,
+			Error: "parsing time \".*\": extra text: .*",
+		},
+		{
+			Content: "# Starting date " + startString + `
+# Time (ms) Relative Humidity (%)
+0 50.0 21.23 13.2
+502 51.3 24.5 15.7
+`,
+			Error: "invalid header .*",
+		},
+		{
+			Content: "# Starting date " + startString + `
+# Time (ms) Relative Humidity (%) Temperature (°C) Aux 1 (°C) Aux 2 (°C)
+0 50.0 21.23 13.2 34.1
+502 51.3 24.5 15.7
+`,
+			Error: "invalid line .*: too few values",
+			Expected: []ClimateReport{
+				ClimateReport{Time: start, Humidity: 50.0, Temperatures: []Temperature{21.23, 13.2, 34.1}},
+			},
+		},
+	}
+	filename := filepath.Join(tmpdir, "log.txt")
+	for _, d := range testdata {
+		err := ioutil.WriteFile(filename, []byte(d.Content), 0644)
+		if c.Check(err, IsNil) == false {
+			continue
+		}
+		result, err := ReadClimateFile(filename)
+		if len(d.Error) > 0 {
+			c.Check(err, ErrorMatches, d.Error)
+		} else {
+			c.Check(err, IsNil)
+		}
+		c.Check(result, DeepEquals, d.Expected)
+
+	}
+}
+
+func (s *ClimateLogSuite) TestStructuredFormatErrors(c *C) {
+	testdata := []struct {
+		Content string
+		Error   string
+	}{
+		{
+			Content: `# {"format":"csv","aux_count":0}
+time,humidity,temperature,target_temperature,target_humidity,target_wind,target_visible_light,target_uv_light,target_state
+2023-03-01T12:00:00Z,50,21,,,,,
+`,
+			Error: "invalid record at 2023-03-01T12:00:00Z: got 8 fields, expected 9",
+		},
+		{
+			Content: `# {"format":"csv","aux_count":0}
+time,humidity,temperature,target_temperature,target_humidity,target_wind,target_visible_light,target_uv_light,target_state
+yesterday,50,21,,,,,,
+`,
+			Error: "invalid time 'yesterday': .*",
+		},
+		{
+			Content: `{"format":"jsonl","aux_count":1}
+{"time":"2023-03-01T12:00:00Z","humidity":50,"temperatures":[21]}
+`,
+			Error: "invalid entry at 2023-03-01T12:00:00Z: got 1 temperatures, expected 2",
+		},
+		{
+			Content: `{"format":"jsonl","aux_count":1
+`,
+			Error: "invalid climate log header: .*",
+		},
+	}
+	filename := filepath.Join(s.TmpDir, "log.txt")
+	for _, d := range testdata {
+		c.Assert(ioutil.WriteFile(filename, []byte(d.Content), 0644), IsNil)
+		_, err := ReadClimateLog(filename)
+		c.Check(err, ErrorMatches, d.Error)
+	}
+}
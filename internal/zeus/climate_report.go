@@ -29,6 +29,45 @@ func (r ClimateReport) Check() error {
 	return nil
 }
 
+// mainTemperature returns the temperature of the main sensor, or
+// false if the report has no valid temperature and humidity.
+func (r ClimateReport) mainTemperature() (Temperature, bool) {
+	if len(r.Temperatures) == 0 || r.Check() != nil || r.Humidity.Value() <= 0 {
+		return UndefinedTemperature, false
+	}
+	return r.Temperatures[0], true
+}
+
+// DewPoint returns the dew point of the main sensor, or an undefined
+// temperature.
+func (r ClimateReport) DewPoint() Temperature {
+	t, ok := r.mainTemperature()
+	if ok == false {
+		return UndefinedTemperature
+	}
+	return DewPoint(t, r.Humidity)
+}
+
+// AbsoluteHumidity returns the absolute humidity of the main sensor
+// in g/m³, or NaN.
+func (r ClimateReport) AbsoluteHumidity() float64 {
+	t, ok := r.mainTemperature()
+	if ok == false {
+		return math.NaN()
+	}
+	return AbsoluteHumidity(t, r.Humidity)
+}
+
+// VaporPressureDeficit returns the vapor pressure deficit of the main
+// sensor in kPa, or NaN.
+func (r ClimateReport) VaporPressureDeficit() float64 {
+	t, ok := r.mainTemperature()
+	if ok == false {
+		return math.NaN()
+	}
+	return VaporPressureDeficit(t, r.Humidity)
+}
+
 type NamedClimateReport struct {
 	ClimateReport
 	ZoneIdentifier string
@@ -0,0 +1,94 @@
+package zeus
+
+import (
+	"bufio"
+	"compress/gzip"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// LogSegment is a rotated segment of a log file <name>, named
+// <name>.<Index>.gz, or <name>.<Index> if it was not compressed.
+type LogSegment struct {
+	Index      int
+	Filename   string
+	Compressed bool
+}
+
+// ListLogSegments returns the rotated segments of a log file, oldest
+// first. If a segment was not compressed because of an interruption,
+// its uncompressed version is returned.
+func ListLogSegments(filename string) ([]LogSegment, error) {
+	matches, err := filepath.Glob(GlobEscape(filename) + ".*")
+	if err != nil {
+		return nil, err
+	}
+	segments := map[int]LogSegment{}
+	for _, m := range matches {
+		suffix := strings.TrimPrefix(m, filename+".")
+		compressed := strings.HasSuffix(suffix, ".gz")
+		idx, err := strconv.Atoi(strings.TrimSuffix(suffix, ".gz"))
+		if err != nil || idx <= 0 {
+			continue
+		}
+		if s, ok := segments[idx]; ok == true && s.Compressed == true {
+			continue
+		}
+		segments[idx] = LogSegment{Index: idx, Filename: m, Compressed: compressed}
+	}
+	res := make([]LogSegment, 0, len(segments))
+	for _, s := range segments {
+		res = append(res, s)
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i].Index < res[j].Index })
+	return res, nil
+}
+
+// GlobEscape escapes the meta characters of p for filepath.Glob.
+func GlobEscape(p string) string {
+	return strings.NewReplacer(`*`, `\*`, `?`, `\?`, `[`, `\[`, `\`, `\\`).Replace(p)
+}
+
+// ReadLogSegments calls fn on the content of each rotated segment of
+// filename, then on filename itself.
+func ReadLogSegments(filename string, fn func(r *bufio.Reader) error) error {
+	segments, err := ListLogSegments(filename)
+	if err != nil {
+		return err
+	}
+	for _, s := range segments {
+		if err := readLogSegment(s, fn); err != nil {
+			return err
+		}
+	}
+	f, err := os.Open(filename)
+	if os.IsNotExist(err) && len(segments) > 0 {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return fn(bufio.NewReader(f))
+}
+
+func readLogSegment(s LogSegment, fn func(r *bufio.Reader) error) error {
+	f, err := os.Open(s.Filename)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	if s.Compressed == false {
+		return fn(bufio.NewReader(f))
+	}
+	r, err := gzip.NewReader(f)
+	if err != nil {
+		return fmt.Errorf("could not read '%s': %w", s.Filename, err)
+	}
+	defer r.Close()
+	return fn(bufio.NewReader(r))
+}
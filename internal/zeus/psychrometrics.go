@@ -0,0 +1,66 @@
+package zeus
+
+import (
+	"fmt"
+	"math"
+)
+
+// Magnus formula coefficients over water (Alduchov & Eskridge, 1996).
+const (
+	magnusA = 0.61094 // kPa
+	magnusB = 17.625
+	magnusC = 243.04 // °C
+
+	// specific gas constant of water vapor, in J/(kg.K)
+	waterVaporGasConstant = 461.5
+)
+
+// SaturationVaporPressure returns the saturation vapor pressure of
+// water at t, in kPa.
+func SaturationVaporPressure(t Temperature) float64 {
+	return magnusA * math.Exp(magnusB*t.Value()/(magnusC+t.Value()))
+}
+
+// VaporPressure returns the partial pressure of water vapor at t and
+// h, in kPa.
+func VaporPressure(t Temperature, h Humidity) float64 {
+	return h.Value() / 100.0 * SaturationVaporPressure(t)
+}
+
+// DewPoint returns the temperature at which the air at t and h is
+// saturated.
+func DewPoint(t Temperature, h Humidity) Temperature {
+	gamma := math.Log(h.Value()/100.0) + magnusB*t.Value()/(magnusC+t.Value())
+	return Temperature(magnusC * gamma / (magnusB - gamma))
+}
+
+// AbsoluteHumidity returns the mass of water vapor in the air at t and
+// h, in g/m³.
+func AbsoluteHumidity(t Temperature, h Humidity) float64 {
+	return VaporPressure(t, h) * 1e6 / (waterVaporGasConstant * (t.Value() + 273.15))
+}
+
+// VaporPressureDeficit returns the difference between the saturation
+// and actual vapor pressures at t and h, in kPa.
+func VaporPressureDeficit(t Temperature, h Humidity) float64 {
+	return SaturationVaporPressure(t) - VaporPressure(t, h)
+}
+
+// HumidityFromDewPoint returns the relative humidity at t of air with
+// the dew point dp.
+func HumidityFromDewPoint(t, dp Temperature) (Humidity, error) {
+	if dp.Value() > t.Value()+1e-9 {
+		return UndefinedHumidity, fmt.Errorf("dew point %.2f°C is above temperature %.2f°C", dp.Value(), t.Value())
+	}
+	return Humidity(math.Min(100.0, 100.0*SaturationVaporPressure(dp)/SaturationVaporPressure(t))), nil
+}
+
+// HumidityFromVaporPressureDeficit returns the relative humidity at t
+// of air with a vapor pressure deficit of vpd kPa.
+func HumidityFromVaporPressureDeficit(t Temperature, vpd float64) (Humidity, error) {
+	saturation := SaturationVaporPressure(t)
+	if vpd < 0 || vpd > saturation {
+		return UndefinedHumidity, fmt.Errorf("vapor pressure deficit %.3f kPa is not in [0,%.3f] at %.2f°C", vpd, saturation, t.Value())
+	}
+	return Humidity(100.0 * (1.0 - vpd/saturation)), nil
+}
@@ -0,0 +1,60 @@
+package zeus
+
+import (
+	"math"
+
+	. "gopkg.in/check.v1"
+)
+
+type PsychrometricsSuite struct{}
+
+var _ = Suite(&PsychrometricsSuite{})
+
+func (s *PsychrometricsSuite) TestDerivedQuantities(c *C) {
+	testdata := []struct {
+		T, H                    float64
+		DewPoint, Absolute, VPD float64
+	}{
+		{T: 25, H: 50, DewPoint: 13.86, Absolute: 11.49, VPD: 1.581},
+		{T: 20, H: 80, DewPoint: 16.44, Absolute: 13.80, VPD: 0.467},
+		{T: 30, H: 100, DewPoint: 30.00, Absolute: 30.28, VPD: 0.0},
+	}
+	for _, d := range testdata {
+		t, h := Temperature(d.T), Humidity(d.H)
+		comment := Commentf("%.0f°C %.0f%%", d.T, d.H)
+		c.Check(math.Abs(DewPoint(t, h).Value()-d.DewPoint) < 0.01, Equals, true, comment)
+		c.Check(math.Abs(AbsoluteHumidity(t, h)-d.Absolute) < 0.01, Equals, true, comment)
+		c.Check(math.Abs(VaporPressureDeficit(t, h)-d.VPD) < 0.001, Equals, true, comment)
+
+		fromDewPoint, err := HumidityFromDewPoint(t, DewPoint(t, h))
+		c.Check(err, IsNil)
+		c.Check(math.Abs(fromDewPoint.Value()-d.H) < 1e-6, Equals, true, comment)
+		fromVPD, err := HumidityFromVaporPressureDeficit(t, VaporPressureDeficit(t, h))
+		c.Check(err, IsNil)
+		c.Check(math.Abs(fromVPD.Value()-d.H) < 1e-6, Equals, true, comment)
+	}
+
+	_, err := HumidityFromDewPoint(20, 21)
+	c.Check(err, ErrorMatches, "dew point 21.00°C is above temperature 20.00°C")
+	_, err = HumidityFromVaporPressureDeficit(20, 3)
+	c.Check(err, ErrorMatches, `vapor pressure deficit 3.000 kPa is not in \[0,2.333\] at 20.00°C`)
+	_, err = HumidityFromVaporPressureDeficit(20, -0.1)
+	c.Check(err, ErrorMatches, `vapor pressure deficit -0.100 kPa is not in .*`)
+}
+
+func (s *PsychrometricsSuite) TestClimateReportDerivedQuantities(c *C) {
+	report := ClimateReport{Humidity: 50, Temperatures: []Temperature{25, 40}}
+	c.Check(math.Abs(report.DewPoint().Value()-13.86) < 0.01, Equals, true)
+	c.Check(math.Abs(report.AbsoluteHumidity()-11.49) < 0.01, Equals, true)
+	c.Check(math.Abs(report.VaporPressureDeficit()-1.581) < 0.001, Equals, true)
+
+	for _, r := range []ClimateReport{
+		{Humidity: 50},
+		{Humidity: UndefinedHumidity, Temperatures: []Temperature{25}},
+		{Humidity: Humidity(math.NaN()), Temperatures: []Temperature{25}},
+	} {
+		c.Check(IsUndefined(r.DewPoint()), Equals, true)
+		c.Check(math.IsNaN(r.AbsoluteHumidity()), Equals, true)
+		c.Check(math.IsNaN(r.VaporPressureDeficit()), Equals, true)
+	}
+}
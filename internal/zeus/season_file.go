@@ -1,6 +1,8 @@
 package zeus
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"io"
@@ -12,6 +14,9 @@ import (
 
 type SeasonFile struct {
 	Zones map[string]ZoneClimate
+
+	// content is the file the season was parsed from, if any.
+	content []byte
 }
 
 type deprecatedLine struct {
@@ -102,6 +107,7 @@ func ParseSeasonFile(content []byte) (*SeasonFile, error) {
 	if err != nil {
 		return nil, err
 	}
+	s.content = append([]byte(nil), content...)
 
 	return s, nil
 }
@@ -132,10 +138,34 @@ func ReadSeasonFile(filename string, writer io.Writer) (*SeasonFile, error) {
 	return s, nil
 }
 
+// bytes returns the file the season was parsed from, or its YAML
+// representation.
+func (f SeasonFile) bytes() ([]byte, error) {
+	if f.content != nil {
+		return f.content, nil
+	}
+	return yaml.Marshal(f)
+}
+
+// WriteFile writes the file the season was parsed from unchanged, or
+// its YAML representation.
 func (f SeasonFile) WriteFile(filename string) error {
-	data, err := yaml.Marshal(f)
+	data, err := f.bytes()
 	if err != nil {
 		return err
 	}
 	return ioutil.WriteFile(filename, data, 0644)
 }
+
+// FileHash returns the hexadecimal SHA-256 of the file the season was
+// parsed from, so it matches the hash of the file given by the
+// user. It hashes the YAML representation of the season if it was not
+// parsed from a file.
+func (f SeasonFile) FileHash() (string, error) {
+	data, err := f.bytes()
+	if err != nil {
+		return "", err
+	}
+	h := sha256.Sum256(data)
+	return hex.EncodeToString(h[:]), nil
+}
@@ -2,6 +2,8 @@ package zeus
 
 import (
 	"bytes"
+	"crypto/sha256"
+	"fmt"
 	"io/ioutil"
 	"log"
 	"os"
@@ -124,6 +126,37 @@ func (s *SeasonFileSuite) TestWritingShouldBeReadable(c *C) {
 	}()
 
 	result, err := ReadSeasonFile(filename, bytes.NewBuffer(nil))
-	c.Check(err, IsNil)
-	c.Check(*result, DeepEquals, season)
+	c.Assert(err, IsNil)
+	c.Check(result.Zones, DeepEquals, season.Zones)
+}
+
+func (s *SeasonFileSuite) TestFileHashIsTheHashOfTheFile(c *C) {
+	content := []byte(`# a comment, lost when the season is marshalled
+zones:
+  box:
+    states:
+      - name: day
+        temperature: 26
+`)
+	season, err := ParseSeasonFile(content)
+	c.Assert(err, IsNil)
+	hash, err := season.FileHash()
+	c.Assert(err, IsNil)
+	// sha256sum of the file
+	c.Check(hash, Equals, fmt.Sprintf("%x", sha256.Sum256(content)))
+
+	tmpdir, err := ioutil.TempDir("", "zeus-tests-season")
+	c.Assert(err, IsNil)
+	defer os.RemoveAll(tmpdir)
+	filename := filepath.Join(tmpdir, "test.season")
+	c.Assert(season.WriteFile(filename), IsNil)
+	written, err := ioutil.ReadFile(filename)
+	c.Assert(err, IsNil)
+	c.Check(string(written), Equals, string(content))
+
+	season, err = ReadSeasonFile(filename, nil)
+	c.Assert(err, IsNil)
+	resumed, err := season.FileHash()
+	c.Assert(err, IsNil)
+	c.Check(resumed, Equals, hash)
 }
@@ -1,6 +1,10 @@
 package zeus
 
-import "github.com/formicidae-tracker/zeus/pkg/zeuspb"
+import (
+	"fmt"
+
+	"github.com/formicidae-tracker/zeus/pkg/zeuspb"
+)
 
 type State struct {
 	Name         string
@@ -9,6 +13,22 @@ type State struct {
 	Wind         Wind
 	VisibleLight Light
 	UVLight      Light
+	// VPD, in kPa, or DewPoint set the humidity target instead of
+	// Humidity. They are converted to a relative humidity at the
+	// current temperature target by the interpolations.
+	VPD      *float64
+	DewPoint *Temperature
+}
+
+// UndefinedState returns a State where all values are undefined.
+func UndefinedState() State {
+	return State{
+		Temperature:  UndefinedTemperature,
+		Humidity:     UndefinedHumidity,
+		Wind:         UndefinedWind,
+		VisibleLight: UndefinedLight,
+		UVLight:      UndefinedLight,
+	}
 }
 
 func (s *State) UnmarshalYAML(unmarshal func(interface{}) error) error {
@@ -19,6 +39,10 @@ func (s *State) UnmarshalYAML(unmarshal func(interface{}) error) error {
 		Wind         float64 `yaml:"wind"`
 		VisibleLight float64 `yaml:"visible-light"`
 		UVLight      float64 `yaml:"uv-light"`
+		// humidity targets given as a vapor pressure deficit in kPa
+		// or a dew point in °C.
+		VPD      *float64 `yaml:"vpd"`
+		DewPoint *float64 `yaml:"dew-point"`
 	}
 
 	res := stateYAML{}
@@ -34,12 +58,86 @@ func (s *State) UnmarshalYAML(unmarshal func(interface{}) error) error {
 	s.Name = res.Name
 	s.Temperature = Temperature(res.Temperature)
 	s.Humidity = Humidity(res.Humidity)
+	if res.VPD != nil || res.DewPoint != nil {
+		if err := s.setHumidityFrom(res.VPD, res.DewPoint); err != nil {
+			return err
+		}
+	}
 	s.Wind = Wind(res.Wind)
 	s.VisibleLight = Light(res.VisibleLight)
 	s.UVLight = Light(res.UVLight)
 	return nil
 }
 
+// setHumidityFrom sets the humidity target as a vapor pressure
+// deficit or a dew point. They are checked against the temperature
+// target, if any.
+func (s *State) setHumidityFrom(vpd, dewPoint *float64) error {
+	if IsUndefined(s.Humidity) == false || (vpd != nil && dewPoint != nil) {
+		return fmt.Errorf("state '%s': only one of humidity, vpd or dew-point can be set", s.Name)
+	}
+	if vpd != nil {
+		if *vpd < 0 {
+			return fmt.Errorf("state '%s': negative vapor pressure deficit %.3f kPa", s.Name, *vpd)
+		}
+		s.VPD = new(float64)
+		*s.VPD = *vpd
+	} else {
+		s.DewPoint = new(Temperature)
+		*s.DewPoint = Temperature(*dewPoint)
+	}
+	if IsUndefined(s.Temperature) == true {
+		return nil
+	}
+	var err error
+	if vpd != nil {
+		_, err = HumidityFromVaporPressureDeficit(s.Temperature, *vpd)
+	} else {
+		_, err = HumidityFromDewPoint(s.Temperature, Temperature(*dewPoint))
+	}
+	if err != nil {
+		return fmt.Errorf("state '%s': %w", s.Name, err)
+	}
+	return nil
+}
+
+// HasHumidityTarget returns true if s sets a relative humidity, a
+// vapor pressure deficit or a dew point.
+func (s State) HasHumidityTarget() bool {
+	return IsUndefined(s.Humidity) == false || s.VPD != nil || s.DewPoint != nil
+}
+
+// HumidityAt returns the relative humidity target of s at t. A vapor
+// pressure deficit or dew point out of reach at t gives the closest
+// humidity, and an undefined t an undefined humidity.
+func (s State) HumidityAt(t Temperature) Humidity {
+	if s.VPD == nil && s.DewPoint == nil {
+		return s.Humidity
+	}
+	if IsUndefined(t) == true {
+		return UndefinedHumidity
+	}
+	if s.VPD != nil {
+		h, err := HumidityFromVaporPressureDeficit(t, *s.VPD)
+		if err != nil {
+			return 0
+		}
+		return h
+	}
+	h, err := HumidityFromDewPoint(t, *s.DewPoint)
+	if err != nil {
+		return 100
+	}
+	return h
+}
+
+// resolveHumidity returns s with the relative humidity of its vapor
+// pressure deficit or dew point at its temperature target.
+func (s State) resolveHumidity() State {
+	s.Humidity = s.HumidityAt(s.Temperature)
+	return s
+}
+
 func (s State) MarshalYAML() (interface{}, error) {
 	type saveState struct {
 		Name   string
@@ -52,7 +150,11 @@ func (s State) MarshalYAML() (interface{}, error) {
 	if !IsUndefined(s.Temperature) {
 		res.Values["temperature"] = float64(s.Temperature)
 	}
-	if !IsUndefined(s.Humidity) {
+	if s.VPD != nil {
+		res.Values["vpd"] = *s.VPD
+	} else if s.DewPoint != nil {
+		res.Values["dew-point"] = s.DewPoint.Value()
+	} else if !IsUndefined(s.Humidity) {
 		res.Values["humidity"] = float64(s.Humidity)
 	}
 	if !IsUndefined(s.Wind) {
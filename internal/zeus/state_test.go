@@ -1,6 +1,7 @@
 package zeus
 
 import (
+	"math"
 	"regexp"
 	"testing"
 
@@ -71,3 +72,44 @@ func (s *StateSuite) TestParsing(c *C) {
 	c.Check(rx.MatchString(err.Error()), Equals, true)
 
 }
+
+func (s *StateSuite) TestParsesHumidityFromVPDOrDewPoint(c *C) {
+	testdata := []struct {
+		Text     string
+		Humidity float64
+	}{
+		{"temperature: 25.0\nvpd: 1.581", 50},
+		{"temperature: 25.0\ndew-point: 13.86", 50},
+		{"temperature: 20.0\nvpd: 0", 100},
+	}
+	for _, d := range testdata {
+		res := State{}
+		if c.Check(yaml.Unmarshal([]byte(d.Text), &res), IsNil) == false {
+			continue
+		}
+		c.Check(IsUndefined(res.Humidity), Equals, true, Commentf("%s", d.Text))
+		c.Check(res.HasHumidityTarget(), Equals, true, Commentf("%s", d.Text))
+		h := res.HumidityAt(res.Temperature)
+		c.Check(math.Abs(h.Value()-d.Humidity) < 0.05, Equals, true, Commentf("%s: %f", d.Text, h))
+	}
+
+	res := State{}
+	if c.Check(yaml.Unmarshal([]byte("vpd: 1.0"), &res), IsNil) == true {
+		c.Check(res.VPD, Not(IsNil))
+		c.Check(IsUndefined(res.HumidityAt(res.Temperature)), Equals, true)
+	}
+
+	errors := []struct {
+		Text  string
+		Error string
+	}{
+		{"name: a\nvpd: -1.0", "state 'a': negative vapor pressure deficit -1.000 kPa"},
+		{"name: a\ntemperature: 25.0\nhumidity: 50\nvpd: 1.0", "state 'a': only one of humidity, vpd or dew-point can be set"},
+		{"name: a\ntemperature: 25.0\nvpd: 1.0\ndew-point: 12", "state 'a': only one of humidity, vpd or dew-point can be set"},
+		{"name: a\ntemperature: 20.0\ndew-point: 22", "state 'a': dew point 22.00°C is above temperature 20.00°C"},
+	}
+	for _, d := range errors {
+		res := State{}
+		c.Check(yaml.Unmarshal([]byte(d.Text), &res), ErrorMatches, d.Error)
+	}
+}
@@ -26,6 +26,16 @@ func AsFloat32Pointer(u BoundedUnit) *float32 {
 	return res
 }
 
+// Float32Pointer returns nil for NaN or infinite values.
+func Float32Pointer(v float64) *float32 {
+	if math.IsNaN(v) || math.IsInf(v, 0) {
+		return nil
+	}
+	res := new(float32)
+	*res = float32(v)
+	return res
+}
+
 type Temperature float64
 
 func (t Temperature) Value() float64    { return float64(t) }
@@ -1,15 +1,15 @@
 // Code generated by protoc-gen-go. DO NOT EDIT.
 // versions:
 // 	protoc-gen-go v1.28.1
-// 	protoc        v3.12.4
+// 	protoc        (unknown)
 // source: zeus_service.proto
 
 package zeuspb
 
 import (
-	timestamp "github.com/golang/protobuf/ptypes/timestamp"
 	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
 	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
 	reflect "reflect"
 	sync "sync"
 )
@@ -206,10 +206,13 @@ type ZoneStatus struct {
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	Name        string   `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
-	Temperature *float32 `protobuf:"fixed32,2,opt,name=temperature,proto3,oneof" json:"temperature,omitempty"`
-	Humidity    *float32 `protobuf:"fixed32,3,opt,name=humidity,proto3,oneof" json:"humidity,omitempty"`
-	Target      *Target  `protobuf:"bytes,4,opt,name=target,proto3" json:"target,omitempty"`
+	Name                 string   `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Temperature          *float32 `protobuf:"fixed32,2,opt,name=temperature,proto3,oneof" json:"temperature,omitempty"`
+	Humidity             *float32 `protobuf:"fixed32,3,opt,name=humidity,proto3,oneof" json:"humidity,omitempty"`
+	Target               *Target  `protobuf:"bytes,4,opt,name=target,proto3" json:"target,omitempty"`
+	DewPoint             *float32 `protobuf:"fixed32,5,opt,name=dew_point,json=dewPoint,proto3,oneof" json:"dew_point,omitempty"`
+	AbsoluteHumidity     *float32 `protobuf:"fixed32,6,opt,name=absolute_humidity,json=absoluteHumidity,proto3,oneof" json:"absolute_humidity,omitempty"`
+	VaporPressureDeficit *float32 `protobuf:"fixed32,7,opt,name=vapor_pressure_deficit,json=vaporPressureDeficit,proto3,oneof" json:"vapor_pressure_deficit,omitempty"`
 }
 
 func (x *ZoneStatus) Reset() {
@@ -272,15 +275,36 @@ func (x *ZoneStatus) GetTarget() *Target {
 	return nil
 }
 
+func (x *ZoneStatus) GetDewPoint() float32 {
+	if x != nil && x.DewPoint != nil {
+		return *x.DewPoint
+	}
+	return 0
+}
+
+func (x *ZoneStatus) GetAbsoluteHumidity() float32 {
+	if x != nil && x.AbsoluteHumidity != nil {
+		return *x.AbsoluteHumidity
+	}
+	return 0
+}
+
+func (x *ZoneStatus) GetVaporPressureDeficit() float32 {
+	if x != nil && x.VaporPressureDeficit != nil {
+		return *x.VaporPressureDeficit
+	}
+	return 0
+}
+
 type Status struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	Running bool                 `protobuf:"varint,1,opt,name=running,proto3" json:"running,omitempty"`
-	Since   *timestamp.Timestamp `protobuf:"bytes,2,opt,name=since,proto3" json:"since,omitempty"`
-	Version string               `protobuf:"bytes,3,opt,name=version,proto3" json:"version,omitempty"`
-	Zones   []*ZoneStatus        `protobuf:"bytes,4,rep,name=zones,proto3" json:"zones,omitempty"`
+	Running bool                   `protobuf:"varint,1,opt,name=running,proto3" json:"running,omitempty"`
+	Since   *timestamppb.Timestamp `protobuf:"bytes,2,opt,name=since,proto3" json:"since,omitempty"`
+	Version string                 `protobuf:"bytes,3,opt,name=version,proto3" json:"version,omitempty"`
+	Zones   []*ZoneStatus          `protobuf:"bytes,4,rep,name=zones,proto3" json:"zones,omitempty"`
 }
 
 func (x *Status) Reset() {
@@ -322,7 +346,7 @@ func (x *Status) GetRunning() bool {
 	return false
 }
 
-func (x *Status) GetSince() *timestamp.Timestamp {
+func (x *Status) GetSince() *timestamppb.Timestamp {
 	if x != nil {
 		return x.Since
 	}
@@ -343,6 +367,502 @@ func (x *Status) GetZones() []*ZoneStatus {
 	return nil
 }
 
+type Device struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Zone            string                 `protobuf:"bytes,1,opt,name=zone,proto3" json:"zone,omitempty"`
+	Class           string                 `protobuf:"bytes,2,opt,name=class,proto3" json:"class,omitempty"`
+	Id              uint32                 `protobuf:"varint,3,opt,name=id,proto3" json:"id,omitempty"`
+	FirmwareVersion string                 `protobuf:"bytes,4,opt,name=firmware_version,json=firmwareVersion,proto3" json:"firmware_version,omitempty"`
+	FirstSeen       *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=first_seen,json=firstSeen,proto3" json:"first_seen,omitempty"`
+	LastSeen        *timestamppb.Timestamp `protobuf:"bytes,6,opt,name=last_seen,json=lastSeen,proto3" json:"last_seen,omitempty"`
+	Resets          uint32                 `protobuf:"varint,7,opt,name=resets,proto3" json:"resets,omitempty"`
+	Outdated        bool                   `protobuf:"varint,8,opt,name=outdated,proto3" json:"outdated,omitempty"`
+}
+
+func (x *Device) Reset() {
+	*x = Device{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_zeus_service_proto_msgTypes[5]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Device) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Device) ProtoMessage() {}
+
+func (x *Device) ProtoReflect() protoreflect.Message {
+	mi := &file_zeus_service_proto_msgTypes[5]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Device.ProtoReflect.Descriptor instead.
+func (*Device) Descriptor() ([]byte, []int) {
+	return file_zeus_service_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *Device) GetZone() string {
+	if x != nil {
+		return x.Zone
+	}
+	return ""
+}
+
+func (x *Device) GetClass() string {
+	if x != nil {
+		return x.Class
+	}
+	return ""
+}
+
+func (x *Device) GetId() uint32 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *Device) GetFirmwareVersion() string {
+	if x != nil {
+		return x.FirmwareVersion
+	}
+	return ""
+}
+
+func (x *Device) GetFirstSeen() *timestamppb.Timestamp {
+	if x != nil {
+		return x.FirstSeen
+	}
+	return nil
+}
+
+func (x *Device) GetLastSeen() *timestamppb.Timestamp {
+	if x != nil {
+		return x.LastSeen
+	}
+	return nil
+}
+
+func (x *Device) GetResets() uint32 {
+	if x != nil {
+		return x.Resets
+	}
+	return 0
+}
+
+func (x *Device) GetOutdated() bool {
+	if x != nil {
+		return x.Outdated
+	}
+	return false
+}
+
+type DeviceList struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Devices []*Device `protobuf:"bytes,1,rep,name=devices,proto3" json:"devices,omitempty"`
+}
+
+func (x *DeviceList) Reset() {
+	*x = DeviceList{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_zeus_service_proto_msgTypes[6]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *DeviceList) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeviceList) ProtoMessage() {}
+
+func (x *DeviceList) ProtoReflect() protoreflect.Message {
+	mi := &file_zeus_service_proto_msgTypes[6]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeviceList.ProtoReflect.Descriptor instead.
+func (*DeviceList) Descriptor() ([]byte, []int) {
+	return file_zeus_service_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *DeviceList) GetDevices() []*Device {
+	if x != nil {
+		return x.Devices
+	}
+	return nil
+}
+
+type ConfigReload struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Applied []string `protobuf:"bytes,1,rep,name=applied,proto3" json:"applied,omitempty"`
+	Refused []string `protobuf:"bytes,2,rep,name=refused,proto3" json:"refused,omitempty"`
+}
+
+func (x *ConfigReload) Reset() {
+	*x = ConfigReload{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_zeus_service_proto_msgTypes[7]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ConfigReload) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ConfigReload) ProtoMessage() {}
+
+func (x *ConfigReload) ProtoReflect() protoreflect.Message {
+	mi := &file_zeus_service_proto_msgTypes[7]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ConfigReload.ProtoReflect.Descriptor instead.
+func (*ConfigReload) Descriptor() ([]byte, []int) {
+	return file_zeus_service_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *ConfigReload) GetApplied() []string {
+	if x != nil {
+		return x.Applied
+	}
+	return nil
+}
+
+func (x *ConfigReload) GetRefused() []string {
+	if x != nil {
+		return x.Refused
+	}
+	return nil
+}
+
+type LogRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Zone     string `protobuf:"bytes,1,opt,name=zone,proto3" json:"zone,omitempty"`
+	PageSize uint32 `protobuf:"varint,2,opt,name=page_size,json=pageSize,proto3" json:"page_size,omitempty"`
+}
+
+func (x *LogRequest) Reset() {
+	*x = LogRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_zeus_service_proto_msgTypes[8]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *LogRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LogRequest) ProtoMessage() {}
+
+func (x *LogRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_zeus_service_proto_msgTypes[8]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LogRequest.ProtoReflect.Descriptor instead.
+func (*LogRequest) Descriptor() ([]byte, []int) {
+	return file_zeus_service_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *LogRequest) GetZone() string {
+	if x != nil {
+		return x.Zone
+	}
+	return ""
+}
+
+func (x *LogRequest) GetPageSize() uint32 {
+	if x != nil {
+		return x.PageSize
+	}
+	return 0
+}
+
+type ClimateLogEntry struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Time         *timestamppb.Timestamp `protobuf:"bytes,1,opt,name=time,proto3" json:"time,omitempty"`
+	Humidity     float32                `protobuf:"fixed32,2,opt,name=humidity,proto3" json:"humidity,omitempty"`
+	Temperatures []float32              `protobuf:"fixed32,3,rep,packed,name=temperatures,proto3" json:"temperatures,omitempty"`
+}
+
+func (x *ClimateLogEntry) Reset() {
+	*x = ClimateLogEntry{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_zeus_service_proto_msgTypes[9]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ClimateLogEntry) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ClimateLogEntry) ProtoMessage() {}
+
+func (x *ClimateLogEntry) ProtoReflect() protoreflect.Message {
+	mi := &file_zeus_service_proto_msgTypes[9]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ClimateLogEntry.ProtoReflect.Descriptor instead.
+func (*ClimateLogEntry) Descriptor() ([]byte, []int) {
+	return file_zeus_service_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *ClimateLogEntry) GetTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Time
+	}
+	return nil
+}
+
+func (x *ClimateLogEntry) GetHumidity() float32 {
+	if x != nil {
+		return x.Humidity
+	}
+	return 0
+}
+
+func (x *ClimateLogEntry) GetTemperatures() []float32 {
+	if x != nil {
+		return x.Temperatures
+	}
+	return nil
+}
+
+type ClimateLogPage struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Entries []*ClimateLogEntry `protobuf:"bytes,1,rep,name=entries,proto3" json:"entries,omitempty"`
+}
+
+func (x *ClimateLogPage) Reset() {
+	*x = ClimateLogPage{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_zeus_service_proto_msgTypes[10]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ClimateLogPage) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ClimateLogPage) ProtoMessage() {}
+
+func (x *ClimateLogPage) ProtoReflect() protoreflect.Message {
+	mi := &file_zeus_service_proto_msgTypes[10]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ClimateLogPage.ProtoReflect.Descriptor instead.
+func (*ClimateLogPage) Descriptor() ([]byte, []int) {
+	return file_zeus_service_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *ClimateLogPage) GetEntries() []*ClimateLogEntry {
+	if x != nil {
+		return x.Entries
+	}
+	return nil
+}
+
+type AlarmLogEvent struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Time        *timestamppb.Timestamp `protobuf:"bytes,1,opt,name=time,proto3" json:"time,omitempty"`
+	Identifier  string                 `protobuf:"bytes,2,opt,name=identifier,proto3" json:"identifier,omitempty"`
+	Description string                 `protobuf:"bytes,3,opt,name=description,proto3" json:"description,omitempty"`
+	Flags       int32                  `protobuf:"varint,4,opt,name=flags,proto3" json:"flags,omitempty"`
+	On          bool                   `protobuf:"varint,5,opt,name=on,proto3" json:"on,omitempty"`
+}
+
+func (x *AlarmLogEvent) Reset() {
+	*x = AlarmLogEvent{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_zeus_service_proto_msgTypes[11]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *AlarmLogEvent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AlarmLogEvent) ProtoMessage() {}
+
+func (x *AlarmLogEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_zeus_service_proto_msgTypes[11]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AlarmLogEvent.ProtoReflect.Descriptor instead.
+func (*AlarmLogEvent) Descriptor() ([]byte, []int) {
+	return file_zeus_service_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *AlarmLogEvent) GetTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Time
+	}
+	return nil
+}
+
+func (x *AlarmLogEvent) GetIdentifier() string {
+	if x != nil {
+		return x.Identifier
+	}
+	return ""
+}
+
+func (x *AlarmLogEvent) GetDescription() string {
+	if x != nil {
+		return x.Description
+	}
+	return ""
+}
+
+func (x *AlarmLogEvent) GetFlags() int32 {
+	if x != nil {
+		return x.Flags
+	}
+	return 0
+}
+
+func (x *AlarmLogEvent) GetOn() bool {
+	if x != nil {
+		return x.On
+	}
+	return false
+}
+
+type AlarmLogPage struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Events []*AlarmLogEvent `protobuf:"bytes,1,rep,name=events,proto3" json:"events,omitempty"`
+}
+
+func (x *AlarmLogPage) Reset() {
+	*x = AlarmLogPage{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_zeus_service_proto_msgTypes[12]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *AlarmLogPage) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AlarmLogPage) ProtoMessage() {}
+
+func (x *AlarmLogPage) ProtoReflect() protoreflect.Message {
+	mi := &file_zeus_service_proto_msgTypes[12]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AlarmLogPage.ProtoReflect.Descriptor instead.
+func (*AlarmLogPage) Descriptor() ([]byte, []int) {
+	return file_zeus_service_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *AlarmLogPage) GetEvents() []*AlarmLogEvent {
+	if x != nil {
+		return x.Events
+	}
+	return nil
+}
+
 var File_zeus_service_proto protoreflect.FileDescriptor
 
 var file_zeus_service_proto_rawDesc = []byte{
@@ -372,7 +892,7 @@ var file_zeus_service_proto_rawDesc = []byte{
 	0x0a, 0x0b, 0x73, 0x65, 0x61, 0x73, 0x6f, 0x6e, 0x5f, 0x66, 0x69, 0x6c, 0x65, 0x18, 0x01, 0x20,
 	0x01, 0x28, 0x09, 0x52, 0x0a, 0x73, 0x65, 0x61, 0x73, 0x6f, 0x6e, 0x46, 0x69, 0x6c, 0x65, 0x12,
 	0x18, 0x0a, 0x07, 0x76, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09,
-	0x52, 0x07, 0x76, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x22, 0xb6, 0x01, 0x0a, 0x0a, 0x5a, 0x6f,
+	0x52, 0x07, 0x76, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x22, 0x84, 0x03, 0x0a, 0x0a, 0x5a, 0x6f,
 	0x6e, 0x65, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x12, 0x12, 0x0a, 0x04, 0x6e, 0x61, 0x6d, 0x65,
 	0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x12, 0x25, 0x0a, 0x0b,
 	0x74, 0x65, 0x6d, 0x70, 0x65, 0x72, 0x61, 0x74, 0x75, 0x72, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28,
@@ -382,33 +902,122 @@ var file_zeus_service_proto_rawDesc = []byte{
 	0x79, 0x88, 0x01, 0x01, 0x12, 0x2f, 0x0a, 0x06, 0x74, 0x61, 0x72, 0x67, 0x65, 0x74, 0x18, 0x04,
 	0x20, 0x01, 0x28, 0x0b, 0x32, 0x17, 0x2e, 0x66, 0x6f, 0x72, 0x74, 0x2e, 0x7a, 0x65, 0x75, 0x73,
 	0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x54, 0x61, 0x72, 0x67, 0x65, 0x74, 0x52, 0x06, 0x74,
-	0x61, 0x72, 0x67, 0x65, 0x74, 0x42, 0x0e, 0x0a, 0x0c, 0x5f, 0x74, 0x65, 0x6d, 0x70, 0x65, 0x72,
-	0x61, 0x74, 0x75, 0x72, 0x65, 0x42, 0x0b, 0x0a, 0x09, 0x5f, 0x68, 0x75, 0x6d, 0x69, 0x64, 0x69,
-	0x74, 0x79, 0x22, 0xa1, 0x01, 0x0a, 0x06, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x12, 0x18, 0x0a,
-	0x07, 0x72, 0x75, 0x6e, 0x6e, 0x69, 0x6e, 0x67, 0x18, 0x01, 0x20, 0x01, 0x28, 0x08, 0x52, 0x07,
-	0x72, 0x75, 0x6e, 0x6e, 0x69, 0x6e, 0x67, 0x12, 0x30, 0x0a, 0x05, 0x73, 0x69, 0x6e, 0x63, 0x65,
-	0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e,
-	0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61,
-	0x6d, 0x70, 0x52, 0x05, 0x73, 0x69, 0x6e, 0x63, 0x65, 0x12, 0x18, 0x0a, 0x07, 0x76, 0x65, 0x72,
-	0x73, 0x69, 0x6f, 0x6e, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x76, 0x65, 0x72, 0x73,
-	0x69, 0x6f, 0x6e, 0x12, 0x31, 0x0a, 0x05, 0x7a, 0x6f, 0x6e, 0x65, 0x73, 0x18, 0x04, 0x20, 0x03,
-	0x28, 0x0b, 0x32, 0x1b, 0x2e, 0x66, 0x6f, 0x72, 0x74, 0x2e, 0x7a, 0x65, 0x75, 0x73, 0x2e, 0x70,
-	0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x5a, 0x6f, 0x6e, 0x65, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x52,
-	0x05, 0x7a, 0x6f, 0x6e, 0x65, 0x73, 0x32, 0xca, 0x01, 0x0a, 0x04, 0x5a, 0x65, 0x75, 0x73, 0x12,
-	0x45, 0x0a, 0x0c, 0x53, 0x74, 0x61, 0x72, 0x74, 0x43, 0x6c, 0x69, 0x6d, 0x61, 0x74, 0x65, 0x12,
-	0x1d, 0x2e, 0x66, 0x6f, 0x72, 0x74, 0x2e, 0x7a, 0x65, 0x75, 0x73, 0x2e, 0x70, 0x72, 0x6f, 0x74,
-	0x6f, 0x2e, 0x53, 0x74, 0x61, 0x72, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x16,
-	0x2e, 0x66, 0x6f, 0x72, 0x74, 0x2e, 0x7a, 0x65, 0x75, 0x73, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f,
-	0x2e, 0x45, 0x6d, 0x70, 0x74, 0x79, 0x12, 0x3c, 0x0a, 0x09, 0x47, 0x65, 0x74, 0x53, 0x74, 0x61,
-	0x74, 0x75, 0x73, 0x12, 0x16, 0x2e, 0x66, 0x6f, 0x72, 0x74, 0x2e, 0x7a, 0x65, 0x75, 0x73, 0x2e,
-	0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x45, 0x6d, 0x70, 0x74, 0x79, 0x1a, 0x17, 0x2e, 0x66, 0x6f,
-	0x72, 0x74, 0x2e, 0x7a, 0x65, 0x75, 0x73, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x53, 0x74,
-	0x61, 0x74, 0x75, 0x73, 0x12, 0x3d, 0x0a, 0x0b, 0x53, 0x74, 0x6f, 0x70, 0x43, 0x6c, 0x69, 0x6d,
-	0x61, 0x74, 0x65, 0x12, 0x16, 0x2e, 0x66, 0x6f, 0x72, 0x74, 0x2e, 0x7a, 0x65, 0x75, 0x73, 0x2e,
-	0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x45, 0x6d, 0x70, 0x74, 0x79, 0x1a, 0x16, 0x2e, 0x66, 0x6f,
-	0x72, 0x74, 0x2e, 0x7a, 0x65, 0x75, 0x73, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x45, 0x6d,
-	0x70, 0x74, 0x79, 0x42, 0x0a, 0x5a, 0x08, 0x2e, 0x3b, 0x7a, 0x65, 0x75, 0x73, 0x70, 0x62, 0x62,
-	0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+	0x61, 0x72, 0x67, 0x65, 0x74, 0x12, 0x20, 0x0a, 0x09, 0x64, 0x65, 0x77, 0x5f, 0x70, 0x6f, 0x69,
+	0x6e, 0x74, 0x18, 0x05, 0x20, 0x01, 0x28, 0x02, 0x48, 0x02, 0x52, 0x08, 0x64, 0x65, 0x77, 0x50,
+	0x6f, 0x69, 0x6e, 0x74, 0x88, 0x01, 0x01, 0x12, 0x30, 0x0a, 0x11, 0x61, 0x62, 0x73, 0x6f, 0x6c,
+	0x75, 0x74, 0x65, 0x5f, 0x68, 0x75, 0x6d, 0x69, 0x64, 0x69, 0x74, 0x79, 0x18, 0x06, 0x20, 0x01,
+	0x28, 0x02, 0x48, 0x03, 0x52, 0x10, 0x61, 0x62, 0x73, 0x6f, 0x6c, 0x75, 0x74, 0x65, 0x48, 0x75,
+	0x6d, 0x69, 0x64, 0x69, 0x74, 0x79, 0x88, 0x01, 0x01, 0x12, 0x39, 0x0a, 0x16, 0x76, 0x61, 0x70,
+	0x6f, 0x72, 0x5f, 0x70, 0x72, 0x65, 0x73, 0x73, 0x75, 0x72, 0x65, 0x5f, 0x64, 0x65, 0x66, 0x69,
+	0x63, 0x69, 0x74, 0x18, 0x07, 0x20, 0x01, 0x28, 0x02, 0x48, 0x04, 0x52, 0x14, 0x76, 0x61, 0x70,
+	0x6f, 0x72, 0x50, 0x72, 0x65, 0x73, 0x73, 0x75, 0x72, 0x65, 0x44, 0x65, 0x66, 0x69, 0x63, 0x69,
+	0x74, 0x88, 0x01, 0x01, 0x42, 0x0e, 0x0a, 0x0c, 0x5f, 0x74, 0x65, 0x6d, 0x70, 0x65, 0x72, 0x61,
+	0x74, 0x75, 0x72, 0x65, 0x42, 0x0b, 0x0a, 0x09, 0x5f, 0x68, 0x75, 0x6d, 0x69, 0x64, 0x69, 0x74,
+	0x79, 0x42, 0x0c, 0x0a, 0x0a, 0x5f, 0x64, 0x65, 0x77, 0x5f, 0x70, 0x6f, 0x69, 0x6e, 0x74, 0x42,
+	0x14, 0x0a, 0x12, 0x5f, 0x61, 0x62, 0x73, 0x6f, 0x6c, 0x75, 0x74, 0x65, 0x5f, 0x68, 0x75, 0x6d,
+	0x69, 0x64, 0x69, 0x74, 0x79, 0x42, 0x19, 0x0a, 0x17, 0x5f, 0x76, 0x61, 0x70, 0x6f, 0x72, 0x5f,
+	0x70, 0x72, 0x65, 0x73, 0x73, 0x75, 0x72, 0x65, 0x5f, 0x64, 0x65, 0x66, 0x69, 0x63, 0x69, 0x74,
+	0x22, 0xa1, 0x01, 0x0a, 0x06, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x12, 0x18, 0x0a, 0x07, 0x72,
+	0x75, 0x6e, 0x6e, 0x69, 0x6e, 0x67, 0x18, 0x01, 0x20, 0x01, 0x28, 0x08, 0x52, 0x07, 0x72, 0x75,
+	0x6e, 0x6e, 0x69, 0x6e, 0x67, 0x12, 0x30, 0x0a, 0x05, 0x73, 0x69, 0x6e, 0x63, 0x65, 0x18, 0x02,
+	0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72,
+	0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70,
+	0x52, 0x05, 0x73, 0x69, 0x6e, 0x63, 0x65, 0x12, 0x18, 0x0a, 0x07, 0x76, 0x65, 0x72, 0x73, 0x69,
+	0x6f, 0x6e, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x76, 0x65, 0x72, 0x73, 0x69, 0x6f,
+	0x6e, 0x12, 0x31, 0x0a, 0x05, 0x7a, 0x6f, 0x6e, 0x65, 0x73, 0x18, 0x04, 0x20, 0x03, 0x28, 0x0b,
+	0x32, 0x1b, 0x2e, 0x66, 0x6f, 0x72, 0x74, 0x2e, 0x7a, 0x65, 0x75, 0x73, 0x2e, 0x70, 0x72, 0x6f,
+	0x74, 0x6f, 0x2e, 0x5a, 0x6f, 0x6e, 0x65, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x52, 0x05, 0x7a,
+	0x6f, 0x6e, 0x65, 0x73, 0x22, 0x95, 0x02, 0x0a, 0x06, 0x44, 0x65, 0x76, 0x69, 0x63, 0x65, 0x12,
+	0x12, 0x0a, 0x04, 0x7a, 0x6f, 0x6e, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x7a,
+	0x6f, 0x6e, 0x65, 0x12, 0x14, 0x0a, 0x05, 0x63, 0x6c, 0x61, 0x73, 0x73, 0x18, 0x02, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x05, 0x63, 0x6c, 0x61, 0x73, 0x73, 0x12, 0x0e, 0x0a, 0x02, 0x69, 0x64, 0x18,
+	0x03, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x02, 0x69, 0x64, 0x12, 0x29, 0x0a, 0x10, 0x66, 0x69, 0x72,
+	0x6d, 0x77, 0x61, 0x72, 0x65, 0x5f, 0x76, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x18, 0x04, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x0f, 0x66, 0x69, 0x72, 0x6d, 0x77, 0x61, 0x72, 0x65, 0x56, 0x65, 0x72,
+	0x73, 0x69, 0x6f, 0x6e, 0x12, 0x39, 0x0a, 0x0a, 0x66, 0x69, 0x72, 0x73, 0x74, 0x5f, 0x73, 0x65,
+	0x65, 0x6e, 0x18, 0x05, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c,
+	0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73,
+	0x74, 0x61, 0x6d, 0x70, 0x52, 0x09, 0x66, 0x69, 0x72, 0x73, 0x74, 0x53, 0x65, 0x65, 0x6e, 0x12,
+	0x37, 0x0a, 0x09, 0x6c, 0x61, 0x73, 0x74, 0x5f, 0x73, 0x65, 0x65, 0x6e, 0x18, 0x06, 0x20, 0x01,
+	0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74,
+	0x6f, 0x62, 0x75, 0x66, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x52, 0x08,
+	0x6c, 0x61, 0x73, 0x74, 0x53, 0x65, 0x65, 0x6e, 0x12, 0x16, 0x0a, 0x06, 0x72, 0x65, 0x73, 0x65,
+	0x74, 0x73, 0x18, 0x07, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x06, 0x72, 0x65, 0x73, 0x65, 0x74, 0x73,
+	0x12, 0x1a, 0x0a, 0x08, 0x6f, 0x75, 0x74, 0x64, 0x61, 0x74, 0x65, 0x64, 0x18, 0x08, 0x20, 0x01,
+	0x28, 0x08, 0x52, 0x08, 0x6f, 0x75, 0x74, 0x64, 0x61, 0x74, 0x65, 0x64, 0x22, 0x3f, 0x0a, 0x0a,
+	0x44, 0x65, 0x76, 0x69, 0x63, 0x65, 0x4c, 0x69, 0x73, 0x74, 0x12, 0x31, 0x0a, 0x07, 0x64, 0x65,
+	0x76, 0x69, 0x63, 0x65, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x17, 0x2e, 0x66, 0x6f,
+	0x72, 0x74, 0x2e, 0x7a, 0x65, 0x75, 0x73, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x44, 0x65,
+	0x76, 0x69, 0x63, 0x65, 0x52, 0x07, 0x64, 0x65, 0x76, 0x69, 0x63, 0x65, 0x73, 0x22, 0x42, 0x0a,
+	0x0c, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x52, 0x65, 0x6c, 0x6f, 0x61, 0x64, 0x12, 0x18, 0x0a,
+	0x07, 0x61, 0x70, 0x70, 0x6c, 0x69, 0x65, 0x64, 0x18, 0x01, 0x20, 0x03, 0x28, 0x09, 0x52, 0x07,
+	0x61, 0x70, 0x70, 0x6c, 0x69, 0x65, 0x64, 0x12, 0x18, 0x0a, 0x07, 0x72, 0x65, 0x66, 0x75, 0x73,
+	0x65, 0x64, 0x18, 0x02, 0x20, 0x03, 0x28, 0x09, 0x52, 0x07, 0x72, 0x65, 0x66, 0x75, 0x73, 0x65,
+	0x64, 0x22, 0x3d, 0x0a, 0x0a, 0x4c, 0x6f, 0x67, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12,
+	0x12, 0x0a, 0x04, 0x7a, 0x6f, 0x6e, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x7a,
+	0x6f, 0x6e, 0x65, 0x12, 0x1b, 0x0a, 0x09, 0x70, 0x61, 0x67, 0x65, 0x5f, 0x73, 0x69, 0x7a, 0x65,
+	0x18, 0x02, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x08, 0x70, 0x61, 0x67, 0x65, 0x53, 0x69, 0x7a, 0x65,
+	0x22, 0x81, 0x01, 0x0a, 0x0f, 0x43, 0x6c, 0x69, 0x6d, 0x61, 0x74, 0x65, 0x4c, 0x6f, 0x67, 0x45,
+	0x6e, 0x74, 0x72, 0x79, 0x12, 0x2e, 0x0a, 0x04, 0x74, 0x69, 0x6d, 0x65, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74,
+	0x6f, 0x62, 0x75, 0x66, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x52, 0x04,
+	0x74, 0x69, 0x6d, 0x65, 0x12, 0x1a, 0x0a, 0x08, 0x68, 0x75, 0x6d, 0x69, 0x64, 0x69, 0x74, 0x79,
+	0x18, 0x02, 0x20, 0x01, 0x28, 0x02, 0x52, 0x08, 0x68, 0x75, 0x6d, 0x69, 0x64, 0x69, 0x74, 0x79,
+	0x12, 0x22, 0x0a, 0x0c, 0x74, 0x65, 0x6d, 0x70, 0x65, 0x72, 0x61, 0x74, 0x75, 0x72, 0x65, 0x73,
+	0x18, 0x03, 0x20, 0x03, 0x28, 0x02, 0x52, 0x0c, 0x74, 0x65, 0x6d, 0x70, 0x65, 0x72, 0x61, 0x74,
+	0x75, 0x72, 0x65, 0x73, 0x22, 0x4c, 0x0a, 0x0e, 0x43, 0x6c, 0x69, 0x6d, 0x61, 0x74, 0x65, 0x4c,
+	0x6f, 0x67, 0x50, 0x61, 0x67, 0x65, 0x12, 0x3a, 0x0a, 0x07, 0x65, 0x6e, 0x74, 0x72, 0x69, 0x65,
+	0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x20, 0x2e, 0x66, 0x6f, 0x72, 0x74, 0x2e, 0x7a,
+	0x65, 0x75, 0x73, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x43, 0x6c, 0x69, 0x6d, 0x61, 0x74,
+	0x65, 0x4c, 0x6f, 0x67, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x52, 0x07, 0x65, 0x6e, 0x74, 0x72, 0x69,
+	0x65, 0x73, 0x22, 0xa7, 0x01, 0x0a, 0x0d, 0x41, 0x6c, 0x61, 0x72, 0x6d, 0x4c, 0x6f, 0x67, 0x45,
+	0x76, 0x65, 0x6e, 0x74, 0x12, 0x2e, 0x0a, 0x04, 0x74, 0x69, 0x6d, 0x65, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74,
+	0x6f, 0x62, 0x75, 0x66, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x52, 0x04,
+	0x74, 0x69, 0x6d, 0x65, 0x12, 0x1e, 0x0a, 0x0a, 0x69, 0x64, 0x65, 0x6e, 0x74, 0x69, 0x66, 0x69,
+	0x65, 0x72, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0a, 0x69, 0x64, 0x65, 0x6e, 0x74, 0x69,
+	0x66, 0x69, 0x65, 0x72, 0x12, 0x20, 0x0a, 0x0b, 0x64, 0x65, 0x73, 0x63, 0x72, 0x69, 0x70, 0x74,
+	0x69, 0x6f, 0x6e, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0b, 0x64, 0x65, 0x73, 0x63, 0x72,
+	0x69, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x14, 0x0a, 0x05, 0x66, 0x6c, 0x61, 0x67, 0x73, 0x18,
+	0x04, 0x20, 0x01, 0x28, 0x05, 0x52, 0x05, 0x66, 0x6c, 0x61, 0x67, 0x73, 0x12, 0x0e, 0x0a, 0x02,
+	0x6f, 0x6e, 0x18, 0x05, 0x20, 0x01, 0x28, 0x08, 0x52, 0x02, 0x6f, 0x6e, 0x22, 0x46, 0x0a, 0x0c,
+	0x41, 0x6c, 0x61, 0x72, 0x6d, 0x4c, 0x6f, 0x67, 0x50, 0x61, 0x67, 0x65, 0x12, 0x36, 0x0a, 0x06,
+	0x65, 0x76, 0x65, 0x6e, 0x74, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x1e, 0x2e, 0x66,
+	0x6f, 0x72, 0x74, 0x2e, 0x7a, 0x65, 0x75, 0x73, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x41,
+	0x6c, 0x61, 0x72, 0x6d, 0x4c, 0x6f, 0x67, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x52, 0x06, 0x65, 0x76,
+	0x65, 0x6e, 0x74, 0x73, 0x32, 0xf3, 0x03, 0x0a, 0x04, 0x5a, 0x65, 0x75, 0x73, 0x12, 0x45, 0x0a,
+	0x0c, 0x53, 0x74, 0x61, 0x72, 0x74, 0x43, 0x6c, 0x69, 0x6d, 0x61, 0x74, 0x65, 0x12, 0x1d, 0x2e,
+	0x66, 0x6f, 0x72, 0x74, 0x2e, 0x7a, 0x65, 0x75, 0x73, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e,
+	0x53, 0x74, 0x61, 0x72, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x16, 0x2e, 0x66,
+	0x6f, 0x72, 0x74, 0x2e, 0x7a, 0x65, 0x75, 0x73, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x45,
+	0x6d, 0x70, 0x74, 0x79, 0x12, 0x3c, 0x0a, 0x09, 0x47, 0x65, 0x74, 0x53, 0x74, 0x61, 0x74, 0x75,
+	0x73, 0x12, 0x16, 0x2e, 0x66, 0x6f, 0x72, 0x74, 0x2e, 0x7a, 0x65, 0x75, 0x73, 0x2e, 0x70, 0x72,
+	0x6f, 0x74, 0x6f, 0x2e, 0x45, 0x6d, 0x70, 0x74, 0x79, 0x1a, 0x17, 0x2e, 0x66, 0x6f, 0x72, 0x74,
+	0x2e, 0x7a, 0x65, 0x75, 0x73, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x53, 0x74, 0x61, 0x74,
+	0x75, 0x73, 0x12, 0x3d, 0x0a, 0x0b, 0x53, 0x74, 0x6f, 0x70, 0x43, 0x6c, 0x69, 0x6d, 0x61, 0x74,
+	0x65, 0x12, 0x16, 0x2e, 0x66, 0x6f, 0x72, 0x74, 0x2e, 0x7a, 0x65, 0x75, 0x73, 0x2e, 0x70, 0x72,
+	0x6f, 0x74, 0x6f, 0x2e, 0x45, 0x6d, 0x70, 0x74, 0x79, 0x1a, 0x16, 0x2e, 0x66, 0x6f, 0x72, 0x74,
+	0x2e, 0x7a, 0x65, 0x75, 0x73, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x45, 0x6d, 0x70, 0x74,
+	0x79, 0x12, 0x42, 0x0a, 0x0b, 0x4c, 0x69, 0x73, 0x74, 0x44, 0x65, 0x76, 0x69, 0x63, 0x65, 0x73,
+	0x12, 0x16, 0x2e, 0x66, 0x6f, 0x72, 0x74, 0x2e, 0x7a, 0x65, 0x75, 0x73, 0x2e, 0x70, 0x72, 0x6f,
+	0x74, 0x6f, 0x2e, 0x45, 0x6d, 0x70, 0x74, 0x79, 0x1a, 0x1b, 0x2e, 0x66, 0x6f, 0x72, 0x74, 0x2e,
+	0x7a, 0x65, 0x75, 0x73, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x44, 0x65, 0x76, 0x69, 0x63,
+	0x65, 0x4c, 0x69, 0x73, 0x74, 0x12, 0x45, 0x0a, 0x0c, 0x52, 0x65, 0x6c, 0x6f, 0x61, 0x64, 0x43,
+	0x6f, 0x6e, 0x66, 0x69, 0x67, 0x12, 0x16, 0x2e, 0x66, 0x6f, 0x72, 0x74, 0x2e, 0x7a, 0x65, 0x75,
+	0x73, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x45, 0x6d, 0x70, 0x74, 0x79, 0x1a, 0x1d, 0x2e,
+	0x66, 0x6f, 0x72, 0x74, 0x2e, 0x7a, 0x65, 0x75, 0x73, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e,
+	0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x52, 0x65, 0x6c, 0x6f, 0x61, 0x64, 0x12, 0x4f, 0x0a, 0x0d,
+	0x47, 0x65, 0x74, 0x43, 0x6c, 0x69, 0x6d, 0x61, 0x74, 0x65, 0x4c, 0x6f, 0x67, 0x12, 0x1b, 0x2e,
+	0x66, 0x6f, 0x72, 0x74, 0x2e, 0x7a, 0x65, 0x75, 0x73, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e,
+	0x4c, 0x6f, 0x67, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1f, 0x2e, 0x66, 0x6f, 0x72,
+	0x74, 0x2e, 0x7a, 0x65, 0x75, 0x73, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x43, 0x6c, 0x69,
+	0x6d, 0x61, 0x74, 0x65, 0x4c, 0x6f, 0x67, 0x50, 0x61, 0x67, 0x65, 0x30, 0x01, 0x12, 0x4b, 0x0a,
+	0x0b, 0x47, 0x65, 0x74, 0x41, 0x6c, 0x61, 0x72, 0x6d, 0x4c, 0x6f, 0x67, 0x12, 0x1b, 0x2e, 0x66,
+	0x6f, 0x72, 0x74, 0x2e, 0x7a, 0x65, 0x75, 0x73, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x4c,
+	0x6f, 0x67, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1d, 0x2e, 0x66, 0x6f, 0x72, 0x74,
+	0x2e, 0x7a, 0x65, 0x75, 0x73, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x41, 0x6c, 0x61, 0x72,
+	0x6d, 0x4c, 0x6f, 0x67, 0x50, 0x61, 0x67, 0x65, 0x30, 0x01, 0x42, 0x0a, 0x5a, 0x08, 0x2e, 0x3b,
+	0x7a, 0x65, 0x75, 0x73, 0x70, 0x62, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
 }
 
 var (
@@ -423,30 +1032,53 @@ func file_zeus_service_proto_rawDescGZIP() []byte {
 	return file_zeus_service_proto_rawDescData
 }
 
-var file_zeus_service_proto_msgTypes = make([]protoimpl.MessageInfo, 5)
+var file_zeus_service_proto_msgTypes = make([]protoimpl.MessageInfo, 13)
 var file_zeus_service_proto_goTypes = []interface{}{
-	(*Empty)(nil),               // 0: fort.zeus.proto.Empty
-	(*Target)(nil),              // 1: fort.zeus.proto.Target
-	(*StartRequest)(nil),        // 2: fort.zeus.proto.StartRequest
-	(*ZoneStatus)(nil),          // 3: fort.zeus.proto.ZoneStatus
-	(*Status)(nil),              // 4: fort.zeus.proto.Status
-	(*timestamp.Timestamp)(nil), // 5: google.protobuf.Timestamp
+	(*Empty)(nil),                 // 0: fort.zeus.proto.Empty
+	(*Target)(nil),                // 1: fort.zeus.proto.Target
+	(*StartRequest)(nil),          // 2: fort.zeus.proto.StartRequest
+	(*ZoneStatus)(nil),            // 3: fort.zeus.proto.ZoneStatus
+	(*Status)(nil),                // 4: fort.zeus.proto.Status
+	(*Device)(nil),                // 5: fort.zeus.proto.Device
+	(*DeviceList)(nil),            // 6: fort.zeus.proto.DeviceList
+	(*ConfigReload)(nil),          // 7: fort.zeus.proto.ConfigReload
+	(*LogRequest)(nil),            // 8: fort.zeus.proto.LogRequest
+	(*ClimateLogEntry)(nil),       // 9: fort.zeus.proto.ClimateLogEntry
+	(*ClimateLogPage)(nil),        // 10: fort.zeus.proto.ClimateLogPage
+	(*AlarmLogEvent)(nil),         // 11: fort.zeus.proto.AlarmLogEvent
+	(*AlarmLogPage)(nil),          // 12: fort.zeus.proto.AlarmLogPage
+	(*timestamppb.Timestamp)(nil), // 13: google.protobuf.Timestamp
 }
 var file_zeus_service_proto_depIdxs = []int32{
-	1, // 0: fort.zeus.proto.ZoneStatus.target:type_name -> fort.zeus.proto.Target
-	5, // 1: fort.zeus.proto.Status.since:type_name -> google.protobuf.Timestamp
-	3, // 2: fort.zeus.proto.Status.zones:type_name -> fort.zeus.proto.ZoneStatus
-	2, // 3: fort.zeus.proto.Zeus.StartClimate:input_type -> fort.zeus.proto.StartRequest
-	0, // 4: fort.zeus.proto.Zeus.GetStatus:input_type -> fort.zeus.proto.Empty
-	0, // 5: fort.zeus.proto.Zeus.StopClimate:input_type -> fort.zeus.proto.Empty
-	0, // 6: fort.zeus.proto.Zeus.StartClimate:output_type -> fort.zeus.proto.Empty
-	4, // 7: fort.zeus.proto.Zeus.GetStatus:output_type -> fort.zeus.proto.Status
-	0, // 8: fort.zeus.proto.Zeus.StopClimate:output_type -> fort.zeus.proto.Empty
-	6, // [6:9] is the sub-list for method output_type
-	3, // [3:6] is the sub-list for method input_type
-	3, // [3:3] is the sub-list for extension type_name
-	3, // [3:3] is the sub-list for extension extendee
-	0, // [0:3] is the sub-list for field type_name
+	1,  // 0: fort.zeus.proto.ZoneStatus.target:type_name -> fort.zeus.proto.Target
+	13, // 1: fort.zeus.proto.Status.since:type_name -> google.protobuf.Timestamp
+	3,  // 2: fort.zeus.proto.Status.zones:type_name -> fort.zeus.proto.ZoneStatus
+	13, // 3: fort.zeus.proto.Device.first_seen:type_name -> google.protobuf.Timestamp
+	13, // 4: fort.zeus.proto.Device.last_seen:type_name -> google.protobuf.Timestamp
+	5,  // 5: fort.zeus.proto.DeviceList.devices:type_name -> fort.zeus.proto.Device
+	13, // 6: fort.zeus.proto.ClimateLogEntry.time:type_name -> google.protobuf.Timestamp
+	9,  // 7: fort.zeus.proto.ClimateLogPage.entries:type_name -> fort.zeus.proto.ClimateLogEntry
+	13, // 8: fort.zeus.proto.AlarmLogEvent.time:type_name -> google.protobuf.Timestamp
+	11, // 9: fort.zeus.proto.AlarmLogPage.events:type_name -> fort.zeus.proto.AlarmLogEvent
+	2,  // 10: fort.zeus.proto.Zeus.StartClimate:input_type -> fort.zeus.proto.StartRequest
+	0,  // 11: fort.zeus.proto.Zeus.GetStatus:input_type -> fort.zeus.proto.Empty
+	0,  // 12: fort.zeus.proto.Zeus.StopClimate:input_type -> fort.zeus.proto.Empty
+	0,  // 13: fort.zeus.proto.Zeus.ListDevices:input_type -> fort.zeus.proto.Empty
+	0,  // 14: fort.zeus.proto.Zeus.ReloadConfig:input_type -> fort.zeus.proto.Empty
+	8,  // 15: fort.zeus.proto.Zeus.GetClimateLog:input_type -> fort.zeus.proto.LogRequest
+	8,  // 16: fort.zeus.proto.Zeus.GetAlarmLog:input_type -> fort.zeus.proto.LogRequest
+	0,  // 17: fort.zeus.proto.Zeus.StartClimate:output_type -> fort.zeus.proto.Empty
+	4,  // 18: fort.zeus.proto.Zeus.GetStatus:output_type -> fort.zeus.proto.Status
+	0,  // 19: fort.zeus.proto.Zeus.StopClimate:output_type -> fort.zeus.proto.Empty
+	6,  // 20: fort.zeus.proto.Zeus.ListDevices:output_type -> fort.zeus.proto.DeviceList
+	7,  // 21: fort.zeus.proto.Zeus.ReloadConfig:output_type -> fort.zeus.proto.ConfigReload
+	10, // 22: fort.zeus.proto.Zeus.GetClimateLog:output_type -> fort.zeus.proto.ClimateLogPage
+	12, // 23: fort.zeus.proto.Zeus.GetAlarmLog:output_type -> fort.zeus.proto.AlarmLogPage
+	17, // [17:24] is the sub-list for method output_type
+	10, // [10:17] is the sub-list for method input_type
+	10, // [10:10] is the sub-list for extension type_name
+	10, // [10:10] is the sub-list for extension extendee
+	0,  // [0:10] is the sub-list for field type_name
 }
 
 func init() { file_zeus_service_proto_init() }
@@ -515,6 +1147,102 @@ func file_zeus_service_proto_init() {
 				return nil
 			}
 		}
+		file_zeus_service_proto_msgTypes[5].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Device); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_zeus_service_proto_msgTypes[6].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*DeviceList); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_zeus_service_proto_msgTypes[7].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ConfigReload); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_zeus_service_proto_msgTypes[8].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*LogRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_zeus_service_proto_msgTypes[9].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ClimateLogEntry); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_zeus_service_proto_msgTypes[10].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ClimateLogPage); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_zeus_service_proto_msgTypes[11].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*AlarmLogEvent); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_zeus_service_proto_msgTypes[12].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*AlarmLogPage); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
 	}
 	file_zeus_service_proto_msgTypes[1].OneofWrappers = []interface{}{}
 	file_zeus_service_proto_msgTypes[3].OneofWrappers = []interface{}{}
@@ -524,7 +1252,7 @@ func file_zeus_service_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: file_zeus_service_proto_rawDesc,
 			NumEnums:      0,
-			NumMessages:   5,
+			NumMessages:   13,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
@@ -1,7 +1,7 @@
 // Code generated by protoc-gen-go-grpc. DO NOT EDIT.
 // versions:
 // - protoc-gen-go-grpc v1.2.0
-// - protoc             v3.12.4
+// - protoc             (unknown)
 // source: zeus_service.proto
 
 package zeuspb
@@ -25,6 +25,10 @@ type ZeusClient interface {
 	StartClimate(ctx context.Context, in *StartRequest, opts ...grpc.CallOption) (*Empty, error)
 	GetStatus(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*Status, error)
 	StopClimate(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*Empty, error)
+	ListDevices(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*DeviceList, error)
+	ReloadConfig(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*ConfigReload, error)
+	GetClimateLog(ctx context.Context, in *LogRequest, opts ...grpc.CallOption) (Zeus_GetClimateLogClient, error)
+	GetAlarmLog(ctx context.Context, in *LogRequest, opts ...grpc.CallOption) (Zeus_GetAlarmLogClient, error)
 }
 
 type zeusClient struct {
@@ -62,6 +66,88 @@ func (c *zeusClient) StopClimate(ctx context.Context, in *Empty, opts ...grpc.Ca
 	return out, nil
 }
 
+func (c *zeusClient) ListDevices(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*DeviceList, error) {
+	out := new(DeviceList)
+	err := c.cc.Invoke(ctx, "/fort.zeus.proto.Zeus/ListDevices", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *zeusClient) ReloadConfig(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*ConfigReload, error) {
+	out := new(ConfigReload)
+	err := c.cc.Invoke(ctx, "/fort.zeus.proto.Zeus/ReloadConfig", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *zeusClient) GetClimateLog(ctx context.Context, in *LogRequest, opts ...grpc.CallOption) (Zeus_GetClimateLogClient, error) {
+	stream, err := c.cc.NewStream(ctx, &Zeus_ServiceDesc.Streams[0], "/fort.zeus.proto.Zeus/GetClimateLog", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &zeusGetClimateLogClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type Zeus_GetClimateLogClient interface {
+	Recv() (*ClimateLogPage, error)
+	grpc.ClientStream
+}
+
+type zeusGetClimateLogClient struct {
+	grpc.ClientStream
+}
+
+func (x *zeusGetClimateLogClient) Recv() (*ClimateLogPage, error) {
+	m := new(ClimateLogPage)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *zeusClient) GetAlarmLog(ctx context.Context, in *LogRequest, opts ...grpc.CallOption) (Zeus_GetAlarmLogClient, error) {
+	stream, err := c.cc.NewStream(ctx, &Zeus_ServiceDesc.Streams[1], "/fort.zeus.proto.Zeus/GetAlarmLog", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &zeusGetAlarmLogClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type Zeus_GetAlarmLogClient interface {
+	Recv() (*AlarmLogPage, error)
+	grpc.ClientStream
+}
+
+type zeusGetAlarmLogClient struct {
+	grpc.ClientStream
+}
+
+func (x *zeusGetAlarmLogClient) Recv() (*AlarmLogPage, error) {
+	m := new(AlarmLogPage)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
 // ZeusServer is the server API for Zeus service.
 // All implementations must embed UnimplementedZeusServer
 // for forward compatibility
@@ -69,6 +155,10 @@ type ZeusServer interface {
 	StartClimate(context.Context, *StartRequest) (*Empty, error)
 	GetStatus(context.Context, *Empty) (*Status, error)
 	StopClimate(context.Context, *Empty) (*Empty, error)
+	ListDevices(context.Context, *Empty) (*DeviceList, error)
+	ReloadConfig(context.Context, *Empty) (*ConfigReload, error)
+	GetClimateLog(*LogRequest, Zeus_GetClimateLogServer) error
+	GetAlarmLog(*LogRequest, Zeus_GetAlarmLogServer) error
 	mustEmbedUnimplementedZeusServer()
 }
 
@@ -85,6 +175,18 @@ func (UnimplementedZeusServer) GetStatus(context.Context, *Empty) (*Status, erro
 func (UnimplementedZeusServer) StopClimate(context.Context, *Empty) (*Empty, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method StopClimate not implemented")
 }
+func (UnimplementedZeusServer) ListDevices(context.Context, *Empty) (*DeviceList, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListDevices not implemented")
+}
+func (UnimplementedZeusServer) ReloadConfig(context.Context, *Empty) (*ConfigReload, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ReloadConfig not implemented")
+}
+func (UnimplementedZeusServer) GetClimateLog(*LogRequest, Zeus_GetClimateLogServer) error {
+	return status.Errorf(codes.Unimplemented, "method GetClimateLog not implemented")
+}
+func (UnimplementedZeusServer) GetAlarmLog(*LogRequest, Zeus_GetAlarmLogServer) error {
+	return status.Errorf(codes.Unimplemented, "method GetAlarmLog not implemented")
+}
 func (UnimplementedZeusServer) mustEmbedUnimplementedZeusServer() {}
 
 // UnsafeZeusServer may be embedded to opt out of forward compatibility for this service.
@@ -152,6 +254,84 @@ func _Zeus_StopClimate_Handler(srv interface{}, ctx context.Context, dec func(in
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Zeus_ListDevices_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ZeusServer).ListDevices(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/fort.zeus.proto.Zeus/ListDevices",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ZeusServer).ListDevices(ctx, req.(*Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Zeus_ReloadConfig_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ZeusServer).ReloadConfig(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/fort.zeus.proto.Zeus/ReloadConfig",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ZeusServer).ReloadConfig(ctx, req.(*Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Zeus_GetClimateLog_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(LogRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(ZeusServer).GetClimateLog(m, &zeusGetClimateLogServer{stream})
+}
+
+type Zeus_GetClimateLogServer interface {
+	Send(*ClimateLogPage) error
+	grpc.ServerStream
+}
+
+type zeusGetClimateLogServer struct {
+	grpc.ServerStream
+}
+
+func (x *zeusGetClimateLogServer) Send(m *ClimateLogPage) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _Zeus_GetAlarmLog_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(LogRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(ZeusServer).GetAlarmLog(m, &zeusGetAlarmLogServer{stream})
+}
+
+type Zeus_GetAlarmLogServer interface {
+	Send(*AlarmLogPage) error
+	grpc.ServerStream
+}
+
+type zeusGetAlarmLogServer struct {
+	grpc.ServerStream
+}
+
+func (x *zeusGetAlarmLogServer) Send(m *AlarmLogPage) error {
+	return x.ServerStream.SendMsg(m)
+}
+
 // Zeus_ServiceDesc is the grpc.ServiceDesc for Zeus service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -171,7 +351,26 @@ var Zeus_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "StopClimate",
 			Handler:    _Zeus_StopClimate_Handler,
 		},
+		{
+			MethodName: "ListDevices",
+			Handler:    _Zeus_ListDevices_Handler,
+		},
+		{
+			MethodName: "ReloadConfig",
+			Handler:    _Zeus_ReloadConfig_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "GetClimateLog",
+			Handler:       _Zeus_GetClimateLog_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "GetAlarmLog",
+			Handler:       _Zeus_GetAlarmLog_Handler,
+			ServerStreams: true,
+		},
 	},
-	Streams:  []grpc.StreamDesc{},
 	Metadata: "zeus_service.proto",
 }